@@ -3,9 +3,11 @@ package bot
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/raykavin/backnrun/core"
 	"github.com/raykavin/backnrun/exchange"
+	"github.com/raykavin/backnrun/metric"
 	"github.com/raykavin/backnrun/order"
 	"github.com/raykavin/backnrun/storage"
 	strg "github.com/raykavin/backnrun/strategy"
@@ -17,7 +19,7 @@ const defaultDatabase = "bot.db"
 type Bot struct {
 	storage  core.Storage
 	exchange core.Exchange
-	strategy core.Strategy
+	bindings []StrategyBinding
 	notifier core.Notifier
 	telegram core.NotifierWithStart
 	log      core.Logger
@@ -29,12 +31,30 @@ type Bot struct {
 	dataFeed            *exchange.DataFeedSubscription
 	paperWallet         *exchange.PaperWallet
 
-	strategiesControllers map[string]*strg.Controller
+	strategiesControllers  map[string][]*strg.Controller
+	informativeControllers map[string][]*strg.Controller
+	feeds                  map[string]strategyFeed
+	feedOrder              []string
+	baseFeeds              map[string]strategyFeed
+	informativeFeeds       []strategyFeed
+	warmups                map[string]int
 
-	backtest bool
+	riskManager       *order.RiskManager
+	candleSubscribers []core.CandleSubscriber
+	orderSubscribers  []core.OrderSubscriber
+
+	monteCarlo []metric.MonteCarloOption
+	parameters core.ParameterSet
+
+	backtest          bool
+	backtestStart     time.Time
+	strategiesStarted bool
 }
 
-// NewBot creates a new Bot bot instance with the provided settings and dependencies
+// NewBot creates a new Bot bot instance with the provided settings and dependencies.
+// The given strategy trades every pair in the settings, additional strategies bound to their own
+// pairs and timeframes can be registered with WithStrategies; in that case strategy may be nil.
+// Pairs used by the bindings are added to settings.Pairs.
 func NewBot(
 	ctx context.Context,
 	settings *core.Settings,
@@ -44,21 +64,24 @@ func NewBot(
 	options ...Option,
 ) (*Bot, error) {
 	// Validate parameters
-	err := validate(settings, exch, strategy, log)
+	err := validate(settings, exch, log)
 	if err != nil {
 		return nil, err
 	}
 
 	// Initialize bot with required core components
 	bot := &Bot{
-		settings:              settings,
-		exchange:              exch,
-		strategy:              strategy,
-		orderFeed:             order.NewOrderFeed(),
-		dataFeed:              exchange.NewDataFeed(exch, log),
-		log:                   log,
-		priorityQueueCandle:   core.NewPriorityQueue(nil),
-		strategiesControllers: make(map[string]*strg.Controller),
+		settings:               settings,
+		exchange:               exch,
+		orderFeed:              order.NewOrderFeed(),
+		dataFeed:               exchange.NewDataFeed(exch, log),
+		log:                    log,
+		priorityQueueCandle:    core.NewPriorityQueue(nil),
+		strategiesControllers:  make(map[string][]*strg.Controller),
+		informativeControllers: make(map[string][]*strg.Controller),
+		feeds:                  make(map[string]strategyFeed),
+		baseFeeds:              make(map[string]strategyFeed),
+		warmups:                make(map[string]int),
 	}
 
 	// Validate trading pairs
@@ -66,11 +89,29 @@ func NewBot(
 		return nil, err
 	}
 
+	// The default strategy trades all pairs in the settings and keeps its orders untagged
+	if strategy != nil {
+		bot.bindings = append(bot.bindings, StrategyBinding{Strategy: strategy, Pairs: settings.Pairs})
+	}
+
 	// Apply custom options
 	for _, option := range options {
 		option(bot)
 	}
 
+	if strategy != nil && bot.parameters != nil {
+		bot.bindings[0].Parameters = bot.parameters
+	}
+
+	// Validate strategy bindings and register their feeds
+	if len(bot.bindings) == 0 {
+		return nil, fmt.Errorf("strategy cannot be nil")
+	}
+
+	if err := bot.resolveBindings(); err != nil {
+		return nil, err
+	}
+
 	// Initialize storage
 	if err := initializeStorage(bot); err != nil {
 		return nil, err
@@ -78,6 +119,20 @@ func NewBot(
 
 	// Initialize order controller
 	bot.orderController = order.NewController(ctx, exch, bot.storage, log, bot.orderFeed)
+	if bot.backtest {
+		bot.orderFeed.SetSynchronous(true)
+		bot.orderController.SetSynchronous(true)
+	}
+
+	// Register risk manager, notifier and subscribers set by options
+	if bot.riskManager != nil {
+		bot.orderController.SetRiskManager(bot.riskManager)
+	}
+	if bot.notifier != nil {
+		bot.registerNotifier(bot.notifier)
+	}
+	bot.SubscribeOrder(bot.orderSubscribers...)
+	bot.SubscribeCandle(bot.candleSubscribers...)
 
 	// Initialize notification systems
 	if err := initializeNotifications(ctx, bot, settings, log); err != nil {
@@ -98,9 +153,9 @@ func validatePairs(pairs []string) error {
 	return nil
 }
 
-// validate checks if the provided settings, exchange, strategy, and logger are valid
-func validate(settings *core.Settings, exch core.Exchange, strategy core.Strategy, log core.Logger) error {
-	if settings == nil || len(settings.Pairs) == 0 {
+// validate checks if the provided settings, exchange, and logger are valid
+func validate(settings *core.Settings, exch core.Exchange, log core.Logger) error {
+	if settings == nil {
 		return fmt.Errorf("settings cannot be nil")
 	}
 
@@ -108,10 +163,6 @@ func validate(settings *core.Settings, exch core.Exchange, strategy core.Strateg
 		return fmt.Errorf("exchange cannot be nil")
 	}
 
-	if strategy == nil {
-		return fmt.Errorf("strategy cannot be nil")
-	}
-
 	if log == nil {
 		return fmt.Errorf("logger cannot be nil")
 	}
@@ -136,23 +187,62 @@ func (n *Bot) Controller() *order.Controller {
 	return n.orderController
 }
 
-// Run will initialize the strategy controller, order controller, preload data and start the bot
+// Run will initialize the strategy controllers, order controller, preload data and start the bot
 func (n *Bot) Run(ctx context.Context) error {
-	for _, pair := range n.settings.Pairs {
-		// setup and subscribe strategy to data feed (candles)
-		n.strategiesControllers[pair] = strg.NewStrategyController(pair, n.strategy, n.orderController, n.log)
+	// setup a strategy controller for each bound pair, all sharing the same order controller
+	for _, binding := range n.bindings {
+		broker := n.strategyBroker(binding)
+		for _, pair := range binding.Pairs {
+			key := feedKey(pair, binding.Timeframe)
+			controller := strg.NewStrategyController(pair, binding.Strategy, broker, n.log)
+			controller.SetTimeframe(binding.Timeframe)
+			n.strategiesControllers[key] = append(n.strategiesControllers[key], controller)
+			n.setWarmup(key, binding.Strategy.WarmupPeriod())
+
+			// register extra pairs and timeframes requested by the strategy
+			for _, informative := range controller.InformativeFeeds() {
+				warmup := informative.WarmupPeriod
+				if warmup <= 0 {
+					warmup = binding.Strategy.WarmupPeriod()
+				}
+				n.addInformativeFeed(informative.Pair, informative.Timeframe, controller, warmup)
+			}
+		}
+	}
+
+	// informative feeds are preloaded first, so strategy candles being preloaded can use them
+	for _, feed := range n.informativeFeeds {
+		err := n.preloadInformative(ctx, feed)
+		if err != nil {
+			return err
+		}
+
+		n.dataFeed.Subscribe(feed.pair, feed.timeframe, n.onCandle(feed), true)
+	}
+
+	for _, key := range n.feedOrder {
+		feed := n.feeds[key]
 
 		// preload candles for warmup period
-		err := n.preload(ctx, pair)
+		err := n.preload(ctx, feed)
 		if err != nil {
 			return err
 		}
 
 		// link to backnrun controller
-		n.dataFeed.Subscribe(pair, n.strategy.Timeframe(), n.onCandle, false)
+		n.dataFeed.Subscribe(feed.pair, feed.timeframe, n.onCandle(feed), false)
+	}
 
-		// start strategy controller
-		n.strategiesControllers[pair].Start()
+	// start strategy controllers, after the warmup candles of a backtest
+	if !n.backtest || n.backtestStart.IsZero() {
+		n.startStrategies()
+	}
+
+	// rebuild positions and results from previous runs, paper wallets start from a clean state
+	if !n.backtest && n.paperWallet == nil {
+		if err := n.orderController.Restore(ctx); err != nil {
+			return err
+		}
 	}
 
 	// start order feed and controller
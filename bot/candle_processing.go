@@ -2,79 +2,165 @@ package bot
 
 import (
 	"context"
-	"time"
 
 	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/exchange"
 )
 
-// onCandle handles incoming candles and adds them to the priority queue
-func (bot *Bot) onCandle(candle core.Candle) {
-	bot.priorityQueueCandle.Push(candle)
+// onCandle returns a consumer that adds candles of the given feed to the priority queue.
+// Closed candles are also handed to strategies using the feed as informative, which
+// only expose them once their own candles reach the same close time.
+func (bot *Bot) onCandle(feed strategyFeed) exchange.DataFeedConsumer {
+	return func(candle core.Candle) {
+		bot.publishInformative(feed, candle)
+
+		if _, ok := bot.feeds[feed.key()]; ok {
+			bot.priorityQueueCandle.Push(feedCandle{Candle: candle, feed: feed})
+		}
+	}
+}
+
+// publishInformative sends a candle to the strategy controllers using the feed as informative
+func (bot *Bot) publishInformative(feed strategyFeed, candle core.Candle) {
+	for _, controller := range bot.informativeControllers[feed.key()] {
+		controller.OnInformativeCandle(feed.timeframe, candle)
+	}
 }
 
 // processCandle processes a single candle through the bot's systems
-func (bot *Bot) processCandle(ctx context.Context, candle core.Candle) {
-	if bot.paperWallet != nil {
+func (bot *Bot) processCandle(ctx context.Context, feed strategyFeed, candle core.Candle) {
+	base := bot.isBaseFeed(feed)
+	if base && bot.paperWallet != nil {
 		bot.paperWallet.OnCandle(candle)
 	}
 
-	bot.strategiesControllers[candle.Pair].OnPartialCandle(candle)
+	controllers := bot.strategiesControllers[feed.key()]
+	for _, controller := range controllers {
+		controller.OnPartialCandle(candle)
+	}
+
 	if candle.Complete {
-		bot.strategiesControllers[candle.Pair].OnCandle(ctx, candle)
-		bot.orderController.OnCandle(candle)
+		for _, controller := range controllers {
+			controller.OnCandle(ctx, candle)
+		}
+
+		if base {
+			bot.orderController.OnCandle(candle)
+		}
 	}
 }
 
 // processCandles processes pending candles in buffer
 func (bot *Bot) processCandles(ctx context.Context) {
 	for item := range bot.priorityQueueCandle.PopLock() {
-		bot.processCandle(ctx, item.(core.Candle))
+		candle := item.(feedCandle)
+		bot.processCandle(ctx, candle.feed, candle.Candle)
 	}
 }
 
-// backtestCandles processes candles for backtesting with a progress bar
+// backtestCandles processes candles for backtesting synchronously, in the priority queue order,
+// so identical inputs always produce identical trades
 func (bot *Bot) backtestCandles(ctx context.Context) {
 	bot.log.Info("Starting backtesting...")
 
 	for bot.priorityQueueCandle.Len() > 0 {
 		item := bot.priorityQueueCandle.Pop()
 
-		candle := item.(core.Candle)
+		candle := item.(feedCandle)
+		bot.backtestCandle(ctx, candle.feed, candle.Candle)
+	}
+}
+
+// backtestCandle runs a single candle through the wallet, the order controller,
+// the order feed and the strategies, in that order
+func (bot *Bot) backtestCandle(ctx context.Context, feed strategyFeed, candle core.Candle) {
+	if candle.Time.Before(bot.backtestStart) {
+		if candle.Complete {
+			for _, controller := range bot.strategiesControllers[feed.key()] {
+				controller.OnCandle(ctx, candle)
+			}
+		}
+		return
+	}
+	bot.startStrategies()
+
+	if bot.isBaseFeed(feed) {
 		if bot.paperWallet != nil {
 			bot.paperWallet.OnCandle(candle)
 		}
 
-		bot.strategiesControllers[candle.Pair].OnPartialCandle(candle)
 		if candle.Complete {
-			bot.strategiesControllers[candle.Pair].OnCandle(ctx, candle)
+			bot.orderController.OnCandle(candle)
+		}
+
+		// Fills triggered by the candle are published before strategies see it
+		bot.orderController.UpdateOrders(ctx)
+	}
+
+	controllers := bot.strategiesControllers[feed.key()]
+	for _, controller := range controllers {
+		controller.OnPartialCandle(candle)
+	}
+
+	if candle.Complete {
+		for _, controller := range controllers {
+			controller.OnCandle(ctx, candle)
 		}
+	}
+}
 
-		time.Sleep(1 * time.Millisecond) // prevent CPU overload
+// startStrategies starts the strategy controllers once
+func (bot *Bot) startStrategies() {
+	if bot.strategiesStarted {
+		return
+	}
+	bot.strategiesStarted = true
+
+	for _, controllers := range bot.strategiesControllers {
+		for _, controller := range controllers {
+			controller.Start()
+		}
 	}
 }
 
-// preload loads initial data needed for strategy indicators
-func (bot *Bot) preload(ctx context.Context, pair string) error {
+// preload loads initial data needed for strategy indicators,
+// fetching enough candles for the longest warmup among the strategies using the feed
+func (bot *Bot) preload(ctx context.Context, feed strategyFeed) error {
 	if bot.backtest {
 		return nil
 	}
 
-	candles, err := bot.exchange.CandlesByLimit(
-		ctx,
-		pair,
-		bot.strategy.Timeframe(),
-		bot.strategy.WarmupPeriod(),
-	)
+	candles, err := bot.exchange.CandlesByLimit(ctx, feed.pair, feed.timeframe, bot.warmups[feed.key()])
+	if err != nil {
+		return err
+	}
+
+	for _, candle := range candles {
+		bot.publishInformative(feed, candle)
+		bot.processCandle(ctx, feed, candle)
+	}
+
+	bot.dataFeed.Preload(ctx, feed.pair, feed.timeframe, candles)
+
+	return nil
+}
+
+// preloadInformative loads the warmup candles of a feed only used as informative
+func (bot *Bot) preloadInformative(ctx context.Context, feed strategyFeed) error {
+	if bot.backtest {
+		return nil
+	}
 
+	candles, err := bot.exchange.CandlesByLimit(ctx, feed.pair, feed.timeframe, bot.warmups[feed.key()])
 	if err != nil {
 		return err
 	}
 
 	for _, candle := range candles {
-		bot.processCandle(ctx, candle)
+		bot.publishInformative(feed, candle)
 	}
 
-	bot.dataFeed.Preload(ctx, pair, bot.strategy.Timeframe(), candles)
+	bot.dataFeed.Preload(ctx, feed.pair, feed.timeframe, candles)
 
 	return nil
 }
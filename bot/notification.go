@@ -16,11 +16,18 @@ func initializeNotifications(ctx context.Context, bot *Bot, settings *core.Setti
 			return err
 		}
 		// Register telegram as notifier
-		WithNotifier(bot.telegram)(bot)
+		bot.registerNotifier(bot.telegram)
 	}
 	return nil
 }
 
+// registerNotifier sets the notifier of the order controller and subscribes it to order updates
+func (bot *Bot) registerNotifier(notifier core.Notifier) {
+	bot.notifier = notifier
+	bot.orderController.SetNotifier(notifier)
+	bot.SubscribeOrder(notifier)
+}
+
 // SubscribeOrder subscribes the given subscribers to order updates for all pairs
 func (bot *Bot) SubscribeOrder(subscriptions ...core.OrderSubscriber) {
 	for _, pair := range bot.settings.Pairs {
@@ -30,11 +37,13 @@ func (bot *Bot) SubscribeOrder(subscriptions ...core.OrderSubscriber) {
 	}
 }
 
-// SubscribeCandle subscribes the given subscribers to candle updates for all pairs
+// SubscribeCandle subscribes the given subscribers to candle updates for all pairs,
+// using the lowest timeframe traded on each pair
 func (bot *Bot) SubscribeCandle(subscriptions ...core.CandleSubscriber) {
 	for _, pair := range bot.settings.Pairs {
+		feed := bot.baseFeeds[pair]
 		for _, subscription := range subscriptions {
-			bot.dataFeed.Subscribe(pair, bot.strategy.Timeframe(), subscription.OnCandle, false)
+			bot.dataFeed.Subscribe(pair, feed.timeframe, subscription.OnCandle, false)
 		}
 	}
 }
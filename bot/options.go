@@ -1,8 +1,12 @@
 package bot
 
 import (
+	"time"
+
 	"github.com/raykavin/backnrun/core"
 	"github.com/raykavin/backnrun/exchange"
+	"github.com/raykavin/backnrun/metric"
+	"github.com/raykavin/backnrun/order"
 )
 
 // Option is a functional option for configuring a Bot instance
@@ -18,6 +22,15 @@ func WithBacktest(wallet *exchange.PaperWallet) Option {
 	}
 }
 
+// WithBacktestStart makes the candles opening before start only warm up the strategies, as the
+// candles preloaded by a live bot: they skip the paper wallet and the order controller, and the
+// strategies don't trade on them
+func WithBacktestStart(start time.Time) Option {
+	return func(bot *Bot) {
+		bot.backtestStart = start
+	}
+}
+
 // WithStorage sets the storage for the bot, by default it uses a local file calledbot.db
 func WithStorage(storage core.Storage) Option {
 	return func(bot *Bot) {
@@ -29,15 +42,13 @@ func WithStorage(storage core.Storage) Option {
 func WithNotifier(notifier core.Notifier) Option {
 	return func(bot *Bot) {
 		bot.notifier = notifier
-		bot.orderController.SetNotifier(notifier)
-		bot.SubscribeOrder(notifier)
 	}
 }
 
 // WithCandleSubscription subscribes a given struct to the candle feed
 func WithCandleSubscription(subscriber core.CandleSubscriber) Option {
 	return func(bot *Bot) {
-		bot.SubscribeCandle(subscriber)
+		bot.candleSubscribers = append(bot.candleSubscribers, subscriber)
 	}
 }
 
@@ -51,6 +62,47 @@ func WithPaperWallet(wallet *exchange.PaperWallet) Option {
 // WithOrderSubscription subscribes a given struct to the order feed
 func WithOrderSubscription(subscriber core.OrderSubscriber) Option {
 	return func(bot *Bot) {
-		bot.SubscribeOrder(subscriber)
+		bot.orderSubscribers = append(bot.orderSubscribers, subscriber)
+	}
+}
+
+// WithStrategies binds additional strategies to the bot, each one with its own pairs and timeframe.
+// All strategies share the same order controller and storage, and their orders and trade results
+// are attributed to the binding name.
+func WithStrategies(bindings ...StrategyBinding) Option {
+	return func(bot *Bot) {
+		for _, binding := range bindings {
+			if binding.Name == "" {
+				binding.Name = strategyName(binding.Strategy)
+			}
+			bot.bindings = append(bot.bindings, binding)
+		}
+	}
+}
+
+// WithParameters records the parameter values the default strategy was built with, included in reports
+func WithParameters(params core.ParameterSet) Option {
+	return func(bot *Bot) {
+		bot.parameters = params
+	}
+}
+
+// WithMonteCarlo adds a Monte Carlo simulation of the trade sequence to the summary, reporting
+// percentiles of max drawdown, final equity and losing streak, and the risk of losing the ruin
+// threshold fraction of the initial paper wallet value
+func WithMonteCarlo(simulations int, ruinThreshold float64) Option {
+	return func(bot *Bot) {
+		bot.monteCarlo = []metric.MonteCarloOption{
+			metric.WithSimulations(simulations),
+			metric.WithRuinThreshold(ruinThreshold),
+		}
+	}
+}
+
+// WithRiskManager validates every order created by the strategies against the risk manager limits.
+// Rejected orders return an *order.RiskError, also sent to the notifier.
+func WithRiskManager(risk *order.RiskManager) Option {
+	return func(bot *Bot) {
+		bot.riskManager = risk
 	}
 }
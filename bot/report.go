@@ -0,0 +1,155 @@
+package bot
+
+import (
+	"sort"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/metric"
+	"github.com/raykavin/backnrun/report"
+)
+
+// Report builds a report of the results, to be saved as JSON or HTML once the bot is finished.
+// Equity, performance and benchmark data are included when the bot runs on a paper wallet.
+func (bot *Bot) Report() *report.Report {
+	result := &report.Report{
+		GeneratedAt: time.Now(),
+		Settings: report.Settings{
+			Pairs:    bot.settings.Pairs,
+			Backtest: bot.backtest,
+		},
+		Strategies: make([]report.Strategy, 0, len(bot.bindings)),
+		Pairs:      make([]report.PairSummary, 0, len(bot.orderController.Results)),
+		Trades:     make([]report.Trade, 0),
+		Confidence: make([]report.ConfidenceIntervals, 0, len(bot.orderController.Results)),
+		Equity:     make([]report.Point, 0),
+	}
+
+	for _, binding := range bot.bindings {
+		result.Strategies = append(result.Strategies, report.Strategy{
+			Name:       reportStrategyName(binding),
+			Timeframe:  binding.Timeframe,
+			Pairs:      binding.Pairs,
+			Parameters: strategyParameters(binding),
+		})
+	}
+
+	for _, summary := range bot.orderController.Results {
+		wins, losses := len(summary.Win()), len(summary.Lose())
+		pairSummary := report.PairSummary{
+			Strategy:     summary.Strategy,
+			Pair:         summary.Pair,
+			Trades:       wins + losses,
+			Wins:         wins,
+			Losses:       losses,
+			WinRate:      summary.WinPercentage() / 100,
+			Payoff:       summary.Payoff(),
+			ProfitFactor: summary.ProfitFactor(),
+			SQN:          summary.SQN(),
+			Profit:       summary.Profit(),
+			Fees:         summary.TradeFees(),
+			NetProfit:    summary.NetProfit(),
+			Volume:       summary.Volume,
+			Slippage:     summary.Slippage,
+		}
+		result.Pairs = append(result.Pairs, pairSummary)
+
+		returns, payoff, profitFactor := confidenceIntervals(summary)
+		result.Confidence = append(result.Confidence, report.ConfidenceIntervals{
+			Strategy:     summary.Strategy,
+			Pair:         summary.Pair,
+			Confidence:   bootstrapConfidence,
+			Return:       returns,
+			Payoff:       payoff,
+			ProfitFactor: profitFactor,
+		})
+
+		for _, trade := range summary.Trades {
+			result.Trades = append(result.Trades, report.Trade{
+				Strategy:      trade.Strategy,
+				Pair:          trade.Pair,
+				Side:          trade.Side,
+				EntryPrice:    trade.EntryPrice,
+				ExitPrice:     trade.ExitPrice,
+				Quantity:      trade.Quantity,
+				Profit:        trade.ProfitValue,
+				ProfitPercent: trade.ProfitPercent,
+				Fee:           trade.Fee,
+				NetProfit:     trade.NetProfit(),
+				OpenedAt:      trade.CreatedAt.Add(-trade.Duration),
+				ClosedAt:      trade.CreatedAt,
+				Duration:      trade.Duration,
+			})
+		}
+	}
+
+	sort.Slice(result.Pairs, func(i, j int) bool {
+		if result.Pairs[i].Strategy != result.Pairs[j].Strategy {
+			return result.Pairs[i].Strategy < result.Pairs[j].Strategy
+		}
+		return result.Pairs[i].Pair < result.Pairs[j].Pair
+	})
+
+	sort.Slice(result.Confidence, func(i, j int) bool {
+		if result.Confidence[i].Strategy != result.Confidence[j].Strategy {
+			return result.Confidence[i].Strategy < result.Confidence[j].Strategy
+		}
+		return result.Confidence[i].Pair < result.Confidence[j].Pair
+	})
+
+	sort.SliceStable(result.Trades, func(i, j int) bool {
+		return result.Trades[i].ClosedAt.Before(result.Trades[j].ClosedAt)
+	})
+
+	if bot.paperWallet != nil {
+		for _, point := range metric.MergeEquityPoints(bot.paperWallet.EquityCurve()) {
+			result.Equity = append(result.Equity, report.Point{Time: point.Time, Value: point.Value})
+		}
+
+		performance := bot.EquityMetrics()
+		benchmark := bot.BenchmarkMetrics()
+		result.Performance = &performance
+		result.Benchmark = &benchmark
+
+		wallet := &report.Wallet{
+			InitialValue: bot.paperWallet.InitialValue(),
+			FinalValue:   bot.paperWallet.InitialValue(),
+			MaxDrawdown:  performance.MaxDrawdown,
+		}
+		if len(result.Equity) > 0 {
+			wallet.FinalValue = result.Equity[len(result.Equity)-1].Value
+		}
+		wallet.Profit = wallet.FinalValue - wallet.InitialValue
+		result.Wallet = wallet
+	}
+
+	result.Drawdown = report.Drawdown(result.Equity)
+	return result
+}
+
+// reportStrategyName returns the name of a binding displayed in reports
+func reportStrategyName(binding StrategyBinding) string {
+	if binding.Name != "" {
+		return binding.Name
+	}
+	return strategyName(binding.Strategy)
+}
+
+// strategyParameters returns the parameters recorded for a binding,
+// or the defaults of the strategy parameters when none were recorded
+func strategyParameters(binding StrategyBinding) core.ParameterSet {
+	if binding.Parameters != nil {
+		return binding.Parameters
+	}
+
+	evaluator, ok := binding.Strategy.(core.StrategyEvaluator)
+	if !ok {
+		return nil
+	}
+
+	params := make(core.ParameterSet)
+	for _, param := range evaluator.GetParameters() {
+		params[param.Name] = param.Default
+	}
+	return params
+}
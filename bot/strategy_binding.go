@@ -0,0 +1,185 @@
+package bot
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	strg "github.com/raykavin/backnrun/strategy"
+)
+
+// StrategyBinding binds a strategy to the pairs and timeframe it trades
+type StrategyBinding struct {
+	// Name identifies the strategy in orders and trade results, defaults to the strategy type name
+	Name string
+	// Strategy is the strategy executed for every bound pair
+	Strategy core.Strategy
+	// Pairs are the pairs the strategy trades, defaults to the pairs in the bot settings
+	Pairs []string
+	// Timeframe is the candle timeframe fed to the strategy, defaults to Strategy.Timeframe()
+	Timeframe string
+	// Parameters are the parameter values the strategy was built with, recorded in reports
+	Parameters core.ParameterSet
+}
+
+// strategyFeed identifies a candle feed consumed by one or more strategy controllers
+type strategyFeed struct {
+	pair      string
+	timeframe string
+	duration  time.Duration
+}
+
+// key returns the unique key of the feed
+func (f strategyFeed) key() string {
+	return feedKey(f.pair, f.timeframe)
+}
+
+// feedCandle is a candle tagged with the feed it was received from.
+// Candles are ordered by close time, so a higher timeframe candle is only processed
+// after every lower timeframe candle that closed before it.
+type feedCandle struct {
+	core.Candle
+	feed strategyFeed
+}
+
+// Less implements the Item interface for comparison in priority queue
+func (c feedCandle) Less(j core.Item) bool {
+	other := j.(feedCandle)
+
+	closeTime := c.Time.Add(c.feed.duration)
+	otherCloseTime := other.Time.Add(other.feed.duration)
+	if !closeTime.Equal(otherCloseTime) {
+		return closeTime.Before(otherCloseTime)
+	}
+
+	if c.feed.duration != other.feed.duration {
+		return c.feed.duration < other.feed.duration
+	}
+
+	return c.Candle.Less(other.Candle)
+}
+
+// feedKey generates a unique key for a pair and timeframe
+func feedKey(pair, timeframe string) string {
+	return fmt.Sprintf("%s--%s", pair, timeframe)
+}
+
+// strategyName returns the type name of a strategy, used when a binding has no name
+func strategyName(strategy core.Strategy) string {
+	if strategy == nil {
+		return ""
+	}
+
+	t := reflect.TypeOf(strategy)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// resolveBindings fills binding defaults, validates them and indexes the feeds they consume
+func (n *Bot) resolveBindings() error {
+	names := make(map[string]bool)
+	for i := range n.bindings {
+		binding := &n.bindings[i]
+		if binding.Strategy == nil {
+			return fmt.Errorf("strategy cannot be nil")
+		}
+
+		if binding.Name != "" {
+			if names[binding.Name] {
+				return fmt.Errorf("duplicated strategy binding: %s", binding.Name)
+			}
+			names[binding.Name] = true
+		}
+
+		if len(binding.Pairs) == 0 {
+			binding.Pairs = n.settings.Pairs
+		}
+
+		if len(binding.Pairs) == 0 {
+			return fmt.Errorf("strategy %s has no pairs", binding.Name)
+		}
+
+		if binding.Timeframe == "" {
+			binding.Timeframe = binding.Strategy.Timeframe()
+		}
+
+		if err := validatePairs(binding.Pairs); err != nil {
+			return err
+		}
+
+		for _, pair := range binding.Pairs {
+			n.addFeed(pair, binding.Timeframe)
+		}
+	}
+
+	return nil
+}
+
+// addFeed registers a pair and timeframe feed, tracking the lowest timeframe of each pair.
+// The lowest timeframe feed drives the paper wallet and the order controller.
+func (n *Bot) addFeed(pair, timeframe string) {
+	feed := strategyFeed{pair: pair, timeframe: timeframe, duration: strg.TimeframeDuration(timeframe)}
+	if _, ok := n.feeds[feed.key()]; ok {
+		return
+	}
+
+	n.feeds[feed.key()] = feed
+	n.feedOrder = append(n.feedOrder, feed.key())
+
+	base, ok := n.baseFeeds[pair]
+	if !ok {
+		n.settings.Pairs = appendUnique(n.settings.Pairs, pair)
+	}
+
+	if !ok || feed.duration < base.duration {
+		n.baseFeeds[pair] = feed
+	}
+}
+
+// addInformativeFeed registers a controller as consumer of an informative feed.
+// Feeds that are not traded by any strategy are subscribed only to keep informative dataframes updated.
+func (n *Bot) addInformativeFeed(pair, timeframe string, controller *strg.Controller, warmup int) {
+	feed := strategyFeed{pair: pair, timeframe: timeframe, duration: strg.TimeframeDuration(timeframe)}
+	key := feed.key()
+
+	_, traded := n.feeds[key]
+	if _, ok := n.informativeControllers[key]; !ok && !traded {
+		n.informativeFeeds = append(n.informativeFeeds, feed)
+	}
+
+	n.informativeControllers[key] = append(n.informativeControllers[key], controller)
+	n.setWarmup(key, warmup)
+}
+
+// setWarmup keeps the largest number of candles to preload for a feed
+func (n *Bot) setWarmup(key string, warmup int) {
+	if warmup > n.warmups[key] {
+		n.warmups[key] = warmup
+	}
+}
+
+// isBaseFeed reports whether the feed is the lowest timeframe of its pair
+func (n *Bot) isBaseFeed(feed strategyFeed) bool {
+	return n.baseFeeds[feed.pair].timeframe == feed.timeframe
+}
+
+// strategyBroker returns the broker used by the strategies of a binding
+func (n *Bot) strategyBroker(binding StrategyBinding) core.Broker {
+	if binding.Name == "" {
+		return n.orderController
+	}
+	return n.orderController.StrategyBroker(binding.Name)
+}
+
+// appendUnique appends a value to the slice when it is not already present
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -5,10 +5,18 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/aybabtme/uniplot/histogram"
 	"github.com/olekukonko/tablewriter"
 	"github.com/raykavin/backnrun/metric"
+	"github.com/raykavin/backnrun/order"
+)
+
+// Settings of the bootstrap confidence intervals
+const (
+	bootstrapSamples    = 10000
+	bootstrapConfidence = 0.95
 )
 
 // Summary displays all trades, accuracy and bot metrics in stdout
@@ -16,15 +24,28 @@ import (
 func (bot *Bot) Summary() {
 	var (
 		total  float64
+		fees   float64
 		wins   int
 		loses  int
 		volume float64
 		sqn    float64
 	)
 
+	// Results are attributed per strategy when named strategies are bound to the bot
+	byStrategy := false
+	for _, summary := range bot.orderController.Results {
+		if summary.Strategy != "" {
+			byStrategy = true
+		}
+	}
+
 	buffer := bytes.NewBuffer(nil)
 	table := tablewriter.NewWriter(buffer)
-	table.SetHeader([]string{"Pair", "Trades", "Win", "Loss", "% Win", "Payoff", "Pr Fact.", "SQN", "Profit", "Volume"})
+	header := []string{"Pair", "Trades", "Win", "Loss", "% Win", "Payoff", "Pr Fact.", "SQN", "Profit", "Fees", "Net Profit", "Volume"}
+	if byStrategy {
+		header = append([]string{"Strategy"}, header...)
+	}
+	table.SetHeader(header)
 	table.SetFooterAlignment(tablewriter.ALIGN_RIGHT)
 	avgPayoff := 0.0
 	avgProfitFactor := 0.0
@@ -33,7 +54,7 @@ func (bot *Bot) Summary() {
 	for _, summary := range bot.orderController.Results {
 		avgPayoff += summary.Payoff() * float64(len(summary.Win())+len(summary.Lose()))
 		avgProfitFactor += summary.ProfitFactor() * float64(len(summary.Win())+len(summary.Lose()))
-		table.Append([]string{
+		row := []string{
 			summary.Pair,
 			strconv.Itoa(len(summary.Win()) + len(summary.Lose())),
 			strconv.Itoa(len(summary.Win())),
@@ -43,9 +64,16 @@ func (bot *Bot) Summary() {
 			fmt.Sprintf("%.3f", summary.ProfitFactor()),
 			fmt.Sprintf("%.1f", summary.SQN()),
 			fmt.Sprintf("%.2f", summary.Profit()),
+			fmt.Sprintf("%.2f", summary.TradeFees()),
+			fmt.Sprintf("%.2f", summary.NetProfit()),
 			fmt.Sprintf("%.2f", summary.Volume),
-		})
+		}
+		if byStrategy {
+			row = append([]string{summaryStrategy(summary.Strategy)}, row...)
+		}
+		table.Append(row)
 		total += summary.Profit()
+		fees += summary.TradeFees()
 		sqn += summary.SQN()
 		wins += len(summary.Win())
 		loses += len(summary.Lose())
@@ -55,7 +83,7 @@ func (bot *Bot) Summary() {
 		returns = append(returns, summary.LosePercent()...)
 	}
 
-	table.SetFooter([]string{
+	footer := []string{
 		"TOTAL",
 		strconv.Itoa(wins + loses),
 		strconv.Itoa(wins),
@@ -65,8 +93,14 @@ func (bot *Bot) Summary() {
 		fmt.Sprintf("%.3f", avgProfitFactor/float64(wins+loses)),
 		fmt.Sprintf("%.1f", sqn/float64(len(bot.orderController.Results))),
 		fmt.Sprintf("%.2f", total),
+		fmt.Sprintf("%.2f", fees),
+		fmt.Sprintf("%.2f", total-fees),
 		fmt.Sprintf("%.2f", volume),
-	})
+	}
+	if byStrategy {
+		footer = append([]string{""}, footer...)
+	}
+	table.SetFooter(footer)
 	table.Render()
 
 	fmt.Println(buffer.String())
@@ -82,12 +116,9 @@ func (bot *Bot) Summary() {
 	fmt.Println()
 
 	fmt.Println("------ CONFIDENCE INTERVAL (95%) -------")
-	for pair, summary := range bot.orderController.Results {
-		fmt.Printf("| %s |\n", pair)
-		returns := append(summary.WinPercent(), summary.LosePercent()...)
-		returnsInterval := metric.Bootstrap(returns, metric.Mean, 10000, 0.95)
-		payoffInterval := metric.Bootstrap(returns, metric.Payoff, 10000, 0.95)
-		profitFactorInterval := metric.Bootstrap(returns, metric.ProfitFactor, 10000, 0.95)
+	for key, summary := range bot.orderController.Results {
+		fmt.Printf("| %s |\n", key)
+		returnsInterval, payoffInterval, profitFactorInterval := confidenceIntervals(summary)
 
 		fmt.Printf("RETURN:      %.2f%% (%.2f%% ~ %.2f%%)\n",
 			returnsInterval.Mean*100, returnsInterval.Lower*100, returnsInterval.Upper*100)
@@ -99,18 +130,158 @@ func (bot *Bot) Summary() {
 
 	fmt.Println()
 
+	if bot.paperWallet != nil {
+		bot.equitySummary()
+		bot.benchmarkSummary()
+	}
+
+	if bot.monteCarlo != nil {
+		bot.monteCarloSummary()
+	}
+
 	if bot.paperWallet != nil {
 		bot.paperWallet.Summary()
 	}
 }
 
+// EquityMetrics returns the performance metrics of the paper wallet equity curve, annualized by the
+// lowest timeframe driving the wallet. Metrics are empty when the bot has no paper wallet.
+func (bot *Bot) EquityMetrics() metric.EquityMetrics {
+	if bot.paperWallet == nil {
+		return metric.EquityMetrics{}
+	}
+	return metric.CalculateEquityMetrics(bot.paperWallet.EquityCurve(), bot.equityTimeframe())
+}
+
+// BenchmarkMetrics compares the paper wallet equity curve with an equal-weight buy-and-hold
+// portfolio of the traded pairs. Metrics are empty when the bot has no paper wallet.
+func (bot *Bot) BenchmarkMetrics() metric.BenchmarkMetrics {
+	if bot.paperWallet == nil {
+		return metric.BenchmarkMetrics{}
+	}
+	return metric.CalculateBenchmarkMetrics(bot.paperWallet.EquityCurve(), bot.paperWallet.BenchmarkCurve(),
+		bot.equityTimeframe())
+}
+
+// equityTimeframe returns the lowest timeframe driving the paper wallet
+func (bot *Bot) equityTimeframe() time.Duration {
+	var timeframe time.Duration
+	for _, feed := range bot.baseFeeds {
+		if timeframe == 0 || (feed.duration > 0 && feed.duration < timeframe) {
+			timeframe = feed.duration
+		}
+	}
+	return timeframe
+}
+
+// equitySummary prints the performance metrics of the paper wallet equity curve
+func (bot *Bot) equitySummary() {
+	metrics := bot.EquityMetrics()
+
+	fmt.Println("------ PERFORMANCE -------")
+	fmt.Printf("ANNUAL RETURN:    %.2f%%\n", metrics.AnnualReturn*100)
+	fmt.Printf("VOLATILITY:       %.2f%%\n", metrics.Volatility*100)
+	fmt.Printf("SHARPE RATIO:     %.2f\n", metrics.SharpeRatio)
+	fmt.Printf("SORTINO RATIO:    %.2f\n", metrics.SortinoRatio)
+	fmt.Printf("CALMAR RATIO:     %.2f\n", metrics.CalmarRatio)
+	fmt.Printf("ULCER INDEX:      %.2f%%\n", metrics.UlcerIndex*100)
+	fmt.Printf("TIME IN MARKET:   %.2f%%\n", metrics.TimeInMarket*100)
+	fmt.Printf("AVG DD DURATION:  %s\n", metrics.AvgDrawdownDuration)
+	fmt.Println()
+}
+
+// benchmarkSummary prints the performance of the paper wallet relative to buy-and-hold
+func (bot *Bot) benchmarkSummary() {
+	metrics := bot.BenchmarkMetrics()
+
+	fmt.Println("------ BENCHMARK (BUY & HOLD) -------")
+	fmt.Printf("STRATEGY RETURN:   %.2f%%\n", metrics.Return*100)
+	fmt.Printf("BENCHMARK RETURN:  %.2f%%\n", metrics.BenchmarkReturn*100)
+	fmt.Printf("EXCESS RETURN:     %.2f%%\n", metrics.ExcessReturn*100)
+	fmt.Printf("ALPHA:             %.2f%%\n", metrics.Alpha*100)
+	fmt.Printf("BETA:              %.2f\n", metrics.Beta)
+	fmt.Printf("CORRELATION:       %.2f\n", metrics.Correlation)
+	fmt.Printf("INFORMATION RATIO: %.2f\n", metrics.InformationRatio)
+	fmt.Printf("UP CAPTURE:        %.2f%%\n", metrics.UpCapture*100)
+	fmt.Printf("DOWN CAPTURE:      %.2f%%\n", metrics.DownCapture*100)
+	fmt.Println()
+}
+
+// monteCarloSummary prints the percentiles of a Monte Carlo simulation over the net results
+// of the trades of all pairs
+func (bot *Bot) monteCarloSummary() {
+	trades := make([]float64, 0)
+	for _, summary := range bot.orderController.Results {
+		for _, trade := range summary.Trades {
+			trades = append(trades, trade.NetProfit())
+		}
+	}
+
+	initialEquity := 0.0
+	if bot.paperWallet != nil {
+		initialEquity = bot.paperWallet.InitialValue()
+	}
+
+	if len(trades) == 0 || initialEquity <= 0 {
+		return
+	}
+
+	result := metric.MonteCarlo(trades, initialEquity, bot.monteCarlo...)
+	percentiles := []float64{0.05, 0.25, 0.5, 0.75, 0.95}
+
+	buffer := bytes.NewBuffer(nil)
+	table := tablewriter.NewWriter(buffer)
+	table.SetHeader([]string{"Measure", "P5", "P25", "P50", "P75", "P95"})
+	rows := []struct {
+		name         string
+		distribution metric.Distribution
+		format       func(float64) string
+	}{
+		{"Max Drawdown", result.MaxDrawdown, func(v float64) string { return fmt.Sprintf("%.2f %%", v*100) }},
+		{"Final Equity", result.FinalEquity, func(v float64) string { return fmt.Sprintf("%.2f", v) }},
+		{"Losing Streak", result.LosingStreak, func(v float64) string { return fmt.Sprintf("%.0f", v) }},
+	}
+	for _, row := range rows {
+		values := []string{row.name}
+		for _, p := range percentiles {
+			values = append(values, row.format(row.distribution.Percentile(p)))
+		}
+		table.Append(values)
+	}
+	table.Render()
+
+	fmt.Printf("------ MONTE CARLO (%d simulations) -------\n", result.Simulations)
+	fmt.Print(buffer.String())
+	fmt.Printf("RISK OF RUIN (%.0f%% loss): %.2f%%\n", result.RuinThreshold*100, result.RiskOfRuin*100)
+	fmt.Println()
+}
+
+// confidenceIntervals returns the bootstrap intervals of the mean return, payoff and profit factor of the trades
+func confidenceIntervals(summary *order.TradeSummary) (returns, payoff, profitFactor metric.BootstrapInterval) {
+	values := append(summary.WinPercent(), summary.LosePercent()...)
+	return metric.Bootstrap(values, metric.Mean, bootstrapSamples, bootstrapConfidence),
+		metric.Bootstrap(values, metric.Payoff, bootstrapSamples, bootstrapConfidence),
+		metric.Bootstrap(values, metric.ProfitFactor, bootstrapSamples, bootstrapConfidence)
+}
+
 // SaveReturns saves trade returns to CSV files in the specified directory
 func (bot Bot) SaveReturns(outputDir string) error {
 	for _, summary := range bot.orderController.Results {
 		outputFile := fmt.Sprintf("%s/%s.csv", outputDir, summary.Pair)
+		if summary.Strategy != "" {
+			outputFile = fmt.Sprintf("%s/%s-%s.csv", outputDir, summary.Strategy, summary.Pair)
+		}
 		if err := summary.SaveReturns(outputFile); err != nil {
 			return err
 		}
 	}
 	return nil
 }
+
+// summaryStrategy returns the strategy label displayed in the summary
+func summaryStrategy(strategy string) string {
+	if strategy == "" {
+		return "default"
+	}
+	return strategy
+}
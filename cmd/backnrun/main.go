@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/raykavin/backnrun/core"
 	"github.com/raykavin/backnrun/exchange/binance"
 	"github.com/raykavin/backnrun/internal/backtesting"
+	"github.com/raykavin/backnrun/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -25,6 +27,7 @@ var (
 	endDate    string
 	timeframe  string
 	outputFile string
+	database   string
 	isFutures  bool
 )
 
@@ -60,17 +63,22 @@ func buildDownloadCmd() *cobra.Command {
 	downloadCmd.Flags().StringVarP(&endDate, "end", "e", "", "End date (e.g. 2020-12-31)")
 	downloadCmd.Flags().StringVarP(&timeframe, "timeframe", "t", "", "Timeframe (e.g. 1h)")
 	downloadCmd.Flags().StringVarP(&outputFile, "output", "o", "", "Output file path (e.g. ./btc.csv)")
+	downloadCmd.Flags().StringVar(&database, "database", "", "SQLite database storing the candles (e.g. ./candles.db)")
 	downloadCmd.Flags().BoolVarP(&isFutures, "futures", "f", false, "Use futures market")
 
 	// Required flags
 	downloadCmd.MarkFlagRequired("pair")
 	downloadCmd.MarkFlagRequired("timeframe")
-	downloadCmd.MarkFlagRequired("output")
+	downloadCmd.MarkFlagsMutuallyExclusive("output", "database")
 
 	return downloadCmd
 }
 
 func runDownload(cmd *cobra.Command, args []string) error {
+	if outputFile == "" && database == "" {
+		return fmt.Errorf("OUTPUT or DATABASE must be provided")
+	}
+
 	// Initialize exchange
 	exc, err := initializeExchange(cmd)
 	if err != nil {
@@ -83,8 +91,22 @@ func runDownload(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	downloader := backtesting.NewDownloader(exc, bot.DefaultLog)
+
+	// Store the candles in the database, to be served by a cached feed
+	if database != "" {
+		db, err := storage.FromSQLite(database)
+		if err != nil {
+			return err
+		}
+		if closer, ok := db.(io.Closer); ok {
+			defer closer.Close()
+		}
+		return downloader.DownloadToStorage(cmd.Context(), pair, timeframe, db, options...)
+	}
+
 	// Run the download
-	return backtesting.NewDownloader(exc, bot.DefaultLog).Download(
+	return downloader.Download(
 		cmd.Context(),
 		pair,
 		timeframe,
@@ -40,12 +40,21 @@ type Strategy interface {
 
 	// Indicators will be executed for each new candle, in order to fill indicators before `OnCandle` function is called.
 	Indicators(df *Dataframe) []ChartIndicator
-	
+
 	// OnCandle will be executed for each new candle, after indicators are filled, here you can do your trading logic.
 	// OnCandle is executed after the candle close.
 	OnCandle(ctx context.Context, df *Dataframe, broker Broker)
 }
 
+// InformativeStrategy is a strategy that receives extra pairs or timeframes on its dataframe
+type InformativeStrategy interface {
+	Strategy
+
+	// Informative returns the extra feeds made available in Dataframe.Informative.
+	// A candle of an informative feed is only added once it has closed, so there is no look-ahead.
+	Informative() []InformativeFeed
+}
+
 type HighFrequencyStrategy interface {
 	Strategy
 
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"fmt"
 	"time"
 )
 
@@ -19,6 +20,29 @@ type Dataframe struct {
 
 	// Custom user metadata for indicators
 	Metadata map[string]Series[float64]
+
+	// Informative dataframes of other timeframes or pairs, indexed by InformativeFeed.Key
+	Informative map[string]*Dataframe
+}
+
+// InformativeFeed describes an extra pair or timeframe requested by a strategy
+type InformativeFeed struct {
+	// Pair of the feed, empty means the pair of the strategy
+	Pair string
+	// Timeframe of the feed, eg: 4h, 1d
+	Timeframe string
+	// WarmupPeriod is the number of candles kept in the informative dataframe,
+	// defaults to the strategy warmup period
+	WarmupPeriod int
+}
+
+// Key returns the key of the feed in Dataframe.Informative.
+// It is the timeframe for the strategy pair (eg: "4h") and "PAIR:timeframe" for other pairs (eg: "ETHUSDT:4h")
+func (f InformativeFeed) Key() string {
+	if f.Pair == "" {
+		return f.Timeframe
+	}
+	return fmt.Sprintf("%s:%s", f.Pair, f.Timeframe)
 }
 
 // Sample returns a subset of the dataframe with the last 'positions' elements
@@ -33,15 +57,16 @@ func (df Dataframe) Sample(positions int) Dataframe {
 	}
 
 	sample := Dataframe{
-		Pair:       df.Pair,
-		Close:      df.Close.LastValues(positions),
-		Open:       df.Open.LastValues(positions),
-		High:       df.High.LastValues(positions),
-		Low:        df.Low.LastValues(positions),
-		Volume:     df.Volume.LastValues(positions),
-		Time:       df.Time[start:],
-		LastUpdate: df.LastUpdate,
-		Metadata:   make(map[string]Series[float64]),
+		Pair:        df.Pair,
+		Close:       df.Close.LastValues(positions),
+		Open:        df.Open.LastValues(positions),
+		High:        df.High.LastValues(positions),
+		Low:         df.Low.LastValues(positions),
+		Volume:      df.Volume.LastValues(positions),
+		Time:        df.Time[start:],
+		LastUpdate:  df.LastUpdate,
+		Metadata:    make(map[string]Series[float64]),
+		Informative: df.Informative,
 	}
 
 	// Also copy metadata series
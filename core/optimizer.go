@@ -44,7 +44,7 @@ type ParameterSet map[string]any
 type MetricName string
 
 const (
-	// MetricProfit represents the total profit
+	// MetricProfit represents the total profit after fees
 	MetricProfit MetricName = "profit"
 	// MetricWinRate represents the percentage of winning trades
 	MetricWinRate MetricName = "win_rate"
@@ -60,8 +60,41 @@ const (
 	MetricSharpeRatio MetricName = "sharpe_ratio"
 	// MetricTradeCount represents the total number of trades
 	MetricTradeCount MetricName = "trade_count"
+	// MetricAnnualReturn represents the compound annual growth rate of the equity
+	MetricAnnualReturn MetricName = "annual_return"
+	// MetricVolatility represents the annualized volatility of the equity returns
+	MetricVolatility MetricName = "volatility"
+	// MetricSortinoRatio represents the Sortino ratio
+	MetricSortinoRatio MetricName = "sortino_ratio"
+	// MetricCalmarRatio represents the Calmar ratio
+	MetricCalmarRatio MetricName = "calmar_ratio"
+	// MetricUlcerIndex represents the Ulcer index
+	MetricUlcerIndex MetricName = "ulcer_index"
+	// MetricTimeInMarket represents the fraction of time with an open position
+	MetricTimeInMarket MetricName = "time_in_market"
+	// MetricAvgDrawdownDuration represents the average drawdown duration in hours
+	MetricAvgDrawdownDuration MetricName = "avg_drawdown_duration"
 )
 
+// Objective is a metric to optimize with its direction
+type Objective struct {
+	Metric   MetricName
+	Maximize bool
+}
+
+// Better reports whether value a is strictly better than value b for the objective
+func (o Objective) Better(a, b float64) bool {
+	if o.Maximize {
+		return a > b
+	}
+	return a < b
+}
+
+// String returns the metric name of the objective
+func (o Objective) String() string {
+	return string(o.Metric)
+}
+
 // ParameterType defines the data type of a parameter
 type ParameterType string
 
@@ -59,6 +59,13 @@ type Order struct {
 	Status     OrderStatusType `db:"status" json:"status"`
 	Price      float64         `db:"price" json:"price"`
 	Quantity   float64         `db:"quantity" json:"quantity"`
+	Strategy   string          `db:"strategy" json:"strategy,omitempty"`
+
+	// Execution details, Price is the average price of the executions
+	ExecutedQuantity float64 `db:"executed_quantity" json:"executed_quantity,omitempty"`
+	Slippage         float64 `db:"slippage" json:"slippage,omitempty"` // Slippage cost of the fills in quote currency
+	Fee              float64 `db:"fee" json:"fee,omitempty"`           // Fees charged on the fills, in FeeAsset
+	FeeAsset         string  `db:"fee_asset" json:"fee_asset,omitempty"`
 
 	CreatedAt time.Time `db:"created_at" json:"created_at"`
 	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
@@ -114,6 +121,35 @@ func (o Order) GetQuantity() float64 {
 	return o.Quantity
 }
 
+// GetExecutedQuantity returns the quantity filled so far.
+// Filled orders that don't record it were executed in full.
+func (o Order) GetExecutedQuantity() float64 {
+	if o.ExecutedQuantity == 0 && o.Status == OrderStatusTypeFilled {
+		return o.Quantity
+	}
+	return o.ExecutedQuantity
+}
+
+// GetStrategy returns the name of the strategy that placed the order
+func (o Order) GetStrategy() string {
+	return o.Strategy
+}
+
+// GetSlippage returns the slippage cost of the fill in quote currency
+func (o Order) GetSlippage() float64 {
+	return o.Slippage
+}
+
+// GetFee returns the fee charged on the fill
+func (o Order) GetFee() float64 {
+	return o.Fee
+}
+
+// GetFeeAsset returns the asset the fee was charged in
+func (o Order) GetFeeAsset() string {
+	return o.FeeAsset
+}
+
 // GetCreatedAt returns the order creation time
 func (o Order) GetCreatedAt() time.Time {
 	return o.CreatedAt
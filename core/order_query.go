@@ -0,0 +1,150 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+)
+
+// ErrInvalidOrderField is returned when an order query sorts by an unsupported field
+var ErrInvalidOrderField = errors.New("invalid order field")
+
+// OrderField is an order field orders can be sorted by
+type OrderField string
+
+// Sortable order fields, named after their storage columns
+const (
+	OrderFieldID        OrderField = "id"
+	OrderFieldCreatedAt OrderField = "created_at"
+	OrderFieldUpdatedAt OrderField = "updated_at"
+)
+
+// Valid reports whether orders can be sorted by the field
+func (f OrderField) Valid() bool {
+	return f == OrderFieldID || f == OrderFieldCreatedAt || f == OrderFieldUpdatedAt
+}
+
+// OrderQuery is a structured order query that storages translate into their own queries.
+// All conditions must match, zero values match every order. Filters are applied in memory
+// after the structured conditions, and paging applies to the orders matching both.
+type OrderQuery struct {
+	Status      []OrderStatusType // Any of the statuses
+	Pair        string
+	Side        SideType
+	GroupID     *int64
+	CreatedFrom time.Time // Inclusive lower bound of the creation time
+	CreatedTo   time.Time // Inclusive upper bound of the creation time
+	UpdatedFrom time.Time // Inclusive lower bound of the update time
+	UpdatedTo   time.Time // Inclusive upper bound of the update time
+
+	Filters []OrderFilter
+
+	OrderBy    OrderField // Sort field, the update time when empty
+	Descending bool
+	Offset     int
+	Limit      int // Maximum number of orders, unlimited when zero
+}
+
+// Validate checks that the query can be executed
+func (q OrderQuery) Validate() error {
+	if q.OrderBy != "" && !q.OrderBy.Valid() {
+		return fmt.Errorf("%w: %s", ErrInvalidOrderField, q.OrderBy)
+	}
+	return nil
+}
+
+// SortField returns the field orders are sorted by
+func (q OrderQuery) SortField() OrderField {
+	if q.OrderBy == "" {
+		return OrderFieldUpdatedAt
+	}
+	return q.OrderBy
+}
+
+// MatchConditions reports whether an order matches the structured conditions of the query
+func (q OrderQuery) MatchConditions(order Order) bool {
+	switch {
+	case len(q.Status) > 0 && !slices.Contains(q.Status, order.Status):
+		return false
+	case q.Pair != "" && order.Pair != q.Pair:
+		return false
+	case q.Side != "" && order.Side != q.Side:
+		return false
+	case q.GroupID != nil && (order.GroupID == nil || *order.GroupID != *q.GroupID):
+		return false
+	case !inTimeRange(order.CreatedAt, q.CreatedFrom, q.CreatedTo):
+		return false
+	case !inTimeRange(order.UpdatedAt, q.UpdatedFrom, q.UpdatedTo):
+		return false
+	}
+	return true
+}
+
+// MatchFilters reports whether an order passes the in-memory filters of the query
+func (q OrderQuery) MatchFilters(order Order) bool {
+	for _, filter := range q.Filters {
+		if !filter(order) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match reports whether an order matches the conditions and filters of the query
+func (q OrderQuery) Match(order Order) bool {
+	return q.MatchConditions(order) && q.MatchFilters(order)
+}
+
+// Apply selects, sorts and pages orders in memory
+func (q OrderQuery) Apply(orders []*Order) []*Order {
+	selected := make([]*Order, 0, len(orders))
+	for _, order := range orders {
+		if q.Match(*order) {
+			selected = append(selected, order)
+		}
+	}
+
+	q.Sort(selected)
+	return q.Page(selected)
+}
+
+// Sort sorts orders by the query sort field, ties by ID
+func (q OrderQuery) Sort(orders []*Order) {
+	field := q.SortField()
+	sort.SliceStable(orders, func(i, j int) bool {
+		a, b := orders[i], orders[j]
+		if q.Descending {
+			a, b = b, a
+		}
+
+		switch field {
+		case OrderFieldCreatedAt:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		case OrderFieldUpdatedAt:
+			if !a.UpdatedAt.Equal(b.UpdatedAt) {
+				return a.UpdatedAt.Before(b.UpdatedAt)
+			}
+		}
+		return a.ID < b.ID
+	})
+}
+
+// Page returns the orders selected by the query offset and limit
+func (q OrderQuery) Page(orders []*Order) []*Order {
+	if q.Offset > 0 {
+		orders = orders[min(q.Offset, len(orders)):]
+	}
+	if q.Limit > 0 && len(orders) > q.Limit {
+		orders = orders[:q.Limit]
+	}
+	return orders
+}
+
+// inTimeRange reports whether t is within [from, to], zero bounds are open
+func inTimeRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || !t.After(to))
+}
@@ -16,26 +16,53 @@ type Storage interface {
 
 	// Orders retrieves orders based on provided filters
 	Orders(ctx context.Context, filters ...OrderFilter) ([]*Order, error)
+
+	// QueryOrders retrieves orders matching a structured query
+	QueryOrders(ctx context.Context, query OrderQuery) ([]*Order, error)
+
+	// CreateTrade stores a new trade
+	CreateTrade(ctx context.Context, trade *Trade) error
+
+	// Trades retrieves trades based on provided filters, ordered by closing time
+	Trades(ctx context.Context, filters ...TradeFilter) ([]*Trade, error)
+
+	// QueryTrades retrieves trades matching a structured query, ordered by closing time
+	QueryTrades(ctx context.Context, query TradeQuery) ([]*Trade, error)
+
+	CandleStorage
+}
+
+// CandleStorage defines the interface for candle storage operations
+type CandleStorage interface {
+	// SaveCandles stores candles of a timeframe, replacing those with the same pair and time
+	SaveCandles(ctx context.Context, timeframe string, candles []Candle) error
+
+	// Candles retrieves the candles of a pair and timeframe within [start, end], ordered by time
+	Candles(ctx context.Context, pair, timeframe string, start, end time.Time) ([]Candle, error)
 }
 
+// WithStatusIn filters orders with one of the statuses, in memory. Prefer OrderQuery.Status in storage queries
 func WithStatusIn(status ...OrderStatusType) OrderFilter {
 	return func(order Order) bool {
 		return slices.Contains(status, order.Status)
 	}
 }
 
+// WithStatus filters orders with a status, in memory. Prefer OrderQuery.Status in storage queries
 func WithStatus(status OrderStatusType) OrderFilter {
 	return func(order Order) bool {
 		return order.Status == status
 	}
 }
 
+// WithPair filters orders of a pair, in memory. Prefer OrderQuery.Pair in storage queries
 func WithPair(pair string) OrderFilter {
 	return func(order Order) bool {
 		return order.Pair == pair
 	}
 }
 
+// WithUpdateAtBeforeOrEqual filters orders updated up to a time, in memory. Prefer OrderQuery.UpdatedTo in storage queries
 func WithUpdateAtBeforeOrEqual(time time.Time) OrderFilter {
 	return func(order Order) bool {
 		return !order.UpdatedAt.After(time)
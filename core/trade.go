@@ -0,0 +1,96 @@
+package core
+
+import (
+	"fmt"
+	"time"
+)
+
+// TradeFilter defines a function type for filtering trades
+type TradeFilter func(trade Trade) bool
+
+// Trade represents a closed or reduced position, linking the orders that formed it
+type Trade struct {
+	ID            int64         `db:"id" json:"id" gorm:"primaryKey,autoIncrement"`
+	Strategy      string        `db:"strategy" json:"strategy,omitempty"`
+	Pair          string        `db:"pair" json:"pair" gorm:"index"`
+	Side          SideType      `db:"side" json:"side"`
+	EntryOrderID  int64         `db:"entry_order_id" json:"entry_order_id"` // Order that opened the position
+	ExitOrderID   int64         `db:"exit_order_id" json:"exit_order_id"`   // Order that closed or reduced the position
+	EntryPrice    float64       `db:"entry_price" json:"entry_price"`       // Average price of the position
+	ExitPrice     float64       `db:"exit_price" json:"exit_price"`
+	Quantity      float64       `db:"quantity" json:"quantity"`
+	Profit        float64       `db:"profit" json:"profit"` // Realized profit in quote currency
+	ProfitPercent float64       `db:"profit_percent" json:"profit_percent"`
+	Fee           float64       `db:"fee" json:"fee"` // Entry and exit order fees of the traded quantity, in quote currency
+	Duration      time.Duration `db:"duration" json:"duration"`
+	OpenedAt      time.Time     `db:"opened_at" json:"opened_at"`
+	ClosedAt      time.Time     `db:"closed_at" json:"closed_at" gorm:"index"`
+}
+
+// String returns a human-readable representation of the trade
+func (t Trade) String() string {
+	return fmt.Sprintf("[%s] %s %s | ID: %d, Entry: %f, Exit: %f, Quantity: %f, Profit: %f",
+		t.Pair, t.Side, t.Strategy, t.ID, t.EntryPrice, t.ExitPrice, t.Quantity, t.Profit)
+}
+
+// TradeQuery is a structured trade query that storages translate into their own queries.
+// All conditions must match, zero values match every trade. Filters are applied in memory
+// after the structured conditions.
+type TradeQuery struct {
+	Pair       string
+	Strategy   string
+	ClosedFrom time.Time // Inclusive lower bound of the closing time
+	ClosedTo   time.Time // Inclusive upper bound of the closing time
+
+	Filters []TradeFilter
+}
+
+// MatchConditions reports whether a trade matches the structured conditions of the query
+func (q TradeQuery) MatchConditions(trade Trade) bool {
+	switch {
+	case q.Pair != "" && trade.Pair != q.Pair:
+		return false
+	case q.Strategy != "" && trade.Strategy != q.Strategy:
+		return false
+	case !inTimeRange(trade.ClosedAt, q.ClosedFrom, q.ClosedTo):
+		return false
+	}
+	return true
+}
+
+// MatchFilters reports whether a trade passes the in-memory filters of the query
+func (q TradeQuery) MatchFilters(trade Trade) bool {
+	for _, filter := range q.Filters {
+		if !filter(trade) {
+			return false
+		}
+	}
+	return true
+}
+
+// Match reports whether a trade matches the conditions and filters of the query
+func (q TradeQuery) Match(trade Trade) bool {
+	return q.MatchConditions(trade) && q.MatchFilters(trade)
+}
+
+// WithTradePair filters trades of a pair, in memory. Prefer TradeQuery.Pair in storage queries
+func WithTradePair(pair string) TradeFilter {
+	return func(trade Trade) bool {
+		return trade.Pair == pair
+	}
+}
+
+// WithTradeStrategy filters trades of a strategy, in memory. Prefer TradeQuery.Strategy in storage queries
+func WithTradeStrategy(strategy string) TradeFilter {
+	return func(trade Trade) bool {
+		return trade.Strategy == strategy
+	}
+}
+
+// WithTradeClosedBetween filters trades closed in the range [start, end], in memory.
+// Prefer TradeQuery.ClosedFrom and TradeQuery.ClosedTo in storage queries
+func WithTradeClosedBetween(start, end time.Time) TradeFilter {
+	return func(trade Trade) bool {
+		return !trade.ClosedAt.Before(start) && !trade.ClosedAt.After(end)
+	}
+}
@@ -0,0 +1,217 @@
+package exchange
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/xhit/go-str2duration/v2"
+)
+
+// cacheBatchSize is the maximum number of candles fetched per request to the underlying feeder
+const cacheBatchSize = 500
+
+// CachedFeed is a core.Feeder serving candles from a candle storage. Closed candles missing
+// in the storage are fetched from the underlying feeder and stored, while the candle still
+// open is always fetched. Timeframes that can't be parsed as durations are not cached.
+// Closed candles the feeder didn't return, such as those before a pair was listed, are
+// remembered in memory and not fetched again, except for the last closed candle.
+type CachedFeed struct {
+	core.Feeder
+	storage core.CandleStorage
+	clock   func() time.Time
+
+	mu    sync.Mutex
+	empty map[string][]timeRange // ranges fetched without candles, by pair and timeframe
+}
+
+// timeRange is a closed range of candle times
+type timeRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// NewCachedFeed creates a feeder caching the candles of another feeder in a storage
+func NewCachedFeed(feeder core.Feeder, storage core.CandleStorage) *CachedFeed {
+	return &CachedFeed{
+		Feeder:  feeder,
+		storage: storage,
+		clock:   time.Now,
+		empty:   make(map[string][]timeRange),
+	}
+}
+
+// CandlesByPeriod gets candles for a pair within a time range, from the storage first
+func (c *CachedFeed) CandlesByPeriod(ctx context.Context, pair, period string,
+	start, end time.Time) ([]core.Candle, error) {
+
+	interval, err := str2duration.ParseDuration(period)
+	if err != nil || interval <= 0 {
+		return c.Feeder.CandlesByPeriod(ctx, pair, period, start, end)
+	}
+
+	// Only closed candles are cached
+	closedEnd := end
+	if lastClosed := c.lastClosed(interval); lastClosed.Before(closedEnd) {
+		closedEnd = lastClosed
+	}
+
+	candles := make([]core.Candle, 0)
+	recentStart := start
+	if !closedEnd.Before(start) {
+		if err := c.fill(ctx, pair, period, interval, start, closedEnd); err != nil {
+			return nil, err
+		}
+
+		candles, err = c.storage.Candles(ctx, pair, period, start, closedEnd)
+		if err != nil {
+			return nil, err
+		}
+		recentStart = closedEnd.Add(interval)
+	}
+
+	if recentStart.After(end) {
+		return candles, nil
+	}
+
+	recent, err := c.Feeder.CandlesByPeriod(ctx, pair, period, recentStart, end)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, candle := range recent {
+		if candle.Time.After(closedEnd) {
+			candles = append(candles, candle)
+		}
+	}
+	return candles, nil
+}
+
+// CandlesByLimit gets the last closed candles for a pair, from the storage first
+func (c *CachedFeed) CandlesByLimit(ctx context.Context, pair, period string, limit int) ([]core.Candle, error) {
+	interval, err := str2duration.ParseDuration(period)
+	if err != nil || interval <= 0 || limit <= 0 {
+		return c.Feeder.CandlesByLimit(ctx, pair, period, limit)
+	}
+
+	end := c.lastClosed(interval)
+	start := end.Add(-time.Duration(limit-1) * interval)
+
+	candles, err := c.CandlesByPeriod(ctx, pair, period, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(candles) > limit {
+		candles = candles[len(candles)-limit:]
+	}
+	return candles, nil
+}
+
+// fill fetches the candles of a closed time range missing in the storage and stores them
+func (c *CachedFeed) fill(ctx context.Context, pair, period string, interval time.Duration,
+	start, end time.Time) error {
+
+	stored, err := c.storage.Candles(ctx, pair, period, start, end)
+	if err != nil {
+		return err
+	}
+
+	key := pair + "--" + period
+	for _, missing := range missingRanges(stored, c.emptyRanges(key), start, end, interval) {
+		for batchStart := missing.start; !batchStart.After(missing.end); batchStart = batchStart.Add(cacheBatchSize * interval) {
+			batchEnd := batchStart.Add((cacheBatchSize - 1) * interval)
+			if batchEnd.After(missing.end) {
+				batchEnd = missing.end
+			}
+
+			candles, err := c.Feeder.CandlesByPeriod(ctx, pair, period, batchStart, batchEnd)
+			if err != nil {
+				return err
+			}
+
+			// Keep only the requested candles, exchanges may return the next open candle
+			closed := make([]core.Candle, 0, len(candles))
+			for _, candle := range candles {
+				if !candle.Time.Before(batchStart) && !candle.Time.After(batchEnd) {
+					closed = append(closed, candle)
+				}
+			}
+
+			if err := c.storage.SaveCandles(ctx, period, closed); err != nil {
+				return err
+			}
+
+			// The last closed candle may not be available yet, it is fetched again
+			if lastClosed := c.lastClosed(interval); !batchEnd.Before(lastClosed) {
+				batchEnd = lastClosed.Add(-interval)
+			}
+			c.addEmptyRanges(key, missingRanges(closed, nil, batchStart, batchEnd, interval))
+		}
+	}
+
+	return nil
+}
+
+// emptyRanges returns the ranges of a pair and timeframe fetched without candles
+func (c *CachedFeed) emptyRanges(key string) []timeRange {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]timeRange(nil), c.empty[key]...)
+}
+
+// addEmptyRanges remembers ranges of a pair and timeframe fetched without candles
+func (c *CachedFeed) addEmptyRanges(key string, ranges []timeRange) {
+	if len(ranges) == 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.empty[key] = append(c.empty[key], ranges...)
+}
+
+// lastClosed returns the open time of the last closed candle of a timeframe
+func (c *CachedFeed) lastClosed(interval time.Duration) time.Time {
+	return c.clock().Truncate(interval).Add(-interval)
+}
+
+// missingRanges returns the ranges of candle times within [start, end] absent from the stored
+// candles and not covered by the skipped ranges
+func missingRanges(stored []core.Candle, skip []timeRange, start, end time.Time, interval time.Duration) []timeRange {
+	times := make(map[int64]struct{}, len(stored))
+	for _, candle := range stored {
+		times[candle.Time.UnixNano()] = struct{}{}
+	}
+
+	first := start.Truncate(interval)
+	if first.Before(start) {
+		first = first.Add(interval)
+	}
+
+	var ranges []timeRange
+	for t := first; !t.After(end); t = t.Add(interval) {
+		if _, ok := times[t.UnixNano()]; ok || inRanges(skip, t) {
+			continue
+		}
+
+		if last := len(ranges) - 1; last >= 0 && ranges[last].end.Add(interval).Equal(t) {
+			ranges[last].end = t
+			continue
+		}
+		ranges = append(ranges, timeRange{start: t, end: t})
+	}
+
+	return ranges
+}
+
+// inRanges reports whether a time is within one of the ranges
+func inRanges(ranges []timeRange, t time.Time) bool {
+	for _, r := range ranges {
+		if !t.Before(r.start) && !t.After(r.end) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFeeder generates an hourly candle for every hour not missing, recording the requested periods
+type fakeFeeder struct {
+	core.Feeder
+	requests [][2]time.Time
+	missing  func(t time.Time) bool
+}
+
+func (f *fakeFeeder) CandlesByPeriod(_ context.Context, pair, _ string, start, end time.Time) ([]core.Candle, error) {
+	f.requests = append(f.requests, [2]time.Time{start, end})
+
+	var candles []core.Candle
+	for t := start.Truncate(time.Hour); !t.After(end); t = t.Add(time.Hour) {
+		if t.Before(start) || (f.missing != nil && f.missing(t)) {
+			continue
+		}
+		candles = append(candles, core.Candle{Pair: pair, Time: t, UpdatedAt: t, Close: float64(t.Hour()), Complete: true})
+	}
+	return candles, nil
+}
+
+func TestCachedFeed(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC)
+	hour := func(h int) time.Time { return time.Date(2024, 1, 2, h, 0, 0, 0, time.UTC) }
+
+	db, err := storage.FromMemory()
+	require.NoError(t, err)
+
+	feeder := &fakeFeeder{}
+	feed := NewCachedFeed(feeder, db)
+	feed.clock = func() time.Time { return now }
+
+	t.Run("fetch missing candles", func(t *testing.T) {
+		candles, err := feed.CandlesByPeriod(ctx, "BTCUSDT", "1h", hour(2), hour(5))
+		require.NoError(t, err)
+		require.Len(t, candles, 4)
+		assert.Equal(t, hour(2), candles[0].Time)
+		assert.Equal(t, hour(5), candles[3].Time)
+		assert.Equal(t, [][2]time.Time{{hour(2), hour(5)}}, feeder.requests)
+	})
+
+	t.Run("serve stored candles", func(t *testing.T) {
+		feeder.requests = nil
+		candles, err := feed.CandlesByPeriod(ctx, "BTCUSDT", "1h", hour(3), hour(4))
+		require.NoError(t, err)
+		require.Len(t, candles, 2)
+		assert.Empty(t, feeder.requests)
+	})
+
+	t.Run("fetch only missing ranges", func(t *testing.T) {
+		feeder.requests = nil
+		candles, err := feed.CandlesByPeriod(ctx, "BTCUSDT", "1h", hour(0), hour(7))
+		require.NoError(t, err)
+		require.Len(t, candles, 8)
+		assert.Equal(t, [][2]time.Time{{hour(0), hour(1)}, {hour(6), hour(7)}}, feeder.requests)
+	})
+
+	t.Run("open candle is not stored", func(t *testing.T) {
+		feeder.requests = nil
+		candles, err := feed.CandlesByPeriod(ctx, "BTCUSDT", "1h", hour(8), now)
+		require.NoError(t, err)
+		require.Len(t, candles, 3)
+		assert.Equal(t, hour(10), candles[2].Time)
+		assert.Equal(t, [][2]time.Time{{hour(8), hour(9)}, {hour(10), now}}, feeder.requests)
+
+		stored, err := db.Candles(ctx, "BTCUSDT", "1h", hour(0), now)
+		require.NoError(t, err)
+		assert.Len(t, stored, 10)
+	})
+
+	t.Run("candles by limit", func(t *testing.T) {
+		feeder.requests = nil
+		candles, err := feed.CandlesByLimit(ctx, "BTCUSDT", "1h", 5)
+		require.NoError(t, err)
+		require.Len(t, candles, 5)
+		assert.Equal(t, hour(5), candles[0].Time)
+		assert.Equal(t, hour(9), candles[4].Time)
+		assert.Empty(t, feeder.requests)
+	})
+
+	t.Run("remember ranges without candles", func(t *testing.T) {
+		// the pair is listed at 3h and the last closed candle is not available yet
+		feeder.requests = nil
+		feeder.missing = func(t time.Time) bool { return t.Before(hour(3)) || t.Equal(hour(9)) }
+		defer func() { feeder.missing = nil }()
+
+		candles, err := feed.CandlesByPeriod(ctx, "ETHUSDT", "1h", hour(0), hour(9))
+		require.NoError(t, err)
+		require.Len(t, candles, 6)
+		assert.Equal(t, hour(3), candles[0].Time)
+		assert.Equal(t, [][2]time.Time{{hour(0), hour(9)}}, feeder.requests)
+
+		// only the last closed candle is fetched again
+		feeder.requests = nil
+		candles, err = feed.CandlesByPeriod(ctx, "ETHUSDT", "1h", hour(0), hour(9))
+		require.NoError(t, err)
+		require.Len(t, candles, 6)
+		assert.Equal(t, [][2]time.Time{{hour(9), hour(9)}}, feeder.requests)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"math"
 	"os"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/raykavin/backnrun/core"
@@ -24,6 +25,9 @@ var (
 	// ErrInsufficientData is returned when there is not enough data to fulfill a request
 	ErrInsufficientData = errors.New("insufficient data")
 
+	// ErrInvalidTimeframe is returned when candles cannot be resampled to the requested timeframe
+	ErrInvalidTimeframe = errors.New("invalid timeframe")
+
 	// defaultHeaderMap defines the standard CSV column mapping
 	defaultHeaderMap = map[string]int{
 		"time": 0, "open": 1, "close": 2, "low": 3, "high": 4, "volume": 5,
@@ -46,6 +50,9 @@ type PairFeed struct {
 type CSVFeed struct {
 	Feeds               map[string]PairFeed
 	CandlePairTimeFrame map[string][]core.Candle
+
+	// mu guards CandlePairTimeFrame, shared by the backtests of parallel optimizations
+	mu sync.RWMutex
 }
 
 // PeriodBoundaryCheck defines an interface for checking period boundaries
@@ -270,8 +277,46 @@ func isTimeOnPeriodBoundary(t time.Time, targetTimeframe string) (bool, error) {
 // Resampling
 // ---------------------
 
+// Resample builds the candles of a pair in the given timeframe from the source CSV candles.
+// It is called on demand when a timeframe other than the target one is requested, e.g.
+// informative timeframes of a strategy. The timeframe must be a multiple of the source timeframe.
+func (c *CSVFeed) Resample(pair, timeframe string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.CandlePairTimeFrame[c.feedTimeframeKey(pair, timeframe)]; ok {
+		return nil
+	}
+
+	feed, ok := c.Feeds[pair]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInsufficientData, pair)
+	}
+
+	sourceDuration, err := str2duration.ParseDuration(feed.Timeframe)
+	if err != nil {
+		return err
+	}
+
+	targetDuration, err := str2duration.ParseDuration(timeframe)
+	if err != nil {
+		return err
+	}
+
+	if targetDuration < sourceDuration {
+		return fmt.Errorf("%w: cannot resample %s %s candles to %s", ErrInvalidTimeframe, pair, feed.Timeframe, timeframe)
+	}
+
+	return c.resample(pair, feed.Timeframe, timeframe)
+}
+
 // resample resamples candles from source timeframe to target timeframe
 func (c *CSVFeed) resample(pair, sourceTimeframe, targetTimeframe string) error {
+	// Source candles are already stored in their own timeframe
+	if sourceTimeframe == targetTimeframe {
+		return nil
+	}
+
 	sourceKey := c.feedTimeframeKey(pair, sourceTimeframe)
 	targetKey := c.feedTimeframeKey(pair, targetTimeframe)
 
@@ -363,12 +408,15 @@ func (c *CSVFeed) resampleCandles(sourceCandles []core.Candle, sourceTimeframe,
 // ---------------------
 
 // feedTimeframeKey generates a unique key for each pair and timeframe
-func (c CSVFeed) feedTimeframeKey(pair, timeframe string) string {
+func (c *CSVFeed) feedTimeframeKey(pair, timeframe string) string {
 	return fmt.Sprintf("%s--%s", pair, timeframe)
 }
 
 // Limit limits candles to a specific time duration
 func (c *CSVFeed) Limit(duration time.Duration) *CSVFeed {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	for pair, candles := range c.CandlePairTimeFrame {
 		if len(candles) == 0 {
 			continue
@@ -385,12 +433,64 @@ func (c *CSVFeed) Limit(duration time.Duration) *CSVFeed {
 	return c
 }
 
+// Period returns the time of the first and last candles loaded in the feed
+func (c *CSVFeed) Period() (start, end time.Time) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, candles := range c.CandlePairTimeFrame {
+		if len(candles) == 0 {
+			continue
+		}
+
+		if first := candles[0].Time; start.IsZero() || first.Before(start) {
+			start = first
+		}
+		if last := candles[len(candles)-1].Time; last.After(end) {
+			end = last
+		}
+	}
+	return start, end
+}
+
+// Candles returns the candles loaded for a pair and timeframe. The feed replaces its
+// candle slices instead of modifying them, so they can be read without the lock
+func (c *CSVFeed) Candles(pair, timeframe string) []core.Candle {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.CandlePairTimeFrame[c.feedTimeframeKey(pair, timeframe)]
+}
+
+// Slice returns a new feed with the candles opened in the [start, end) interval,
+// leaving the original feed untouched
+func (c *CSVFeed) Slice(start, end time.Time) *CSVFeed {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	feed := &CSVFeed{
+		Feeds:               make(map[string]PairFeed, len(c.Feeds)),
+		CandlePairTimeFrame: make(map[string][]core.Candle, len(c.CandlePairTimeFrame)),
+	}
+
+	for pair, pairFeed := range c.Feeds {
+		feed.Feeds[pair] = pairFeed
+	}
+
+	for key, candles := range c.CandlePairTimeFrame {
+		feed.CandlePairTimeFrame[key] = lo.Filter(candles, func(candle core.Candle, _ int) bool {
+			return !candle.Time.Before(start) && candle.Time.Before(end)
+		})
+	}
+
+	return feed
+}
+
 // ---------------------
 // API Methods
 // ---------------------
 
 // AssetsInfo returns information about a trading pair's assets
-func (c CSVFeed) AssetsInfo(pair string) (core.AssetInfo, error) {
+func (c *CSVFeed) AssetsInfo(pair string) (core.AssetInfo, error) {
 	asset, quote := SplitAssetQuote(pair)
 	return core.NewAssetInfo(
 		asset,
@@ -407,12 +507,15 @@ func (c CSVFeed) AssetsInfo(pair string) (core.AssetInfo, error) {
 }
 
 // LastQuote returns the last quote (not implemented for CSVFeed)
-func (c CSVFeed) LastQuote(_ context.Context, _ string) (float64, error) {
+func (c *CSVFeed) LastQuote(_ context.Context, _ string) (float64, error) {
 	return 0, errors.New("invalid operation")
 }
 
 // CandlesByPeriod returns candles within a specific time period
-func (c CSVFeed) CandlesByPeriod(_ context.Context, pair, timeframe string, start, end time.Time) ([]core.Candle, error) {
+func (c *CSVFeed) CandlesByPeriod(_ context.Context, pair, timeframe string, start, end time.Time) ([]core.Candle, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	key := c.feedTimeframeKey(pair, timeframe)
 	result := make([]core.Candle, 0)
 
@@ -429,6 +532,9 @@ func (c CSVFeed) CandlesByPeriod(_ context.Context, pair, timeframe string, star
 
 // CandlesByLimit returns a limited number of candles and removes them from the feed
 func (c *CSVFeed) CandlesByLimit(_ context.Context, pair, timeframe string, limit int) ([]core.Candle, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
 	key := c.feedTimeframeKey(pair, timeframe)
 
 	if len(c.CandlePairTimeFrame[key]) < limit {
@@ -442,18 +548,28 @@ func (c *CSVFeed) CandlesByLimit(_ context.Context, pair, timeframe string, limi
 	return result, nil
 }
 
-// CandlesSubscription returns a channel to receive candles
-func (c CSVFeed) CandlesSubscription(_ context.Context, pair, timeframe string) (chan core.Candle, chan error) {
+// CandlesSubscription returns a channel to receive candles.
+// Timeframes not loaded yet are resampled from the source candles of the pair.
+func (c *CSVFeed) CandlesSubscription(_ context.Context, pair, timeframe string) (chan core.Candle, chan error) {
 	ccandle := make(chan core.Candle)
 	cerr := make(chan error)
-	key := c.feedTimeframeKey(pair, timeframe)
+
+	resampleErr := c.Resample(pair, timeframe)
+	c.mu.RLock()
+	candles := c.CandlePairTimeFrame[c.feedTimeframeKey(pair, timeframe)]
+	c.mu.RUnlock()
 
 	go func() {
 		defer close(ccandle)
 		defer close(cerr)
 
+		if resampleErr != nil {
+			cerr <- resampleErr
+			return
+		}
+
 		// Send all candles through the channel
-		for _, candle := range c.CandlePairTimeFrame[key] {
+		for _, candle := range candles {
 			ccandle <- candle
 		}
 	}()
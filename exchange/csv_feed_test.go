@@ -3,9 +3,14 @@ package exchange
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/raykavin/backnrun/core"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -129,6 +134,95 @@ func TestCSVFeed_resample(t *testing.T) {
 	})
 }
 
+func TestCSVFeed_Resample(t *testing.T) {
+	// 12 hourly candles starting at midnight
+	start := time.Date(2021, 5, 13, 0, 0, 0, 0, time.UTC)
+	lines := make([]string, 0, 12)
+	for i := 0; i < 12; i++ {
+		lines = append(lines, fmt.Sprintf("%d,%d,%d,%d,%d,1", start.Add(time.Duration(i)*time.Hour).Unix(),
+			100+i, 101+i, 99+i, 102+i))
+	}
+
+	file := filepath.Join(t.TempDir(), "btc-1h.csv")
+	require.NoError(t, os.WriteFile(file, []byte(strings.Join(lines, "\n")), 0o600))
+
+	feed, err := NewCSVFeed("1h", PairFeed{Timeframe: "1h", Pair: "BTCUSDT", File: file})
+	require.NoError(t, err)
+
+	t.Run("on subscription", func(t *testing.T) {
+		candles, _ := feed.CandlesSubscription(context.Background(), "BTCUSDT", "4h")
+
+		received := make([]time.Time, 0)
+		for candle := range candles {
+			received = append(received, candle.Time)
+		}
+
+		require.Len(t, received, 3)
+		require.Equal(t, start, received[0])
+		require.Equal(t, start.Add(4*time.Hour), received[1])
+
+		candle := feed.CandlePairTimeFrame["BTCUSDT--4h"][0]
+		require.Equal(t, 100.0, candle.Open)
+		require.Equal(t, 104.0, candle.Close)
+		require.Equal(t, 99.0, candle.Low)
+		require.Equal(t, 105.0, candle.High)
+		require.Equal(t, 4.0, candle.Volume)
+	})
+
+	t.Run("concurrent subscriptions", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for _, timeframe := range []string{"2h", "12h", "2h", "12h"} {
+			wg.Add(1)
+			go func(timeframe string) {
+				defer wg.Done()
+				candles, _ := feed.CandlesSubscription(context.Background(), "BTCUSDT", timeframe)
+				for range candles {
+				}
+			}(timeframe)
+		}
+		wg.Wait()
+
+		require.Len(t, feed.CandlePairTimeFrame["BTCUSDT--2h"], 6)
+		require.Len(t, feed.CandlePairTimeFrame["BTCUSDT--12h"], 1)
+	})
+
+	t.Run("lower timeframe", func(t *testing.T) {
+		err := feed.Resample("BTCUSDT", "15m")
+		require.ErrorIs(t, err, ErrInvalidTimeframe)
+	})
+
+	t.Run("unknown pair", func(t *testing.T) {
+		err := feed.Resample("ETHUSDT", "4h")
+		require.ErrorIs(t, err, ErrInsufficientData)
+	})
+}
+
+func TestCSVFeed_Slice(t *testing.T) {
+	start := time.Date(2021, 5, 13, 0, 0, 0, 0, time.UTC)
+	candles := make([]core.Candle, 0, 6)
+	for i := 0; i < 6; i++ {
+		candles = append(candles, core.Candle{Pair: "BTCUSDT", Time: start.Add(time.Duration(i) * time.Hour)})
+	}
+
+	feed := &CSVFeed{
+		Feeds:               map[string]PairFeed{"BTCUSDT": {Pair: "BTCUSDT", Timeframe: "1h"}},
+		CandlePairTimeFrame: map[string][]core.Candle{"BTCUSDT--1h": candles},
+	}
+
+	first, last := feed.Period()
+	require.Equal(t, start, first)
+	require.Equal(t, start.Add(5*time.Hour), last)
+
+	slice := feed.Slice(start.Add(time.Hour), start.Add(3*time.Hour))
+	require.Len(t, slice.CandlePairTimeFrame["BTCUSDT--1h"], 2)
+	require.Equal(t, start.Add(time.Hour), slice.CandlePairTimeFrame["BTCUSDT--1h"][0].Time)
+	require.Equal(t, feed.Feeds, slice.Feeds)
+	require.Equal(t, slice.CandlePairTimeFrame["BTCUSDT--1h"], slice.Candles("BTCUSDT", "1h"))
+
+	// the original feed is untouched
+	require.Len(t, feed.CandlePairTimeFrame["BTCUSDT--1h"], 6)
+}
+
 func TestIsLastCandlePeriod(t *testing.T) {
 	t.Run("valid", func(t *testing.T) {
 		tt := []struct {
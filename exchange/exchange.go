@@ -22,6 +22,7 @@ var (
 	ErrInvalidQuantity   = errors.New("invalid quantity")
 	ErrInsufficientFunds = errors.New("insufficient funds or locked")
 	ErrInvalidAsset      = errors.New("invalid asset")
+	ErrOrderRejected     = errors.New("order rejected")
 )
 
 // ---------------------
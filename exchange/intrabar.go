@@ -0,0 +1,160 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/xhit/go-str2duration/v2"
+)
+
+// lowerTimeframeWindow is the number of candles of lower timeframe data loaded at once
+const lowerTimeframeWindow = 100
+
+// IntrabarModel describes the prices a candle travels through, which decides the order
+// and the price of the fills inside the candle
+type IntrabarModel interface {
+	// Path returns the prices reached by the candle in order for the orders of a side,
+	// starting at the open and ending at the close
+	Path(side core.SideType, candle core.Candle) []float64
+}
+
+// IntrabarFunc adapts a function to the IntrabarModel interface
+type IntrabarFunc func(side core.SideType, candle core.Candle) []float64
+
+// Path calls the function
+func (f IntrabarFunc) Path(side core.SideType, candle core.Candle) []float64 {
+	return f(side, candle)
+}
+
+var (
+	// IntrabarOHLC assumes the price reaches the high before the low
+	IntrabarOHLC IntrabarModel = IntrabarFunc(func(_ core.SideType, candle core.Candle) []float64 {
+		return []float64{candle.Open, candle.High, candle.Low, candle.Close}
+	})
+
+	// IntrabarOLHC assumes the price reaches the low before the high
+	IntrabarOLHC IntrabarModel = IntrabarFunc(func(_ core.SideType, candle core.Candle) []float64 {
+		return []float64{candle.Open, candle.Low, candle.High, candle.Close}
+	})
+
+	// IntrabarWorstCase assumes the price moves against the orders of each side first:
+	// sell orders see the low before the high, so a long stop fills before its take profit,
+	// and buy orders see the high before the low
+	IntrabarWorstCase IntrabarModel = IntrabarFunc(func(side core.SideType, candle core.Candle) []float64 {
+		if side == core.SideTypeSell {
+			return IntrabarOLHC.Path(side, candle)
+		}
+		return IntrabarOHLC.Path(side, candle)
+	})
+)
+
+// FeedIntrabar replays the candles of a lower timeframe feed inside each candle
+type FeedIntrabar struct {
+	*lowerTimeframeFeed
+	fallback IntrabarModel
+}
+
+// NewFeedIntrabar creates a model replaying the timeframe candles of a feed inside the candles
+// of candleTimeframe, e.g. 1m candles inside a 1h backtest. The path inside each lower timeframe
+// candle, and of candles without lower timeframe data, follows the fallback model.
+func NewFeedIntrabar(feeder core.Feeder, timeframe, candleTimeframe string, fallback IntrabarModel) (*FeedIntrabar, error) {
+	feed, err := newLowerTimeframeFeed(feeder, timeframe, candleTimeframe)
+	if err != nil {
+		return nil, err
+	}
+	return &FeedIntrabar{lowerTimeframeFeed: feed, fallback: fallback}, nil
+}
+
+// Path returns the prices of the lower timeframe candles inside the candle
+func (f *FeedIntrabar) Path(side core.SideType, candle core.Candle) []float64 {
+	candles := f.candles(candle.Pair, candle.Time, candle.Time.Add(f.period))
+	if len(candles) == 0 {
+		return f.fallback.Path(side, candle)
+	}
+
+	path := make([]float64, 0, 4*len(candles))
+	for _, c := range candles {
+		path = append(path, f.fallback.Path(side, c)...)
+	}
+	return path
+}
+
+// candleWindow holds the lower timeframe candles of a pair loaded from the feed
+type candleWindow struct {
+	start   time.Time
+	end     time.Time
+	candles []core.Candle
+}
+
+// lowerTimeframeFeed loads the candles of a lower timeframe feed inside the candles of a backtest
+type lowerTimeframeFeed struct {
+	mu sync.Mutex
+
+	feeder    core.Feeder
+	timeframe string
+	period    time.Duration
+	windows   map[string]candleWindow
+	err       error // first error loading a window
+}
+
+// newLowerTimeframeFeed creates a loader of the timeframe candles of a feed inside the candles of candleTimeframe
+func newLowerTimeframeFeed(feeder core.Feeder, timeframe, candleTimeframe string) (*lowerTimeframeFeed, error) {
+	interval, err := str2duration.ParseDuration(timeframe)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTimeframe, timeframe)
+	}
+
+	period, err := str2duration.ParseDuration(candleTimeframe)
+	if err != nil || period <= interval {
+		return nil, fmt.Errorf("%w: %s is not above %s", ErrInvalidTimeframe, candleTimeframe, timeframe)
+	}
+
+	return &lowerTimeframeFeed{
+		feeder:    feeder,
+		timeframe: timeframe,
+		period:    period,
+		windows:   make(map[string]candleWindow),
+	}, nil
+}
+
+// Err returns the first error the feed returned while loading lower timeframe candles.
+// Candles of windows that failed to load follow the fallback of the model.
+func (f *lowerTimeframeFeed) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
+
+// candles returns the lower timeframe candles of a pair between start and end,
+// loading the next window of the feed when needed. Windows that fail to load
+// are kept without candles, so the feed is not requested again for each candle.
+func (f *lowerTimeframeFeed) candles(pair string, start, end time.Time) []core.Candle {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	window, ok := f.windows[pair]
+	if !ok || start.Before(window.start) || end.After(window.end) {
+		window = candleWindow{start: start, end: start.Add(lowerTimeframeWindow * f.period)}
+		candles, err := f.feeder.CandlesByPeriod(context.Background(), pair, f.timeframe, window.start, window.end)
+		if err != nil {
+			if f.err == nil {
+				f.err = fmt.Errorf("loading %s %s candles from %s: %w", pair, f.timeframe, window.start, err)
+			}
+			candles = nil
+		}
+		window.candles = candles
+		f.windows[pair] = window
+	}
+
+	from := sort.Search(len(window.candles), func(i int) bool {
+		return !window.candles[i].Time.Before(start)
+	})
+	to := sort.Search(len(window.candles), func(i int) bool {
+		return !window.candles[i].Time.Before(end)
+	})
+	return window.candles[from:to]
+}
@@ -0,0 +1,99 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+
+	"github.com/stretchr/testify/require"
+)
+
+// listFeeder serves a fixed list of candles, or an error, counting the requests
+type listFeeder struct {
+	core.Feeder
+	candles  []core.Candle
+	err      error
+	requests int
+}
+
+func (f *listFeeder) CandlesByPeriod(_ context.Context, pair, _ string, start, end time.Time) ([]core.Candle, error) {
+	f.requests++
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	var candles []core.Candle
+	for _, candle := range f.candles {
+		if candle.Pair == pair && !candle.Time.Before(start) && !candle.Time.After(end) {
+			candles = append(candles, candle)
+		}
+	}
+	return candles, nil
+}
+
+func TestIntrabarModels(t *testing.T) {
+	candle := core.Candle{Open: 100, High: 110, Low: 90, Close: 105}
+
+	require.Equal(t, []float64{100, 110, 90, 105}, IntrabarOHLC.Path(core.SideTypeBuy, candle))
+	require.Equal(t, []float64{100, 90, 110, 105}, IntrabarOLHC.Path(core.SideTypeBuy, candle))
+	require.Equal(t, []float64{100, 110, 90, 105}, IntrabarWorstCase.Path(core.SideTypeBuy, candle))
+	require.Equal(t, []float64{100, 90, 110, 105}, IntrabarWorstCase.Path(core.SideTypeSell, candle))
+}
+
+func TestFeedIntrabar(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	minute := func(m int) time.Time { return start.Add(time.Duration(m) * time.Minute) }
+
+	feeder := &listFeeder{candles: []core.Candle{
+		{Pair: "BTCUSDT", Time: minute(0), Open: 100, High: 101, Low: 99, Close: 100},
+		{Pair: "BTCUSDT", Time: minute(30), Open: 100, High: 102, Low: 98, Close: 101},
+		{Pair: "BTCUSDT", Time: minute(60), Open: 101, High: 103, Low: 100, Close: 102},
+	}}
+
+	t.Run("invalid timeframes", func(t *testing.T) {
+		_, err := NewFeedIntrabar(feeder, "1x", "1h", IntrabarOHLC)
+		require.ErrorIs(t, err, ErrInvalidTimeframe)
+
+		_, err = NewFeedIntrabar(feeder, "1h", "30m", IntrabarOHLC)
+		require.ErrorIs(t, err, ErrInvalidTimeframe)
+	})
+
+	t.Run("replay lower timeframe candles", func(t *testing.T) {
+		model, err := NewFeedIntrabar(feeder, "30m", "1h", IntrabarOLHC)
+		require.NoError(t, err)
+
+		path := model.Path(core.SideTypeSell, core.Candle{Pair: "BTCUSDT", Time: start})
+		require.Equal(t, []float64{100, 99, 101, 100, 100, 98, 102, 101}, path)
+
+		path = model.Path(core.SideTypeSell, core.Candle{Pair: "BTCUSDT", Time: minute(60)})
+		require.Equal(t, []float64{101, 100, 103, 102}, path)
+		require.Equal(t, 1, feeder.requests)
+	})
+
+	t.Run("fallback without lower timeframe candles", func(t *testing.T) {
+		model, err := NewFeedIntrabar(feeder, "30m", "1h", IntrabarOHLC)
+		require.NoError(t, err)
+
+		candle := core.Candle{Pair: "ETHUSDT", Time: start, Open: 10, High: 12, Low: 9, Close: 11}
+		require.Equal(t, []float64{10, 12, 9, 11}, model.Path(core.SideTypeBuy, candle))
+		require.NoError(t, model.Err())
+	})
+
+	t.Run("feed errors", func(t *testing.T) {
+		errFeed := errors.New("feed unavailable")
+		failing := &listFeeder{err: errFeed}
+		model, err := NewFeedIntrabar(failing, "30m", "1h", IntrabarOHLC)
+		require.NoError(t, err)
+
+		candle := core.Candle{Pair: "BTCUSDT", Time: start, Open: 10, High: 12, Low: 9, Close: 11}
+		require.Equal(t, []float64{10, 12, 9, 11}, model.Path(core.SideTypeBuy, candle))
+		require.Equal(t, []float64{10, 12, 9, 11}, model.Path(core.SideTypeSell, candle))
+		require.ErrorIs(t, model.Err(), errFeed)
+
+		// the failed window is not requested again
+		require.Equal(t, 1, failing.requests)
+	})
+}
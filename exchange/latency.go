@@ -0,0 +1,58 @@
+package exchange
+
+import (
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+)
+
+// LatencyModel delays the execution of market orders after the signal candle closes
+type LatencyModel interface {
+	// Execution returns the time and the price at which a market order sent at sent executes
+	// inside the candle, or false when it executes after the candle
+	Execution(sent time.Time, candle core.Candle) (time.Time, float64, bool)
+}
+
+// LatencyFunc adapts a function to the LatencyModel interface
+type LatencyFunc func(sent time.Time, candle core.Candle) (time.Time, float64, bool)
+
+// Execution calls the function
+func (f LatencyFunc) Execution(sent time.Time, candle core.Candle) (time.Time, float64, bool) {
+	return f(sent, candle)
+}
+
+// NextOpenLatency executes market orders at the open of the candle after the signal
+var NextOpenLatency LatencyModel = LatencyFunc(func(_ time.Time, candle core.Candle) (time.Time, float64, bool) {
+	return candle.Time, candle.Open, true
+})
+
+// FeedLatency executes market orders at the open of the first lower timeframe candle after a delay
+type FeedLatency struct {
+	*lowerTimeframeFeed
+	latency time.Duration
+}
+
+// NewFeedLatency creates a model executing market orders latency after they are sent, at the open
+// of the timeframe candles of a feed inside the candles of candleTimeframe, e.g. 1m candles inside
+// a 1h backtest. Orders execute at the open of the candle when no lower timeframe candle follows the delay.
+func NewFeedLatency(latency time.Duration, feeder core.Feeder, timeframe, candleTimeframe string) (*FeedLatency, error) {
+	feed, err := newLowerTimeframeFeed(feeder, timeframe, candleTimeframe)
+	if err != nil {
+		return nil, err
+	}
+	return &FeedLatency{lowerTimeframeFeed: feed, latency: latency}, nil
+}
+
+// Execution returns the open of the first lower timeframe candle after the latency
+func (f *FeedLatency) Execution(sent time.Time, candle core.Candle) (time.Time, float64, bool) {
+	due := sent.Add(f.latency)
+	end := candle.Time.Add(f.period)
+	if !due.Before(end) {
+		return time.Time{}, 0, false
+	}
+
+	if candles := f.candles(candle.Pair, due, end); len(candles) > 0 {
+		return candles[0].Time, candles[0].Open, true
+	}
+	return candle.Time, candle.Open, true
+}
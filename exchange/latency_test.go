@@ -0,0 +1,60 @@
+package exchange
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencyModels(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candle := core.Candle{Pair: "BTCUSDT", Time: start, Open: 100, Close: 105}
+
+	t.Run("next open", func(t *testing.T) {
+		executedAt, price, ok := NextOpenLatency.Execution(start, candle)
+		require.True(t, ok)
+		require.Equal(t, start, executedAt)
+		require.Equal(t, 100.0, price)
+	})
+
+	t.Run("lower timeframe feed", func(t *testing.T) {
+		feeder := &listFeeder{candles: []core.Candle{
+			{Pair: "BTCUSDT", Time: start, Open: 100},
+			{Pair: "BTCUSDT", Time: start.Add(time.Minute), Open: 101},
+			{Pair: "BTCUSDT", Time: start.Add(2 * time.Minute), Open: 102},
+		}}
+
+		_, err := NewFeedLatency(time.Minute, feeder, "1h", "1m")
+		require.ErrorIs(t, err, ErrInvalidTimeframe)
+
+		model, err := NewFeedLatency(90*time.Second, feeder, "1m", "1h")
+		require.NoError(t, err)
+
+		executedAt, price, ok := model.Execution(start, candle)
+		require.True(t, ok)
+		require.Equal(t, start.Add(2*time.Minute), executedAt)
+		require.Equal(t, 102.0, price)
+
+		// without lower timeframe candles after the latency, orders execute at the open
+		executedAt, price, ok = model.Execution(start.Add(5*time.Minute), candle)
+		require.True(t, ok)
+		require.Equal(t, start, executedAt)
+		require.Equal(t, 100.0, price)
+	})
+
+	t.Run("latency beyond the candle", func(t *testing.T) {
+		model, err := NewFeedLatency(time.Hour, &listFeeder{}, "1m", "1h")
+		require.NoError(t, err)
+
+		_, _, ok := model.Execution(start, candle)
+		require.False(t, ok)
+
+		executedAt, price, ok := model.Execution(start, core.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), Open: 103})
+		require.True(t, ok)
+		require.Equal(t, start.Add(time.Hour), executedAt)
+		require.Equal(t, 103.0, price)
+	})
+}
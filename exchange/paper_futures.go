@@ -0,0 +1,364 @@
+package exchange
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+)
+
+// MarginType represents the margin type of a futures pair
+type MarginType string
+
+const (
+	// MarginTypeIsolated gives each position its own margin, the most it can lose
+	MarginTypeIsolated MarginType = "ISOLATED"
+
+	// MarginTypeCrossed backs the positions with the whole wallet balance
+	MarginTypeCrossed MarginType = "CROSSED"
+)
+
+// FundingRate is the funding rate of a perpetual futures pair at a funding time
+type FundingRate struct {
+	Time time.Time
+	Rate float64
+}
+
+// pairLeverage holds the futures configuration of a pair
+type pairLeverage struct {
+	leverage   float64
+	marginType MarginType
+}
+
+// futuresPosition holds the entry price and the isolated margin of a futures position.
+// The signed quantity of the position is the balance of its base asset.
+type futuresPosition struct {
+	entryPrice float64
+	margin     float64
+}
+
+// WithPaperFutures simulates a futures account: orders open long and short positions on margin,
+// liquidated when their margin falls to the maintenance margin, a fraction of the position value,
+// e.g. 0.005 for 0.5%. Pairs trade at 1x crossed margin unless configured with WithPaperLeverage.
+func WithPaperFutures(maintenanceMargin float64) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.futures = true
+		wallet.maintenanceMargin = maintenanceMargin
+	}
+}
+
+// WithPaperLeverage sets the leverage and the margin type of a pair in futures mode
+func WithPaperLeverage(pair string, leverage int, marginType MarginType) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.leverage[strings.ToUpper(pair)] = pairLeverage{
+			leverage:   float64(leverage),
+			marginType: marginType,
+		}
+	}
+}
+
+// WithPaperFunding sets the funding rates of a pair in futures mode. Each rate is paid on the position
+// value at the first candle starting at or after its time, by longs for positive rates and by shorts for negative ones.
+func WithPaperFunding(pair string, rates []FundingRate) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		sorted := make([]FundingRate, len(rates))
+		copy(sorted, rates)
+		sort.Slice(sorted, func(i, j int) bool {
+			return sorted[i].Time.Before(sorted[j].Time)
+		})
+		wallet.funding[strings.ToUpper(pair)] = sorted
+	}
+}
+
+// LiquidationPrice returns the price at which the futures position of a pair is liquidated,
+// or zero without a position
+func (p *PaperWallet) LiquidationPrice(pair string) float64 {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return p.liquidationPriceLocked(pair)
+}
+
+// Liquidations returns the liquidation orders filled since the last call, so the order controller
+// can close the liquidated positions
+func (p *PaperWallet) Liquidations() []core.Order {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	liquidations := p.liquidations
+	p.liquidations = nil
+	return liquidations
+}
+
+// pairLeverage returns the futures configuration of a pair, 1x crossed margin by default
+func (p *PaperWallet) pairLeverage(pair string) pairLeverage {
+	if config, ok := p.leverage[pair]; ok && config.leverage > 0 {
+		return config
+	}
+	return pairLeverage{leverage: 1, marginType: MarginTypeCrossed}
+}
+
+// positionQuantity returns the signed quantity of the futures position of a pair
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) positionQuantity(pair string) float64 {
+	asset, _ := SplitAssetQuote(pair)
+	if info, ok := p.assets[asset]; ok {
+		return info.Free
+	}
+	return 0
+}
+
+// unrealizedProfit returns the profit of the futures position of a pair at the last close
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) unrealizedProfit(pair string) float64 {
+	position, ok := p.positions[pair]
+	if !ok {
+		return 0
+	}
+	return (p.lastCandle[pair].Close - position.entryPrice) * p.positionQuantity(pair)
+}
+
+// openingQuantity returns the part of an order that increases the futures position of a pair
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) openingQuantity(side core.SideType, pair string, amount float64) float64 {
+	quantity := p.positionQuantity(pair)
+	if side == core.SideTypeSell {
+		quantity = -quantity
+	}
+	return math.Max(amount+math.Min(quantity, 0), 0)
+}
+
+// availableMargin returns the balance available to open positions: the free balance less the
+// initial margin of crossed positions, plus their unrealized profit
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) availableMargin() float64 {
+	available := p.assets[p.baseCoin].Free
+	for pair, position := range p.positions {
+		config := p.pairLeverage(pair)
+		if config.marginType == MarginTypeIsolated {
+			continue
+		}
+		quantity := math.Abs(p.positionQuantity(pair))
+		available += p.unrealizedProfit(pair) - quantity*position.entryPrice/config.leverage
+	}
+	return available
+}
+
+// validateMargin verifies the initial margin of the part of an order that increases the position,
+// and applies filled orders to the position. Orders reversing the position can use the margin
+// and the profit of the closed position.
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) validateMargin(side core.SideType, pair string, amount, value float64, fill bool) error {
+	config := p.pairLeverage(pair)
+	opening := p.openingQuantity(side, pair, amount)
+	required := opening * value / config.leverage
+
+	available := p.availableMargin()
+	if quantity := p.positionQuantity(pair); opening > 0 && opening < amount {
+		position := p.positions[pair]
+		available += (value - position.entryPrice) * quantity
+		if config.marginType == MarginTypeIsolated {
+			available += position.margin
+		} else {
+			available += math.Abs(quantity)*position.entryPrice/config.leverage - p.unrealizedProfit(pair)
+		}
+	}
+
+	if required > 0 && required > available {
+		return &OrderError{
+			Err:      ErrInsufficientFunds,
+			Pair:     pair,
+			Quantity: amount,
+		}
+	}
+
+	if fill {
+		p.applyFuturesFill(side, pair, amount, value)
+	}
+	return nil
+}
+
+// applyFuturesFill updates the futures position of a pair with a fill. The fill closes the opposite
+// position first, realizing its profit and releasing its margin, and opens the rest at its price.
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) applyFuturesFill(side core.SideType, pair string, amount, value float64) {
+	asset, quote := SplitAssetQuote(pair)
+	p.ensureAssetExists(asset)
+	p.ensureAssetExists(quote)
+
+	position, ok := p.positions[pair]
+	if !ok {
+		position = &futuresPosition{}
+		p.positions[pair] = position
+	}
+
+	direction := 1.0
+	if side == core.SideTypeSell {
+		direction = -1
+	}
+
+	quantity := p.assets[asset].Free
+	if quantity*direction < 0 {
+		closed := math.Min(amount, math.Abs(quantity))
+		profit := (value - position.entryPrice) * closed * -direction
+		released := position.margin * closed / math.Abs(quantity)
+		p.log.Infof("PROFIT = %.4f %s (%.2f %%)", profit, quote, profit/(closed*position.entryPrice)*100.0)
+
+		position.margin -= released
+		p.assets[quote].Lock -= released
+		p.assets[quote].Free += released + profit
+
+		amount -= closed
+		if closed == math.Abs(quantity) {
+			quantity, position.entryPrice, position.margin = 0, 0, 0
+		} else {
+			quantity += closed * direction
+		}
+	}
+
+	if amount > 0 {
+		position.entryPrice = (position.entryPrice*math.Abs(quantity) + value*amount) / (math.Abs(quantity) + amount)
+		quantity += amount * direction
+
+		if config := p.pairLeverage(pair); config.marginType == MarginTypeIsolated {
+			margin := amount * value / config.leverage
+			position.margin += margin
+			p.assets[quote].Lock += margin
+			p.assets[quote].Free -= margin
+		}
+	}
+
+	p.assets[asset].Free = quantity
+}
+
+// fillFuturesOrder executes quantity of a limit or stop order on the futures position of its pair.
+// Futures orders don't lock margin, so the order is canceled when the margin runs out.
+// This function acquires the mutex when needed
+func (p *PaperWallet) fillFuturesOrder(order *core.Order, orders []core.Order, candle core.Candle,
+	quantity, price float64, maker bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.validateMargin(order.Side, order.Pair, quantity, price, true); err != nil {
+		p.log.Warnf("canceling order %d: %s", order.ExchangeID, err)
+		order.Status = core.OrderStatusTypeCanceled
+		order.UpdatedAt = candle.Time
+		return
+	}
+
+	// Cancel other orders from the same group on the first fill
+	if order.GroupID != nil && order.ExecutedQuantity == 0 {
+		p.cancelRelatedOrdersLocked(order, orders, candle.Time)
+	}
+
+	// Register volume
+	p.volume[candle.Pair] += price * quantity
+
+	// Update the order
+	fillOrder(order, quantity, candle.Time)
+	p.chargeFee(order, quantity, price, maker)
+}
+
+// liquidationPriceLocked returns the price at which the margin of the futures position of a pair
+// falls to the maintenance margin: the isolated margin, or for crossed positions the free balance
+// with the profit and the maintenance margin of the other crossed positions
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) liquidationPriceLocked(pair string) float64 {
+	position, ok := p.positions[pair]
+	quantity := p.positionQuantity(pair)
+	if !ok || quantity == 0 {
+		return 0
+	}
+
+	collateral := position.margin
+	if p.pairLeverage(pair).marginType != MarginTypeIsolated {
+		collateral = p.assets[p.baseCoin].Free
+		for other := range p.positions {
+			if other == pair || p.pairLeverage(other).marginType == MarginTypeIsolated {
+				continue
+			}
+			value := math.Abs(p.positionQuantity(other)) * p.lastCandle[other].Close
+			collateral += p.unrealizedProfit(other) - value*p.maintenanceMargin
+		}
+	}
+
+	size := math.Abs(quantity)
+	if quantity > 0 {
+		return math.Max((position.entryPrice*size-collateral)/(size*(1-p.maintenanceMargin)), 0)
+	}
+	return (position.entryPrice*size + collateral) / (size * (1 + p.maintenanceMargin))
+}
+
+// liquidateLocked closes the futures position of the candle pair when the candle reaches its
+// liquidation price. The remaining maintenance margin is forfeited as the liquidation fee,
+// and the open orders of the pair are canceled. The liquidation is reported by Liquidations.
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) liquidateLocked(candle core.Candle) {
+	quantity := p.positionQuantity(candle.Pair)
+	if quantity == 0 {
+		return
+	}
+
+	price := p.liquidationPriceLocked(candle.Pair)
+	if quantity > 0 && candle.Low > price || quantity < 0 && candle.High < price {
+		return
+	}
+
+	_, quote := SplitAssetQuote(candle.Pair)
+	side := core.SideTypeSell
+	if quantity < 0 {
+		side = core.SideTypeBuy
+	}
+
+	size := math.Abs(quantity)
+	fee := size * price * p.maintenanceMargin
+	p.applyFuturesFill(side, candle.Pair, size, price)
+	p.assets[quote].Free -= fee
+	p.volume[candle.Pair] += size * price
+	p.log.Warnf("liquidating %s position of %f at %f", candle.Pair, quantity, price)
+
+	for i, order := range p.orders {
+		if order.Pair == candle.Pair && order.IsActive() {
+			p.orders[i].Status = core.OrderStatusTypeCanceled
+			p.orders[i].UpdatedAt = candle.Time
+		}
+	}
+
+	liquidation := core.Order{
+		ExchangeID:       p.ID(),
+		CreatedAt:        candle.Time,
+		UpdatedAt:        candle.Time,
+		Pair:             candle.Pair,
+		Side:             side,
+		Type:             core.OrderTypeMarket,
+		Status:           core.OrderStatusTypeFilled,
+		Price:            price,
+		Quantity:         size,
+		ExecutedQuantity: size,
+		Fee:              fee,
+		FeeAsset:         quote,
+	}
+	p.orders = append(p.orders, liquidation)
+	p.liquidations = append(p.liquidations, liquidation)
+}
+
+// payFundingLocked pays the funding rates of the candle pair due at the start of the candle
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) payFundingLocked(candle core.Candle) {
+	rates := p.funding[candle.Pair]
+	next := p.fundingIndex[candle.Pair]
+	for ; next < len(rates) && !rates[next].Time.After(candle.Time); next++ {
+		quantity := p.positionQuantity(candle.Pair)
+		if quantity == 0 {
+			continue
+		}
+
+		_, quote := SplitAssetQuote(candle.Pair)
+		payment := quantity * candle.Open * rates[next].Rate
+		p.assets[quote].Free -= payment
+		p.log.Infof("FUNDING %s = %.4f %s", candle.Pair, -payment, quote)
+	}
+	p.fundingIndex[candle.Pair] = next
+}
@@ -0,0 +1,161 @@
+package exchange
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaperWallet_Futures(t *testing.T) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hour := func(h int) time.Time { return start.Add(time.Duration(h) * time.Hour) }
+
+	newWallet := func(options ...PaperWalletOption) *PaperWallet {
+		options = append([]PaperWalletOption{WithPaperAsset("USDT", 1000), WithPaperFutures(0.005)}, options...)
+		wallet := NewPaperWallet(ctx, "USDT", getLog(), options...)
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(0), Open: 100, High: 100, Low: 100, Close: 100})
+		return wallet
+	}
+
+	t.Run("isolated long", func(t *testing.T) {
+		wallet := newWallet(WithPaperLeverage("btcusdt", 10, MarginTypeIsolated))
+
+		_, err := wallet.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 50)
+		require.NoError(t, err)
+		require.Equal(t, 50.0, wallet.assets["BTC"].Free)
+		require.Equal(t, 500.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 500.0, wallet.assets["USDT"].Lock)
+		require.InDelta(t, 4500/49.75, wallet.LiquidationPrice("BTCUSDT"), 1e-9)
+
+		asset, _, err := wallet.Position(ctx, "BTCUSDT")
+		require.NoError(t, err)
+		require.Equal(t, 50.0, asset)
+
+		account, err := wallet.Account(ctx)
+		require.NoError(t, err)
+		balance, _ := account.GetBalance("BTC", "USDT")
+		require.Equal(t, 10.0, balance.Leverage)
+
+		_, err = wallet.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 60)
+		require.Equal(t, &OrderError{Err: ErrInsufficientFunds, Pair: "BTCUSDT", Quantity: 60}, err)
+
+		// closing orders don't need margin
+		_, err = wallet.CreateOrderLimit(ctx, core.SideTypeSell, "BTCUSDT", 50, 110)
+		require.NoError(t, err)
+		require.Equal(t, 500.0, wallet.assets["USDT"].Lock)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(1), Open: 105, High: 111, Low: 104, Close: 105, Complete: true})
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[1].Status)
+		require.Equal(t, 0.0, wallet.assets["BTC"].Free)
+		require.Equal(t, 1500.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 0.0, wallet.assets["USDT"].Lock)
+		require.Zero(t, wallet.LiquidationPrice("BTCUSDT"))
+	})
+
+	t.Run("crossed short", func(t *testing.T) {
+		wallet := newWallet()
+
+		_, err := wallet.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 10)
+		require.NoError(t, err)
+		require.Equal(t, -10.0, wallet.assets["BTC"].Free)
+		require.Equal(t, 1000.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 0.0, wallet.assets["USDT"].Lock)
+
+		// the balance is used as margin by the short
+		_, err = wallet.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 1)
+		require.Equal(t, &OrderError{Err: ErrInsufficientFunds, Pair: "BTCUSDT", Quantity: 1}, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(1), Open: 90, High: 92, Low: 88, Close: 90, Complete: true})
+		require.Equal(t, 1100.0, wallet.equityValues[0].Value)
+
+		// reverse into a long
+		_, err = wallet.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 11)
+		require.NoError(t, err)
+		require.Equal(t, 1.0, wallet.assets["BTC"].Free)
+		require.Equal(t, 1100.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 90.0, wallet.positions["BTCUSDT"].entryPrice)
+	})
+
+	t.Run("isolated liquidation", func(t *testing.T) {
+		wallet := newWallet(WithPaperLeverage("BTCUSDT", 10, MarginTypeIsolated))
+
+		_, err := wallet.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 50)
+		require.NoError(t, err)
+		_, err = wallet.CreateOrderLimit(ctx, core.SideTypeSell, "BTCUSDT", 50, 120)
+		require.NoError(t, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(1), Open: 95, High: 96, Low: 91, Close: 95})
+		require.Equal(t, 50.0, wallet.assets["BTC"].Free)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(2), Open: 95, High: 96, Low: 85, Close: 95})
+		require.Equal(t, 0.0, wallet.assets["BTC"].Free)
+		require.InDelta(t, 500.0, wallet.assets["USDT"].Free, 1e-9)
+		require.InDelta(t, 0.0, wallet.assets["USDT"].Lock, 1e-9)
+
+		require.Len(t, wallet.orders, 3)
+		require.Equal(t, core.OrderStatusTypeCanceled, wallet.orders[1].Status)
+		liquidation := wallet.orders[2]
+		require.Equal(t, core.OrderStatusTypeFilled, liquidation.Status)
+		require.Equal(t, core.SideTypeSell, liquidation.Side)
+		require.Equal(t, 50.0, liquidation.Quantity)
+		require.InDelta(t, 4500/49.75, liquidation.Price, 1e-9)
+		require.Equal(t, hour(2), liquidation.UpdatedAt)
+
+		// liquidations are reported once
+		require.Equal(t, []core.Order{liquidation}, wallet.Liquidations())
+		require.Empty(t, wallet.Liquidations())
+	})
+
+	t.Run("crossed liquidation", func(t *testing.T) {
+		wallet := newWallet(WithPaperLeverage("BTCUSDT", 10, MarginTypeCrossed))
+
+		_, err := wallet.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 50)
+		require.NoError(t, err)
+		require.Equal(t, 1000.0, wallet.assets["USDT"].Free)
+		require.InDelta(t, 4000/49.75, wallet.LiquidationPrice("BTCUSDT"), 1e-9)
+
+		// beyond the isolated liquidation price, the balance still backs the position
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(1), Open: 95, High: 96, Low: 85, Close: 95})
+		require.Equal(t, 50.0, wallet.assets["BTC"].Free)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(2), Open: 85, High: 86, Low: 80, Close: 85})
+		require.Equal(t, 0.0, wallet.assets["BTC"].Free)
+		require.InDelta(t, 0.0, wallet.assets["USDT"].Free, 1e-9)
+	})
+
+	t.Run("funding", func(t *testing.T) {
+		wallet := newWallet(WithPaperFunding("BTCUSDT", []FundingRate{
+			{Time: hour(2), Rate: -0.002},
+			{Time: hour(1), Rate: 0.001},
+		}))
+
+		_, err := wallet.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(1), Open: 100, High: 100, Low: 100, Close: 110})
+		require.InDelta(t, 999.9, wallet.assets["USDT"].Free, 1e-9)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(2), Open: 110, High: 110, Low: 110, Close: 110})
+		require.InDelta(t, 1000.12, wallet.assets["USDT"].Free, 1e-9)
+
+		// rates are paid once
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(3), Open: 110, High: 110, Low: 110, Close: 110})
+		require.InDelta(t, 1000.12, wallet.assets["USDT"].Free, 1e-9)
+	})
+
+	t.Run("fees are paid in the quote asset", func(t *testing.T) {
+		wallet := newWallet(WithPaperFee(0, 0.001))
+
+		order, err := wallet.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 5)
+		require.NoError(t, err)
+		require.Equal(t, "USDT", order.FeeAsset)
+		require.InDelta(t, 0.5, order.Fee, 1e-12)
+		require.Equal(t, 5.0, wallet.assets["BTC"].Free)
+		require.InDelta(t, 999.5, wallet.assets["USDT"].Free, 1e-12)
+	})
+}
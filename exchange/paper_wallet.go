@@ -7,12 +7,15 @@ import (
 	"errors"
 	"fmt"
 	"math"
+	"math/rand"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/metric"
 
 	"github.com/adshao/go-binance/v2/common"
 )
@@ -46,6 +49,26 @@ type PaperWallet struct {
 	counter      atomic.Int64
 	feeder       core.Feeder
 
+	// Execution simulation
+	slippage      SlippageModel
+	participation float64
+	intrabar      IntrabarModel
+	latency       LatencyModel
+	timeout       time.Duration
+	rejection     float64
+	rng           *rand.Rand
+	sentAt        map[int64]time.Time
+	modelErrors   map[string]struct{} // errors of the execution models already logged
+
+	// Futures simulation
+	futures           bool
+	maintenanceMargin float64
+	leverage          map[string]pairLeverage
+	positions         map[string]*futuresPosition
+	funding           map[string][]FundingRate
+	fundingIndex      map[string]int
+	liquidations      []core.Order
+
 	// Wallet data
 	orders        []core.Order
 	assets        map[string]*assetInfo
@@ -60,6 +83,7 @@ type PaperWallet struct {
 	// Value history
 	assetValues  map[string][]AssetValue
 	equityValues []AssetValue
+	closeValues  map[string][]AssetValue
 
 	log core.Logger
 }
@@ -81,7 +105,8 @@ func WithPaperAsset(pair string, amount float64) PaperWalletOption {
 	}
 }
 
-// WithPaperFee configures the wallet fees
+// WithPaperFee configures the wallet fees as fractions of the fill value, e.g. 0.001 for 0.1%.
+// Resting limit fills pay the maker fee, market and stop fills the taker fee.
 func WithPaperFee(maker, taker float64) PaperWalletOption {
 	return func(wallet *PaperWallet) {
 		wallet.makerFee = maker
@@ -89,6 +114,58 @@ func WithPaperFee(maker, taker float64) PaperWalletOption {
 	}
 }
 
+// WithPaperSlippage configures the slippage model of market and stop fills.
+// Limit fills keep their limit price.
+func WithPaperSlippage(model SlippageModel) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.slippage = model
+	}
+}
+
+// WithPaperParticipation caps each fill at a fraction of the candle volume, e.g. 0.1 for 10%.
+// Larger orders are partially filled over the next candles, market orders at their close.
+func WithPaperParticipation(rate float64) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.participation = rate
+	}
+}
+
+// WithPaperIntrabar configures the price path used to fill limit and stop orders inside a candle.
+// Orders fill in the order the path reaches them, stop orders at the open when the candle gaps past the stop.
+func WithPaperIntrabar(model IntrabarModel) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.intrabar = model
+	}
+}
+
+// WithPaperLatency delays market orders until the latency model executes them,
+// e.g. NextOpenLatency fills them at the open of the next candle instead of the signal candle close
+func WithPaperLatency(model LatencyModel) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.latency = model
+	}
+}
+
+// WithPaperTimeout expires orders still open a timeout after they reach the exchange, releasing their funds.
+// Orders reach the exchange at the start of the candle after their creation.
+func WithPaperTimeout(timeout time.Duration) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		wallet.timeout = timeout
+	}
+}
+
+// WithPaperRejection rejects new orders with a probability, e.g. 0.01 for 1%.
+// The seed makes the rejections reproducible, zero uses the current time.
+func WithPaperRejection(probability float64, seed int64) PaperWalletOption {
+	return func(wallet *PaperWallet) {
+		if seed == 0 {
+			seed = time.Now().UnixNano()
+		}
+		wallet.rejection = probability
+		wallet.rng = rand.New(rand.NewSource(seed))
+	}
+}
+
 // WithDataFeed configures the data provider
 func WithDataFeed(feeder core.Feeder) PaperWalletOption {
 	return func(wallet *PaperWallet) {
@@ -115,6 +192,13 @@ func NewPaperWallet(ctx context.Context, baseCoin string, log core.Logger, optio
 		volume:        make(map[string]float64),
 		assetValues:   make(map[string][]AssetValue),
 		equityValues:  make([]AssetValue, 0),
+		closeValues:   make(map[string][]AssetValue),
+		sentAt:        make(map[int64]time.Time),
+		modelErrors:   make(map[string]struct{}),
+		leverage:      make(map[string]pairLeverage),
+		positions:     make(map[string]*futuresPosition),
+		funding:       make(map[string][]FundingRate),
+		fundingIndex:  make(map[string]int),
 	}
 
 	// Apply options
@@ -185,6 +269,11 @@ func (p *PaperWallet) AssetValues(pair string) []AssetValue {
 	return p.assetValues[pair]
 }
 
+// InitialValue returns the base coin balance the wallet started with
+func (p *PaperWallet) InitialValue() float64 {
+	return p.initialValue
+}
+
 // EquityValues returns the wallet's value history
 func (p *PaperWallet) EquityValues() []AssetValue {
 	p.mu.RLock()
@@ -192,6 +281,94 @@ func (p *PaperWallet) EquityValues() []AssetValue {
 	return p.equityValues
 }
 
+// EquityCurve returns the wallet's value history, flagging the times a position was open
+func (p *PaperWallet) EquityCurve() []metric.EquityPoint {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	inMarket := make(map[int64]bool)
+	for asset, values := range p.assetValues {
+		if asset == p.baseCoin {
+			continue
+		}
+		for _, value := range values {
+			if value.Value != 0 {
+				inMarket[value.Time.UnixNano()] = true
+			}
+		}
+	}
+
+	points := make([]metric.EquityPoint, len(p.equityValues))
+	for i, value := range p.equityValues {
+		points[i] = metric.EquityPoint{
+			Time:     value.Time,
+			Value:    value.Value,
+			InMarket: inMarket[value.Time.UnixNano()],
+		}
+	}
+	return points
+}
+
+// BenchmarkCurve returns the value history of a buy-and-hold portfolio of the same candles.
+// The initial value is split equally between pairs, each bought at the close of its first candle
+// and held in the base coin until then.
+func (p *PaperWallet) BenchmarkCurve() []metric.EquityPoint {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.closeValues) == 0 {
+		return nil
+	}
+
+	pairs := make([]string, 0, len(p.closeValues))
+	times := make(map[int64]time.Time)
+	for pair, values := range p.closeValues {
+		pairs = append(pairs, pair)
+		for _, value := range values {
+			times[value.Time.UnixNano()] = value.Time
+		}
+	}
+	sort.Strings(pairs)
+
+	timeline := make([]time.Time, 0, len(times))
+	for _, t := range times {
+		timeline = append(timeline, t)
+	}
+	sort.Slice(timeline, func(i, j int) bool {
+		return timeline[i].Before(timeline[j])
+	})
+
+	allocation := p.initialValue / float64(len(pairs))
+	indexes := make(map[string]int, len(pairs))
+	points := make([]metric.EquityPoint, 0, len(timeline))
+	for _, t := range timeline {
+		total := 0.0
+		for _, pair := range pairs {
+			values := p.closeValues[pair]
+			index := indexes[pair]
+			for index < len(values) && !values[index].Time.After(t) {
+				index++
+			}
+			indexes[pair] = index
+
+			// Cash until the first candle of the pair
+			if index == 0 || values[0].Value <= 0 {
+				total += allocation
+				continue
+			}
+			total += allocation * values[index-1].Value / values[0].Value
+		}
+
+		points = append(points, metric.EquityPoint{
+			Time:     t,
+			Value:    total,
+			InMarket: true,
+		})
+	}
+
+	return points
+}
+
 // getAssetFreeAmount returns the free balance of an asset
 func (p *PaperWallet) getAssetFreeAmount(asset string) float64 {
 	assetInfo, ok := p.assets[asset]
@@ -342,6 +519,11 @@ func (p *PaperWallet) calculateAssetValue(pair, asset string, quantity float64)
 		return 0
 	}
 
+	// Futures positions are worth their unrealized profit
+	if p.futures {
+		return p.unrealizedProfit(pair)
+	}
+
 	// If the quantity is positive, it's a long position
 	if quantity > 0 {
 		return quantity * p.lastCandle[pair].Close
@@ -373,6 +555,10 @@ func (p *PaperWallet) validateFunds(side core.SideType, pair string, amount, val
 	p.ensureAssetExists(asset)
 	p.ensureAssetExists(quote)
 
+	if p.futures {
+		return p.validateMargin(side, pair, amount, value, fill)
+	}
+
 	// Check if there are sufficient funds for the operation
 	if side == core.SideTypeSell {
 		return p.validateSellFunds(pair, asset, quote, amount, value, fill)
@@ -580,6 +766,10 @@ func (p *PaperWallet) OnCandle(candle core.Candle) {
 		p.fistCandle[candle.Pair] = candle
 	}
 
+	if p.futures {
+		p.payFundingLocked(candle)
+	}
+
 	// Create a local copy of orders to process to avoid holding the lock during processing
 	ordersToProcess := make([]core.Order, len(p.orders))
 	copy(ordersToProcess, p.orders)
@@ -594,6 +784,11 @@ func (p *PaperWallet) OnCandle(candle core.Candle) {
 	p.mu.Lock()
 	p.orders = updatedOrders
 
+	if p.futures {
+		p.liquidateLocked(candle)
+	}
+	p.logModelErrorsLocked()
+
 	// Update portfolio values if the candle is complete
 	if candle.Complete {
 		p.updatePortfolioValues(candle)
@@ -601,6 +796,28 @@ func (p *PaperWallet) OnCandle(candle core.Candle) {
 	p.mu.Unlock()
 }
 
+// logModelErrorsLocked logs once the errors reported by the intrabar and latency models,
+// such as FeedIntrabar and FeedLatency failing to load lower timeframe candles
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) logModelErrorsLocked() {
+	for _, model := range []any{p.intrabar, p.latency} {
+		reporter, ok := model.(interface{ Err() error })
+		if !ok {
+			continue
+		}
+
+		err := reporter.Err()
+		if err == nil {
+			continue
+		}
+		if _, logged := p.modelErrors[err.Error()]; logged {
+			continue
+		}
+		p.modelErrors[err.Error()] = struct{}{}
+		p.log.Warnf("execution model fell back to the candle prices: %s", err)
+	}
+}
+
 // processOrders processes pending orders based on the new candle
 // This function doesn't hold the mutex lock
 func (p *PaperWallet) processOrders(candle core.Candle, orders []core.Order) []core.Order {
@@ -612,18 +829,28 @@ func (p *PaperWallet) processOrders(candle core.Candle, orders []core.Order) []c
 	if _, ok := p.volume[candle.Pair]; !ok {
 		p.volume[candle.Pair] = 0
 	}
+	p.expireOrdersLocked(candle, result)
 	p.mu.Unlock()
 
+	if p.intrabar != nil {
+		p.processIntrabarOrders(candle, result)
+	}
+
 	for i, order := range result {
 		// Ignore orders that are not for this pair or that are not pending
-		if order.Pair != candle.Pair || order.Status != core.OrderStatusTypeNew {
+		if order.Pair != candle.Pair || !order.IsActive() {
 			continue
 		}
 
-		// Process the order based on side (buy/sell)
-		if order.Side == core.SideTypeBuy {
-			p.processBuyOrder(&result[i], candle)
-		} else {
+		// Process the order based on type and side (buy/sell)
+		switch {
+		case order.Type == core.OrderTypeMarket:
+			p.processMarketOrder(&result[i], candle)
+		case p.intrabar != nil:
+			// Already processed along the price path
+		case order.Side == core.SideTypeBuy:
+			p.processBuyOrder(&result[i], &result, candle)
+		default:
 			p.processSellOrder(&result[i], &result, candle)
 		}
 	}
@@ -633,35 +860,79 @@ func (p *PaperWallet) processOrders(candle core.Candle, orders []core.Order) []c
 
 // processBuyOrder processes a buy order
 // This function acquires the mutex when needed
-func (p *PaperWallet) processBuyOrder(order *core.Order, candle core.Candle) {
+func (p *PaperWallet) processBuyOrder(order *core.Order, orders *[]core.Order, candle core.Candle) {
 	// Check if the buy price was reached
 	if order.Price < candle.Close {
 		return
 	}
 
+	quantity := p.fillQuantity(order.Quantity-order.ExecutedQuantity, candle)
+	if quantity <= 0 {
+		return
+	}
+
+	p.fillBuyOrder(order, *orders, candle, quantity, order.Price, true)
+}
+
+// fillBuyOrder executes quantity of a buy order at price, releasing the funds locked for it
+// This function acquires the mutex when needed
+func (p *PaperWallet) fillBuyOrder(order *core.Order, orders []core.Order, candle core.Candle,
+	quantity, price float64, maker bool) {
+	if p.futures {
+		p.fillFuturesOrder(order, orders, candle, quantity, price, maker)
+		return
+	}
+
 	asset, quote := SplitAssetQuote(order.Pair)
+	locked := lockedPrice(*order, orders)
 
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	p.ensureAssetExists(asset)
 
+	// Cancel other orders from the same group on the first fill
+	if order.GroupID != nil && order.ExecutedQuantity == 0 {
+		p.cancelRelatedOrdersLocked(order, orders, candle.Time)
+	}
+
 	// Register volume
-	p.volume[candle.Pair] += order.Price * order.Quantity
+	p.volume[candle.Pair] += price * quantity
 
 	// Update the order
-	order.UpdatedAt = candle.Time
-	order.Status = core.OrderStatusTypeFilled
+	fillOrder(order, quantity, candle.Time)
 
 	// Update average price and balances
-	p.updateAveragePrice(order.Side, order.Pair, order.Quantity, order.Price)
-	p.assets[asset].Free = p.assets[asset].Free + order.Quantity
-	p.assets[quote].Lock = p.assets[quote].Lock - order.Price*order.Quantity
+	p.updateAveragePrice(order.Side, order.Pair, quantity, price)
+	p.assets[asset].Free = p.assets[asset].Free + quantity
+	p.assets[quote].Lock = p.assets[quote].Lock - locked*quantity
+	p.assets[quote].Free = p.assets[quote].Free + (locked-price)*quantity
+	p.chargeFee(order, quantity, price, maker)
+}
+
+// lockedPrice returns the price at which the funds of a buy order were locked,
+// the limit price of the group for OCO stop orders
+func lockedPrice(order core.Order, orders []core.Order) float64 {
+	if order.GroupID == nil || !isStopOrder(order.Type) {
+		return order.Price
+	}
+
+	for _, groupOrder := range orders {
+		if groupOrder.GroupID != nil && *groupOrder.GroupID == *order.GroupID && isLimitOrder(groupOrder.Type) {
+			return groupOrder.Price
+		}
+	}
+	return order.Price
 }
 
 // processSellOrder processes a sell order
 // This function acquires the mutex when needed
 func (p *PaperWallet) processSellOrder(order *core.Order, orders *[]core.Order, candle core.Candle) {
+	quantity := p.fillQuantity(order.Quantity-order.ExecutedQuantity, candle)
+	if quantity <= 0 {
+		return
+	}
+
 	// Determine the execution price of the order
 	var orderPrice float64
 
@@ -669,11 +940,27 @@ func (p *PaperWallet) processSellOrder(order *core.Order, orders *[]core.Order,
 	if isLimitOrder(order.Type) && candle.High >= order.Price {
 		orderPrice = order.Price
 	} else if isStopOrder(order.Type) && order.Stop != nil && candle.Low <= *order.Stop {
-		orderPrice = *order.Stop
+		// Stop orders execute as market orders and report their executed price
+		var slippage float64
+		orderPrice, slippage = p.fillPrice(order.Side, quantity, *order.Stop, candle)
+		order.Slippage += slippage
+		order.Price = averageFillPrice(*order, quantity, orderPrice)
 	} else {
 		return // Price not reached
 	}
 
+	p.fillSellOrder(order, *orders, candle, quantity, orderPrice, isLimitOrder(order.Type))
+}
+
+// fillSellOrder executes quantity of a sell order at price, releasing the assets locked for it
+// This function acquires the mutex when needed
+func (p *PaperWallet) fillSellOrder(order *core.Order, orders []core.Order, candle core.Candle,
+	quantity, price float64, maker bool) {
+	if p.futures {
+		p.fillFuturesOrder(order, orders, candle, quantity, price, maker)
+		return
+	}
+
 	asset, quote := SplitAssetQuote(order.Pair)
 
 	p.mu.Lock()
@@ -681,23 +968,286 @@ func (p *PaperWallet) processSellOrder(order *core.Order, orders *[]core.Order,
 
 	p.ensureAssetExists(quote)
 
-	// Cancel other orders from the same group
-	if order.GroupID != nil {
-		p.cancelRelatedOrdersLocked(order, *orders, candle.Time)
+	// Cancel other orders from the same group on the first fill
+	if order.GroupID != nil && order.ExecutedQuantity == 0 {
+		p.cancelRelatedOrdersLocked(order, orders, candle.Time)
 	}
 
 	// Register volume
-	orderVolume := order.Quantity * orderPrice
+	orderVolume := quantity * price
 	p.volume[candle.Pair] += orderVolume
 
 	// Update the order
-	order.UpdatedAt = candle.Time
-	order.Status = core.OrderStatusTypeFilled
+	fillOrder(order, quantity, candle.Time)
 
 	// Update average price and balances
-	p.updateAveragePrice(order.Side, order.Pair, order.Quantity, orderPrice)
-	p.assets[asset].Lock = p.assets[asset].Lock - order.Quantity
-	p.assets[quote].Free = p.assets[quote].Free + order.Quantity*orderPrice
+	p.updateAveragePrice(order.Side, order.Pair, quantity, price)
+	p.assets[asset].Lock = p.assets[asset].Lock - quantity
+	p.assets[quote].Free = p.assets[quote].Free + quantity*price
+	p.chargeFee(order, quantity, price, maker)
+}
+
+// intrabarFill is an order reached by the price path of a candle
+type intrabarFill struct {
+	index    int     // position of the order in the list
+	distance float64 // distance along the path where the price reached the order
+	price    float64 // price that triggered the order
+}
+
+// processIntrabarOrders fills the limit and stop orders of a pair in the order the price path
+// of the candle reaches them, so only the first order of an OCO group fills
+// This function acquires the mutex when needed
+func (p *PaperWallet) processIntrabarOrders(candle core.Candle, orders []core.Order) {
+	paths := map[core.SideType][]float64{
+		core.SideTypeBuy:  p.intrabar.Path(core.SideTypeBuy, candle),
+		core.SideTypeSell: p.intrabar.Path(core.SideTypeSell, candle),
+	}
+
+	fills := make([]intrabarFill, 0)
+	for i, order := range orders {
+		if order.Pair != candle.Pair || !order.IsActive() || order.Type == core.OrderTypeMarket {
+			continue
+		}
+		if fill, ok := reachOrder(order, paths[order.Side]); ok {
+			fill.index = i
+			fills = append(fills, fill)
+		}
+	}
+
+	sort.SliceStable(fills, func(i, j int) bool {
+		return fills[i].distance < fills[j].distance
+	})
+
+	for _, fill := range fills {
+		order := &orders[fill.index]
+		if !order.IsActive() {
+			continue // canceled by an earlier fill of its group
+		}
+
+		quantity := p.fillQuantity(order.Quantity-order.ExecutedQuantity, candle)
+		if quantity <= 0 {
+			continue
+		}
+
+		// Stop orders execute as market orders and report their executed price
+		price, maker := fill.price, isLimitOrder(order.Type)
+		if !maker {
+			var slippage float64
+			price, slippage = p.fillPrice(order.Side, quantity, price, candle)
+			order.Slippage += slippage
+			order.Price = averageFillPrice(*order, quantity, price)
+		}
+
+		if order.Side == core.SideTypeBuy {
+			p.fillBuyOrder(order, orders, candle, quantity, price, maker)
+		} else {
+			p.fillSellOrder(order, orders, candle, quantity, price, maker)
+		}
+	}
+}
+
+// reachOrder finds where a price path first reaches a limit or stop order. Limit orders fill
+// at their price, stop orders at the stop price or at the open when the candle gaps past it.
+func reachOrder(order core.Order, path []float64) (intrabarFill, bool) {
+	var level float64
+	var above bool // the order is reached at or above the level
+	switch {
+	case isLimitOrder(order.Type):
+		level, above = order.Price, order.Side == core.SideTypeSell
+	case isStopOrder(order.Type) && order.Stop != nil:
+		level, above = *order.Stop, order.Side == core.SideTypeBuy
+	default:
+		return intrabarFill{}, false
+	}
+
+	reached := func(price float64) bool {
+		if above {
+			return price >= level
+		}
+		return price <= level
+	}
+
+	if len(path) == 0 {
+		return intrabarFill{}, false
+	}
+
+	if reached(path[0]) {
+		if isStopOrder(order.Type) {
+			return intrabarFill{price: path[0]}, true
+		}
+		return intrabarFill{price: level}, true
+	}
+
+	for i := 1; i < len(path); i++ {
+		if reached(path[i]) {
+			from, to := path[i-1], path[i]
+			return intrabarFill{distance: float64(i-1) + (level-from)/(to-from), price: level}, true
+		}
+	}
+	return intrabarFill{}, false
+}
+
+// processMarketOrder fills the remaining quantity of a market order at the candle close,
+// or at the execution of the latency model for delayed orders.
+// Market orders don't lock funds, so the order is canceled when the funds run out.
+// This function acquires the mutex when needed
+func (p *PaperWallet) processMarketOrder(order *core.Order, candle core.Candle) {
+	quantity := p.fillQuantity(order.Quantity-order.ExecutedQuantity, candle)
+	if quantity <= 0 {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	executedAt, price, ok := p.marketExecution(*order, candle)
+	if !ok {
+		return // Waiting for the latency
+	}
+
+	if sent, ok := p.sentAt[order.ExchangeID]; ok && p.timeout > 0 && executedAt.Sub(sent) > p.timeout {
+		order.Status = core.OrderStatusTypeExpired
+		order.UpdatedAt = sent.Add(p.timeout)
+		delete(p.sentAt, order.ExchangeID)
+		return
+	}
+
+	price, slippage := p.fillPrice(order.Side, quantity, price, candle)
+	if err := p.validateFunds(order.Side, order.Pair, quantity, price, true); err != nil {
+		p.log.Warnf("canceling market order %d: %s", order.ExchangeID, err)
+		order.Status = core.OrderStatusTypeCanceled
+		order.UpdatedAt = executedAt
+		return
+	}
+
+	// Register volume
+	p.volume[candle.Pair] += price * quantity
+
+	// Update the order
+	order.Price = averageFillPrice(*order, quantity, price)
+	order.Slippage += slippage
+	fillOrder(order, quantity, executedAt)
+	p.chargeFee(order, quantity, price, false)
+}
+
+// marketExecution returns the time and the price at which a market order executes in the candle,
+// or false while it waits. The first execution of delayed orders follows the latency model, the rest the candle close.
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) marketExecution(order core.Order, candle core.Candle) (time.Time, float64, bool) {
+	if p.latency == nil || order.ExecutedQuantity > 0 {
+		return candle.Time, candle.Close, true
+	}
+	return p.latency.Execution(p.sentAt[order.ExchangeID], candle)
+}
+
+// expireOrdersLocked records when the orders of the candle pair reach the exchange, at the start of
+// the first candle after their creation, and expires the orders open for longer than the timeout
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) expireOrdersLocked(candle core.Candle, orders []core.Order) {
+	if p.latency == nil && p.timeout <= 0 {
+		return
+	}
+
+	for _, order := range orders {
+		if order.Pair != candle.Pair {
+			continue
+		}
+
+		sent, ok := p.sentAt[order.ExchangeID]
+		switch {
+		case !order.IsActive():
+			delete(p.sentAt, order.ExchangeID)
+		case !ok:
+			p.sentAt[order.ExchangeID] = candle.Time
+		case p.timeout > 0 && order.Type != core.OrderTypeMarket && !candle.Time.Before(sent.Add(p.timeout)):
+			// The order and the other orders of its group expire, releasing the funds locked once
+			p.releaseFundsLocked(order)
+			for j, groupOrder := range orders {
+				if groupOrder.ExchangeID == order.ExchangeID ||
+					order.GroupID != nil && groupOrder.GroupID != nil && *groupOrder.GroupID == *order.GroupID {
+					orders[j].Status = core.OrderStatusTypeExpired
+					orders[j].UpdatedAt = sent.Add(p.timeout)
+					delete(p.sentAt, groupOrder.ExchangeID)
+				}
+			}
+		}
+	}
+}
+
+// rejectOrderLocked draws whether the exchange rejects a new order
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) rejectOrderLocked(pair string, quantity float64) error {
+	if p.rejection <= 0 || p.rng.Float64() >= p.rejection {
+		return nil
+	}
+	return &OrderError{Err: ErrOrderRejected, Pair: pair, Quantity: quantity}
+}
+
+// fillQuantity returns the quantity of an order that can fill in a candle, limited by the participation cap
+func (p *PaperWallet) fillQuantity(remaining float64, candle core.Candle) float64 {
+	if p.participation <= 0 {
+		return remaining
+	}
+	return math.Min(remaining, p.participation*candle.Volume)
+}
+
+// fillOrder records an executed quantity on an order, which is filled once no quantity remains
+func fillOrder(order *core.Order, quantity float64, t time.Time) {
+	order.UpdatedAt = t
+	if quantity >= order.Quantity-order.ExecutedQuantity {
+		order.ExecutedQuantity = order.Quantity
+		order.Status = core.OrderStatusTypeFilled
+		return
+	}
+
+	order.ExecutedQuantity += quantity
+	order.Status = core.OrderStatusTypePartiallyFilled
+}
+
+// averageFillPrice returns the average price of an order after a fill of quantity at price
+func averageFillPrice(order core.Order, quantity, price float64) float64 {
+	if order.ExecutedQuantity == 0 {
+		return price
+	}
+	return (order.Price*order.ExecutedQuantity + price*quantity) / (order.ExecutedQuantity + quantity)
+}
+
+// chargeFee deducts the fee of a fill from the received asset and adds it to the order:
+// the base asset for buys and the quote asset for sells. Futures fills pay in the quote asset.
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) chargeFee(order *core.Order, quantity, price float64, maker bool) {
+	rate := p.takerFee
+	if maker {
+		rate = p.makerFee
+	}
+	if rate == 0 {
+		return
+	}
+
+	asset, quote := SplitAssetQuote(order.Pair)
+	fee := quantity * price * rate
+	order.FeeAsset = quote
+	if order.Side == core.SideTypeBuy && !p.futures {
+		fee, order.FeeAsset = quantity*rate, asset
+	}
+
+	order.Fee += fee
+	p.ensureAssetExists(order.FeeAsset)
+	p.assets[order.FeeAsset].Free -= fee
+}
+
+// fillPrice returns the price of a market or stop fill after slippage, and the slippage cost in quote currency
+func (p *PaperWallet) fillPrice(side core.SideType, quantity, price float64, candle core.Candle) (float64, float64) {
+	if p.slippage == nil {
+		return price, 0
+	}
+
+	fraction := math.Max(p.slippage.Slippage(side, quantity, price, candle), 0)
+	fill := price * (1 + fraction)
+	if side == core.SideTypeSell {
+		fill = price * (1 - fraction)
+	}
+	return fill, math.Abs(fill-price) * quantity
 }
 
 // isLimitOrder checks if it's a limit order type
@@ -739,7 +1289,10 @@ func (p *PaperWallet) updatePortfolioValues(candle core.Candle) {
 
 		// Calculate asset value
 		var assetValue float64
-		if amount < 0 {
+		if p.futures {
+			assetValue = p.unrealizedProfit(pair)
+			total += assetValue
+		} else if amount < 0 {
 			v := math.Abs(amount)
 			liquid := 2*v*p.avgShortPrice[pair] - v*p.lastCandle[pair].Close
 			total += liquid
@@ -756,6 +1309,12 @@ func (p *PaperWallet) updatePortfolioValues(candle core.Candle) {
 		})
 	}
 
+	// Register close price for the benchmark
+	p.closeValues[candle.Pair] = append(p.closeValues[candle.Pair], AssetValue{
+		Time:  candle.Time,
+		Value: candle.Close,
+	})
+
 	// Register total wallet value
 	baseCoinInfo := p.assets[p.baseCoin]
 	p.equityValues = append(p.equityValues, AssetValue{
@@ -775,11 +1334,17 @@ func (p *PaperWallet) Account(_ context.Context) (core.Account, error) {
 
 	balances := make([]core.Balance, 0, len(p.assets))
 	for pair, info := range p.assets {
-		balances = append(balances, core.Balance{
+		balance := core.Balance{
 			Asset: pair,
 			Free:  info.Free,
 			Lock:  info.Lock,
-		})
+		}
+
+		// Futures positions report the leverage of their pair
+		if p.futures && pair != p.baseCoin {
+			balance.Leverage = p.pairLeverage(strings.ToUpper(pair + p.baseCoin)).leverage
+		}
+		balances = append(balances, balance)
 	}
 
 	return core.NewAccount(balances)
@@ -814,6 +1379,10 @@ func (p *PaperWallet) CreateOrderOCO(_ context.Context, side core.SideType, pair
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if err := p.rejectOrderLocked(pair, size); err != nil {
+		return nil, err
+	}
+
 	// Check available funds
 	err := p.validateFunds(side, pair, size, price, false)
 	if err != nil {
@@ -870,6 +1439,10 @@ func (p *PaperWallet) CreateOrderLimit(_ context.Context, side core.SideType, pa
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if err := p.rejectOrderLocked(pair, size); err != nil {
+		return core.Order{}, err
+	}
+
 	// Check available funds
 	err := p.validateFunds(side, pair, size, limit, false)
 	if err != nil {
@@ -904,31 +1477,57 @@ func (p *PaperWallet) CreateOrderMarket(ctx context.Context, side core.SideType,
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	// Check and apply funds (with immediate fill)
-	err := p.validateFunds(side, pair, size, p.lastCandle[pair].Close, true)
-	if err != nil {
+	if err := p.rejectOrderLocked(pair, size); err != nil {
 		return core.Order{}, err
 	}
 
+	candle := p.lastCandle[pair]
+	quantity := p.fillQuantity(size, candle)
+	price, slippage := p.fillPrice(side, quantity, candle.Close, candle)
+
+	// Delayed orders fill on the next candles
+	if p.latency != nil {
+		quantity, slippage = 0, 0
+	}
+
+	// Delayed orders and orders above the participation cap must be affordable in full, the rest fills on the next candles
+	if quantity < size {
+		if err := p.checkFunds(side, pair, size, price); err != nil {
+			return core.Order{}, err
+		}
+	}
+
+	// Check and apply funds (with immediate fill)
+	if quantity > 0 {
+		if err := p.validateFunds(side, pair, quantity, price, true); err != nil {
+			return core.Order{}, err
+		}
+	}
+
 	// Initialize volume if needed
 	if _, ok := p.volume[pair]; !ok {
 		p.volume[pair] = 0
 	}
 
 	// Register volume
-	p.volume[pair] += p.lastCandle[pair].Close * size
+	p.volume[pair] += price * quantity
 
-	// Create order (already filled)
+	// Create order (filled up to the participation cap)
 	order := core.Order{
 		ExchangeID: p.ID(),
-		CreatedAt:  p.lastCandle[pair].Time,
-		UpdatedAt:  p.lastCandle[pair].Time,
+		CreatedAt:  candle.Time,
+		UpdatedAt:  candle.Time,
 		Pair:       pair,
 		Side:       side,
 		Type:       core.OrderTypeMarket,
-		Status:     core.OrderStatusTypeFilled,
-		Price:      p.lastCandle[pair].Close,
+		Status:     core.OrderStatusTypeNew,
+		Price:      price,
 		Quantity:   size,
+		Slippage:   slippage,
+	}
+	if quantity > 0 {
+		fillOrder(&order, quantity, candle.Time)
+		p.chargeFee(&order, quantity, price, false)
 	}
 
 	// Add order to the list
@@ -937,6 +1536,22 @@ func (p *PaperWallet) CreateOrderMarket(ctx context.Context, side core.SideType,
 	return order, nil
 }
 
+// checkFunds verifies if there are sufficient funds for an order without changing balances
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) checkFunds(side core.SideType, pair string, amount, value float64) error {
+	assets := p.assets
+	defer func() {
+		p.assets = assets
+	}()
+
+	p.assets = make(map[string]*assetInfo, len(assets))
+	for asset, info := range assets {
+		balance := *info
+		p.assets[asset] = &balance
+	}
+	return p.validateFunds(side, pair, amount, value, false)
+}
+
 // CreateOrderStop creates a stop order
 func (p *PaperWallet) CreateOrderStop(_ context.Context, pair string, size float64, limit float64) (core.Order, error) {
 	if size == 0 {
@@ -946,6 +1561,10 @@ func (p *PaperWallet) CreateOrderStop(_ context.Context, pair string, size float
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	if err := p.rejectOrderLocked(pair, size); err != nil {
+		return core.Order{}, err
+	}
+
 	// Check available funds
 	err := p.validateFunds(core.SideTypeSell, pair, size, limit, false)
 	if err != nil {
@@ -987,7 +1606,8 @@ func (p *PaperWallet) CreateOrderMarketQuote(
 		return core.Order{}, err
 	}
 
-	price := p.lastCandle[pair].Close
+	// Size the order by the price after slippage
+	price, _ := p.fillPrice(side, quoteQuantity/p.lastCandle[pair].Close, p.lastCandle[pair].Close, p.lastCandle[pair])
 	quantity := common.AmountToLotSize(info.StepSize, info.BaseAssetPrecision, quoteQuantity/price)
 
 	// Unlock before calling CreateOrderMarket to avoid deadlock
@@ -1005,21 +1625,7 @@ func (p *PaperWallet) Cancel(_ context.Context, order core.Order) error {
 		if o.ExchangeID == order.ExchangeID {
 			// Mark order as canceled
 			p.orders[i].Status = core.OrderStatusTypeCanceled
-
-			// Release locked funds
-			asset, quote := SplitAssetQuote(o.Pair)
-
-			// Case 1: We have a long position and this is a sell order
-			if p.assets[asset].Lock > 0 && o.Side == core.SideTypeSell {
-				p.assets[asset].Free += o.Quantity
-				p.assets[asset].Lock -= o.Quantity
-			} else if p.assets[asset].Lock == 0 {
-				// Case 2: We don't have a long position
-				amount := order.Price * order.Quantity
-				p.assets[quote].Free += amount
-				p.assets[quote].Lock -= amount
-			}
-
+			p.releaseFundsLocked(o)
 			return nil
 		}
 	}
@@ -1027,6 +1633,29 @@ func (p *PaperWallet) Cancel(_ context.Context, order core.Order) error {
 	return errors.New("order not found")
 }
 
+// releaseFundsLocked releases the funds locked for the remaining quantity of an order
+// Note: This function assumes the mutex is already locked by the caller
+func (p *PaperWallet) releaseFundsLocked(o core.Order) {
+	// Market and futures orders don't lock funds
+	if o.Type == core.OrderTypeMarket || p.futures {
+		return
+	}
+
+	asset, quote := SplitAssetQuote(o.Pair)
+	remaining := o.Quantity - o.ExecutedQuantity
+
+	// Case 1: We have a long position and this is a sell order
+	if p.assets[asset].Lock > 0 && o.Side == core.SideTypeSell {
+		p.assets[asset].Free += remaining
+		p.assets[asset].Lock -= remaining
+	} else if p.assets[asset].Lock == 0 {
+		// Case 2: We don't have a long position
+		amount := o.Price * remaining
+		p.assets[quote].Free += amount
+		p.assets[quote].Lock -= amount
+	}
+}
+
 // Order returns a specific order
 func (p *PaperWallet) Order(_ context.Context, _ string, id int64) (core.Order, error) {
 	p.mu.RLock()
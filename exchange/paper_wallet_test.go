@@ -453,6 +453,409 @@ func TestPaperWallet_CreateOrderStop(t *testing.T) {
 	})
 }
 
+func TestPaperWallet_Slippage(t *testing.T) {
+	t.Run("market orders", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 1000), WithPaperSlippage(NewFixedSlippage(100)))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 100})
+
+		order, err := wallet.CreateOrderMarket(context.Background(), core.SideTypeBuy, "BTCUSDT", 2)
+		require.NoError(t, err)
+		require.InDelta(t, 101.0, order.Price, 1e-9)
+		require.InDelta(t, 2.0, order.Slippage, 1e-9)
+		require.InDelta(t, 798.0, wallet.assets["USDT"].Free, 1e-9)
+
+		order, err = wallet.CreateOrderMarket(context.Background(), core.SideTypeSell, "BTCUSDT", 2)
+		require.NoError(t, err)
+		require.InDelta(t, 99.0, order.Price, 1e-9)
+		require.InDelta(t, 2.0, order.Slippage, 1e-9)
+		require.InDelta(t, 996.0, wallet.assets["USDT"].Free, 1e-9)
+	})
+
+	t.Run("quote sized market order", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 101), WithPaperSlippage(NewFixedSlippage(100)))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 100})
+
+		order, err := wallet.CreateOrderMarketQuote(context.Background(), core.SideTypeBuy, "BTCUSDT", 101)
+		require.NoError(t, err)
+		require.InDelta(t, 1.0, order.Quantity, 1e-8)
+	})
+
+	t.Run("stop orders", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("BTC", 1), WithPaperSlippage(NewFixedSlippage(100)))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 100, High: 100, Low: 100})
+
+		_, err := wallet.CreateOrderStop(context.Background(), "BTCUSDT", 1, 50)
+		require.NoError(t, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 45, High: 55, Low: 40})
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[0].Status)
+		require.InDelta(t, 49.5, wallet.orders[0].Price, 1e-9)
+		require.InDelta(t, 0.5, wallet.orders[0].Slippage, 1e-9)
+		require.InDelta(t, 49.5, wallet.assets["USDT"].Free, 1e-9)
+	})
+
+	t.Run("limit orders", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 100), WithPaperSlippage(NewFixedSlippage(100)))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 110, High: 110, Low: 110})
+
+		_, err := wallet.CreateOrderLimit(context.Background(), core.SideTypeBuy, "BTCUSDT", 1, 100)
+		require.NoError(t, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 95, High: 105, Low: 90})
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[0].Status)
+		require.Equal(t, 100.0, wallet.orders[0].Price)
+		require.Zero(t, wallet.orders[0].Slippage)
+	})
+}
+
+func TestPaperWallet_Fees(t *testing.T) {
+	t.Run("market orders pay the taker fee", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 1000), WithPaperFee(0.001, 0.002))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 100})
+
+		order, err := wallet.CreateOrderMarket(context.Background(), core.SideTypeBuy, "BTCUSDT", 2)
+		require.NoError(t, err)
+		require.InDelta(t, 0.004, order.Fee, 1e-12)
+		require.Equal(t, "BTC", order.FeeAsset)
+		require.InDelta(t, 1.996, wallet.assets["BTC"].Free, 1e-12)
+		require.InDelta(t, 800.0, wallet.assets["USDT"].Free, 1e-9)
+
+		order, err = wallet.CreateOrderMarket(context.Background(), core.SideTypeSell, "BTCUSDT", 1.996)
+		require.NoError(t, err)
+		require.InDelta(t, 0.3992, order.Fee, 1e-12)
+		require.Equal(t, "USDT", order.FeeAsset)
+		require.InDelta(t, 0.0, wallet.assets["BTC"].Free, 1e-12)
+		require.InDelta(t, 999.2008, wallet.assets["USDT"].Free, 1e-9)
+	})
+
+	t.Run("limit orders pay the maker fee", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 100), WithPaperFee(0.001, 0.002))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 110, High: 110, Low: 110})
+
+		_, err := wallet.CreateOrderLimit(context.Background(), core.SideTypeBuy, "BTCUSDT", 1, 100)
+		require.NoError(t, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 95, High: 105, Low: 90})
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[0].Status)
+		require.InDelta(t, 0.001, wallet.orders[0].Fee, 1e-12)
+		require.InDelta(t, 0.999, wallet.assets["BTC"].Free, 1e-12)
+
+		_, err = wallet.CreateOrderLimit(context.Background(), core.SideTypeSell, "BTCUSDT", 0.999, 120)
+		require.NoError(t, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 115, High: 125, Low: 110})
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[1].Status)
+		require.InDelta(t, 0.11988, wallet.orders[1].Fee, 1e-12)
+		require.InDelta(t, 119.76012, wallet.assets["USDT"].Free, 1e-9)
+	})
+
+	t.Run("stop orders pay the taker fee", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("BTC", 1), WithPaperFee(0.001, 0.002))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 100, High: 100, Low: 100})
+
+		_, err := wallet.CreateOrderStop(context.Background(), "BTCUSDT", 1, 50)
+		require.NoError(t, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 45, High: 55, Low: 40})
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[0].Status)
+		require.InDelta(t, 0.1, wallet.orders[0].Fee, 1e-12)
+		require.InDelta(t, 49.9, wallet.assets["USDT"].Free, 1e-9)
+	})
+}
+
+func TestPaperWallet_Participation(t *testing.T) {
+	t.Run("limit orders", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 1000), WithPaperParticipation(0.1))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 110, Volume: 40})
+
+		_, err := wallet.CreateOrderLimit(context.Background(), core.SideTypeBuy, "BTCUSDT", 10, 100)
+		require.NoError(t, err)
+		require.Equal(t, 1000.0, wallet.assets["USDT"].Lock)
+
+		expected := []struct {
+			status   core.OrderStatusType
+			executed float64
+		}{
+			{core.OrderStatusTypePartiallyFilled, 4},
+			{core.OrderStatusTypePartiallyFilled, 8},
+			{core.OrderStatusTypeFilled, 10},
+		}
+		for _, step := range expected {
+			wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 95, Volume: 40})
+			require.Equal(t, step.status, wallet.orders[0].Status)
+			require.InDelta(t, step.executed, wallet.orders[0].ExecutedQuantity, 1e-12)
+			require.InDelta(t, step.executed, wallet.assets["BTC"].Free, 1e-12)
+			require.InDelta(t, 1000-step.executed*100, wallet.assets["USDT"].Lock, 1e-9)
+		}
+		require.Equal(t, 100.0, wallet.orders[0].Price)
+	})
+
+	t.Run("market orders", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 2000), WithPaperParticipation(0.1))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 100, Volume: 40})
+
+		order, err := wallet.CreateOrderMarket(context.Background(), core.SideTypeBuy, "BTCUSDT", 10)
+		require.NoError(t, err)
+		require.Equal(t, core.OrderStatusTypePartiallyFilled, order.Status)
+		require.Equal(t, 4.0, order.ExecutedQuantity)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 110, Volume: 40})
+		require.Equal(t, core.OrderStatusTypePartiallyFilled, wallet.orders[0].Status)
+		require.InDelta(t, 105.0, wallet.orders[0].Price, 1e-9)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 120, Volume: 40})
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[0].Status)
+		require.InDelta(t, 108.0, wallet.orders[0].Price, 1e-9)
+		require.InDelta(t, 10.0, wallet.assets["BTC"].Free, 1e-12)
+		require.InDelta(t, 920.0, wallet.assets["USDT"].Free, 1e-9)
+	})
+
+	t.Run("market orders must be affordable in full", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 500), WithPaperParticipation(0.1))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 100, Volume: 40})
+
+		_, err := wallet.CreateOrderMarket(context.Background(), core.SideTypeBuy, "BTCUSDT", 10)
+		require.Equal(t, &OrderError{Err: ErrInsufficientFunds, Pair: "BTCUSDT", Quantity: 10}, err)
+		require.Equal(t, 500.0, wallet.assets["USDT"].Free)
+		require.Empty(t, wallet.orders)
+	})
+
+	t.Run("cancel releases the remaining quantity", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 1000), WithPaperParticipation(0.1))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 110, Volume: 40})
+
+		order, err := wallet.CreateOrderLimit(context.Background(), core.SideTypeBuy, "BTCUSDT", 10, 100)
+		require.NoError(t, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 95, Volume: 40})
+		require.NoError(t, wallet.Cancel(context.Background(), order))
+		require.Equal(t, core.OrderStatusTypeCanceled, wallet.orders[0].Status)
+		require.InDelta(t, 4.0, wallet.orders[0].ExecutedQuantity, 1e-12)
+		require.InDelta(t, 600.0, wallet.assets["USDT"].Free, 1e-9)
+		require.InDelta(t, 0.0, wallet.assets["USDT"].Lock, 1e-9)
+	})
+}
+
+func TestPaperWallet_Intrabar(t *testing.T) {
+	// newWallet creates a wallet holding 1 BTC protected by a sell OCO with target 110 and stop 90
+	newWallet := func(t *testing.T, model IntrabarModel) *PaperWallet {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("BTC", 1), WithPaperIntrabar(model))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Open: 100, High: 100, Low: 100, Close: 100})
+
+		_, err := wallet.CreateOrderOCO(context.Background(), core.SideTypeSell, "BTCUSDT", 1, 110, 90, 89)
+		require.NoError(t, err)
+		return wallet
+	}
+
+	candle := core.Candle{Pair: "BTCUSDT", Open: 100, High: 115, Low: 85, Close: 100}
+
+	t.Run("high before low", func(t *testing.T) {
+		wallet := newWallet(t, IntrabarOHLC)
+		wallet.OnCandle(candle)
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[0].Status)
+		require.Equal(t, core.OrderStatusTypeCanceled, wallet.orders[1].Status)
+		require.Equal(t, 110.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 0.0, wallet.assets["BTC"].Lock)
+	})
+
+	t.Run("worst case fills the stop first", func(t *testing.T) {
+		wallet := newWallet(t, IntrabarWorstCase)
+		wallet.OnCandle(candle)
+		require.Equal(t, core.OrderStatusTypeCanceled, wallet.orders[0].Status)
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[1].Status)
+		require.Equal(t, 90.0, wallet.orders[1].Price)
+		require.Equal(t, 90.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 0.0, wallet.assets["BTC"].Lock)
+	})
+
+	t.Run("stop fills at the open of a gap", func(t *testing.T) {
+		wallet := newWallet(t, IntrabarOHLC)
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Open: 80, High: 82, Low: 75, Close: 78})
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[1].Status)
+		require.Equal(t, 80.0, wallet.orders[1].Price)
+		require.Equal(t, 80.0, wallet.assets["USDT"].Free)
+	})
+
+	t.Run("lower timeframe feed", func(t *testing.T) {
+		// the low is reached in the first half hour, before the high
+		feeder := &listFeeder{candles: []core.Candle{
+			{Pair: "BTCUSDT", Open: 100, High: 101, Low: 85, Close: 90},
+			{Pair: "BTCUSDT", Time: time.Unix(1800, 0), Open: 90, High: 115, Low: 89, Close: 100},
+		}}
+		model, err := NewFeedIntrabar(feeder, "30m", "1h", IntrabarOHLC)
+		require.NoError(t, err)
+
+		wallet := newWallet(t, model)
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: time.Unix(0, 0), Open: 100, High: 115, Low: 85, Close: 100})
+		require.Equal(t, core.OrderStatusTypeCanceled, wallet.orders[0].Status)
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[1].Status)
+		require.Equal(t, 90.0, wallet.assets["USDT"].Free)
+	})
+
+	t.Run("buy limits fill when the low reaches the price", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 100), WithPaperIntrabar(IntrabarOHLC))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Open: 100, High: 100, Low: 100, Close: 100})
+
+		_, err := wallet.CreateOrderLimit(context.Background(), core.SideTypeBuy, "BTCUSDT", 1, 95)
+		require.NoError(t, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Open: 100, High: 102, Low: 94, Close: 101})
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[0].Status)
+		require.Equal(t, 95.0, wallet.orders[0].Price)
+		require.Equal(t, 1.0, wallet.assets["BTC"].Free)
+		require.Equal(t, 5.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 0.0, wallet.assets["USDT"].Lock)
+	})
+
+	t.Run("buy stop releases the funds locked by its group", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 1000), WithPaperIntrabar(IntrabarWorstCase))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Open: 100, High: 100, Low: 100, Close: 100})
+
+		_, err := wallet.CreateOrderOCO(context.Background(), core.SideTypeBuy, "BTCUSDT", 1, 90, 110, 111)
+		require.NoError(t, err)
+		require.Equal(t, 90.0, wallet.assets["USDT"].Lock)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Open: 100, High: 112, Low: 88, Close: 100})
+		require.Equal(t, core.OrderStatusTypeCanceled, wallet.orders[0].Status)
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[1].Status)
+		require.Equal(t, 110.0, wallet.orders[1].Price)
+		require.Equal(t, 1.0, wallet.assets["BTC"].Free)
+		require.Equal(t, 890.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 0.0, wallet.assets["USDT"].Lock)
+	})
+}
+
+func TestPaperWallet_Latency(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hour := func(h int) time.Time { return start.Add(time.Duration(h) * time.Hour) }
+
+	t.Run("market orders fill at the next open", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 1000), WithPaperLatency(NextOpenLatency))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(0), Close: 100})
+
+		order, err := wallet.CreateOrderMarket(context.Background(), core.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+		require.Equal(t, core.OrderStatusTypeNew, order.Status)
+		require.Equal(t, 1000.0, wallet.assets["USDT"].Free)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(1), Open: 102, Close: 105})
+		require.Equal(t, core.OrderStatusTypeFilled, wallet.orders[0].Status)
+		require.Equal(t, 102.0, wallet.orders[0].Price)
+		require.Equal(t, hour(1), wallet.orders[0].UpdatedAt)
+		require.Equal(t, 1.0, wallet.assets["BTC"].Free)
+		require.Equal(t, 898.0, wallet.assets["USDT"].Free)
+	})
+
+	t.Run("delayed market orders must be affordable", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 50), WithPaperLatency(NextOpenLatency))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(0), Close: 100})
+
+		_, err := wallet.CreateOrderMarket(context.Background(), core.SideTypeBuy, "BTCUSDT", 1)
+		require.Equal(t, &OrderError{Err: ErrInsufficientFunds, Pair: "BTCUSDT", Quantity: 1}, err)
+		require.Empty(t, wallet.orders)
+	})
+
+	t.Run("timeout expires open orders", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 1000), WithPaperTimeout(time.Hour))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(0), Close: 100})
+
+		_, err := wallet.CreateOrderLimit(context.Background(), core.SideTypeBuy, "BTCUSDT", 1, 90)
+		require.NoError(t, err)
+		require.Equal(t, 90.0, wallet.assets["USDT"].Lock)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(1), Close: 100})
+		require.Equal(t, core.OrderStatusTypeNew, wallet.orders[0].Status)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(2), Close: 80})
+		require.Equal(t, core.OrderStatusTypeExpired, wallet.orders[0].Status)
+		require.Equal(t, hour(2), wallet.orders[0].UpdatedAt)
+		require.Equal(t, 1000.0, wallet.assets["USDT"].Free)
+		require.Equal(t, 0.0, wallet.assets["USDT"].Lock)
+		require.Empty(t, wallet.sentAt)
+	})
+
+	t.Run("timeout expires both legs of an OCO", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("BTC", 1), WithPaperTimeout(time.Hour))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(0), Close: 100})
+
+		_, err := wallet.CreateOrderOCO(context.Background(), core.SideTypeSell, "BTCUSDT", 1, 110, 90, 89)
+		require.NoError(t, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(1), High: 100, Low: 100, Close: 100})
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(2), High: 100, Low: 100, Close: 100})
+		require.Equal(t, core.OrderStatusTypeExpired, wallet.orders[0].Status)
+		require.Equal(t, core.OrderStatusTypeExpired, wallet.orders[1].Status)
+		require.Equal(t, 1.0, wallet.assets["BTC"].Free)
+		require.Equal(t, 0.0, wallet.assets["BTC"].Lock)
+	})
+
+	t.Run("timeout expires market orders delayed beyond it", func(t *testing.T) {
+		model, err := NewFeedLatency(2*time.Hour, &listFeeder{}, "1m", "1h")
+		require.NoError(t, err)
+
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 1000), WithPaperLatency(model), WithPaperTimeout(time.Hour))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(0), Close: 100})
+
+		_, err = wallet.CreateOrderMarket(context.Background(), core.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+
+		for h := 1; h <= 3; h++ {
+			wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(h), Open: 100, Close: 100})
+		}
+		require.Equal(t, core.OrderStatusTypeExpired, wallet.orders[0].Status)
+		require.Equal(t, hour(2), wallet.orders[0].UpdatedAt)
+		require.Equal(t, 1000.0, wallet.assets["USDT"].Free)
+	})
+
+	t.Run("rejection", func(t *testing.T) {
+		wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+			WithPaperAsset("USDT", 1000), WithPaperRejection(1, 1))
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(0), Close: 100})
+
+		_, err := wallet.CreateOrderLimit(context.Background(), core.SideTypeBuy, "BTCUSDT", 1, 90)
+		require.Equal(t, &OrderError{Err: ErrOrderRejected, Pair: "BTCUSDT", Quantity: 1}, err)
+		require.Empty(t, wallet.orders)
+		require.Equal(t, 0.0, wallet.assets["USDT"].Lock)
+
+		// rejections are reproducible with the same seed
+		rejections := func() []bool {
+			wallet := NewPaperWallet(context.Background(), "USDT", getLog(),
+				WithPaperAsset("USDT", 1000), WithPaperRejection(0.5, 42))
+			wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: hour(0), Close: 1})
+
+			result := make([]bool, 0, 20)
+			for i := 0; i < 20; i++ {
+				_, err := wallet.CreateOrderMarket(context.Background(), core.SideTypeBuy, "BTCUSDT", 1)
+				result = append(result, err != nil)
+			}
+			return result
+		}
+		first := rejections()
+		require.Contains(t, first, true)
+		require.Contains(t, first, false)
+		require.Equal(t, first, rejections())
+	})
+}
+
 func TestUpdateAveragePrice(t *testing.T) {
 	t.Run("long", func(t *testing.T) {
 		wallet := NewPaperWallet(
@@ -613,3 +1016,20 @@ func TestUpdateAveragePrice(t *testing.T) {
 	})
 
 }
+
+func TestPaperWallet_BenchmarkCurve(t *testing.T) {
+	wallet := NewPaperWallet(context.Background(), "USDT", getLog(), WithPaperAsset("USDT", 100))
+	start := time.Now().Truncate(time.Hour)
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: start, Close: 100, Complete: true})
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), Close: 110, Complete: true})
+	wallet.OnCandle(core.Candle{Pair: "ETHUSDT", Time: start.Add(time.Hour), Close: 10, Complete: true})
+	wallet.OnCandle(core.Candle{Pair: "ETHUSDT", Time: start.Add(2 * time.Hour), Close: 5, Complete: true})
+
+	curve := wallet.BenchmarkCurve()
+	require.Len(t, curve, 3)
+	require.Equal(t, start, curve[0].Time)
+	require.InDelta(t, 100, curve[0].Value, 1e-9)
+	require.InDelta(t, 105, curve[1].Value, 1e-9)
+	require.InDelta(t, 80, curve[2].Value, 1e-9)
+}
@@ -0,0 +1,52 @@
+package exchange
+
+import (
+	"math"
+
+	"github.com/raykavin/backnrun/core"
+)
+
+// SlippageModel estimates the adverse price movement of a simulated fill
+type SlippageModel interface {
+	// Slippage returns the price movement against the order as a fraction of the price,
+	// e.g. 0.001 fills a buy at 0.1% above the price
+	Slippage(side core.SideType, quantity, price float64, candle core.Candle) float64
+}
+
+// SlippageFunc adapts a function to the SlippageModel interface
+type SlippageFunc func(side core.SideType, quantity, price float64, candle core.Candle) float64
+
+// Slippage calls the function
+func (f SlippageFunc) Slippage(side core.SideType, quantity, price float64, candle core.Candle) float64 {
+	return f(side, quantity, price, candle)
+}
+
+// NewFixedSlippage creates a model that moves every fill by a fixed number of basis points
+func NewFixedSlippage(bps float64) SlippageModel {
+	return SlippageFunc(func(_ core.SideType, _, _ float64, _ core.Candle) float64 {
+		return bps / 10000
+	})
+}
+
+// NewVolatilitySlippage creates a model proportional to the range of the fill candle,
+// e.g. a factor of 0.1 moves fills by 10% of the candle high-low range
+func NewVolatilitySlippage(factor float64) SlippageModel {
+	return SlippageFunc(func(_ core.SideType, _, _ float64, candle core.Candle) float64 {
+		if candle.Close <= 0 {
+			return 0
+		}
+		return factor * (candle.High - candle.Low) / candle.Close
+	})
+}
+
+// NewVolumeSlippage creates a square-root market impact model, impact * sqrt(quantity / volume),
+// so orders trading a larger share of the candle volume move the price further.
+// Candles without volume don't add slippage.
+func NewVolumeSlippage(impact float64) SlippageModel {
+	return SlippageFunc(func(_ core.SideType, quantity, _ float64, candle core.Candle) float64 {
+		if candle.Volume <= 0 {
+			return 0
+		}
+		return impact * math.Sqrt(quantity/candle.Volume)
+	})
+}
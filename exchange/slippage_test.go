@@ -0,0 +1,31 @@
+package exchange
+
+import (
+	"testing"
+
+	"github.com/raykavin/backnrun/core"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlippageModels(t *testing.T) {
+	candle := core.Candle{Pair: "BTCUSDT", Close: 100, High: 110, Low: 90, Volume: 400}
+
+	t.Run("fixed", func(t *testing.T) {
+		model := NewFixedSlippage(25)
+		require.InDelta(t, 0.0025, model.Slippage(core.SideTypeBuy, 1, 100, candle), 1e-12)
+	})
+
+	t.Run("volatility", func(t *testing.T) {
+		model := NewVolatilitySlippage(0.1)
+		require.InDelta(t, 0.02, model.Slippage(core.SideTypeSell, 1, 100, candle), 1e-12)
+		require.Zero(t, model.Slippage(core.SideTypeSell, 1, 100, core.Candle{}))
+	})
+
+	t.Run("volume", func(t *testing.T) {
+		model := NewVolumeSlippage(0.01)
+		require.InDelta(t, 0.0005, model.Slippage(core.SideTypeBuy, 1, 100, candle), 1e-12)
+		require.InDelta(t, 0.001, model.Slippage(core.SideTypeBuy, 4, 100, candle), 1e-12)
+		require.Zero(t, model.Slippage(core.SideTypeBuy, 1, 100, core.Candle{Close: 100}))
+	})
+}
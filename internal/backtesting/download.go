@@ -75,6 +75,64 @@ func (d Downloader) Download(ctx context.Context, pair, timeframe, outputPath st
 	}
 	defer recordFile.Close()
 
+	// Setup CSV writer
+	writer := csv.NewWriter(recordFile)
+	assetInfo, err := d.exchange.AssetsInfo(pair)
+	if err != nil {
+		return err
+	}
+
+	// Write CSV headers
+	if err := writer.Write(csvHeaders); err != nil {
+		return err
+	}
+
+	// Download and write candle data
+	err = d.download(ctx, pair, timeframe, func(candles []core.Candle) error {
+		return writeCandles(writer, candles, assetInfo.QuotePrecision)
+	}, options...)
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	d.log.Info("Done!")
+	return writer.Error()
+}
+
+// DownloadToStorage fetches candle data from the exchange and saves it to a candle storage,
+// replacing the candles already stored for the same times. Candles still open are skipped,
+// since a cached feed never fetches a stored candle again.
+func (d Downloader) DownloadToStorage(ctx context.Context, pair, timeframe string, storage core.CandleStorage,
+	options ...Option) error {
+
+	interval, err := str2duration.ParseDuration(timeframe)
+	if err != nil {
+		return err
+	}
+
+	err = d.download(ctx, pair, timeframe, func(candles []core.Candle) error {
+		now := time.Now()
+		closed := make([]core.Candle, 0, len(candles))
+		for _, candle := range candles {
+			if !candle.Time.Add(interval).After(now) {
+				closed = append(closed, candle)
+			}
+		}
+		return storage.SaveCandles(ctx, timeframe, closed)
+	}, options...)
+	if err != nil {
+		return err
+	}
+
+	d.log.Info("Done!")
+	return nil
+}
+
+// download fetches the candles of the configured period in batches, passing each batch to save
+func (d Downloader) download(ctx context.Context, pair, timeframe string, save func([]core.Candle) error,
+	options ...Option) error {
+
 	// Apply download parameters
 	parameters := initializeParameters()
 	for _, option := range options {
@@ -91,22 +149,10 @@ func (d Downloader) Download(ctx context.Context, pair, timeframe, outputPath st
 
 	d.log.Infof("Downloading %d candles of %s for %s", candleCount, timeframe, pair)
 
-	// Setup CSV writer
-	writer := csv.NewWriter(recordFile)
-	assetInfo, err := d.exchange.AssetsInfo(pair)
-	if err != nil {
-		return err
-	}
-
 	// Setup progress tracking
 	progressBar := progressbar.Default(int64(candleCount))
 
-	// Write CSV headers
-	if err := writer.Write(csvHeaders); err != nil {
-		return err
-	}
-
-	// Download and write candle data
+	// Download and save candle data
 	missingCandles, err := d.downloadCandleBatches(
 		ctx,
 		pair,
@@ -114,8 +160,7 @@ func (d Downloader) Download(ctx context.Context, pair, timeframe, outputPath st
 		parameters.Start,
 		parameters.End,
 		interval,
-		assetInfo.QuotePrecision,
-		writer,
+		save,
 		progressBar,
 	)
 	if err != nil {
@@ -130,9 +175,7 @@ func (d Downloader) Download(ctx context.Context, pair, timeframe, outputPath st
 		d.log.Warnf("%d missing candles", missingCandles)
 	}
 
-	writer.Flush()
-	d.log.Info("Done!")
-	return writer.Error()
+	return nil
 }
 
 // initializeParameters creates default parameters for the last month
@@ -168,7 +211,7 @@ func normalizeTimeParameters(parameters *Parameters) {
 	}
 }
 
-// downloadCandleBatches downloads candles in batches and writes them to CSV
+// downloadCandleBatches downloads candles in batches and saves them
 func (d Downloader) downloadCandleBatches(
 	ctx context.Context,
 	pair string,
@@ -176,8 +219,7 @@ func (d Downloader) downloadCandleBatches(
 	start time.Time,
 	end time.Time,
 	interval time.Duration,
-	precision int,
-	writer *csv.Writer,
+	save func([]core.Candle) error,
 	progressBar *progressbar.ProgressBar,
 ) (int, error) {
 	missingCandles := 0
@@ -191,7 +233,7 @@ func (d Downloader) downloadCandleBatches(
 			return missingCandles, err
 		}
 
-		if err := writeCandles(writer, candles, precision); err != nil {
+		if err := save(candles); err != nil {
 			return missingCandles, err
 		}
 
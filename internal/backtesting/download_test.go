@@ -9,6 +9,7 @@ import (
 	"github.com/raykavin/backnrun/core"
 	"github.com/raykavin/backnrun/exchange"
 	"github.com/raykavin/backnrun/logger/zerolog"
+	"github.com/raykavin/backnrun/storage"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -108,3 +109,33 @@ func TestDownloader_download(t *testing.T) {
 		require.Len(t, csvFeed.CandlePairTimeFrame["BTCUSDT--1d"], 14)
 	})
 }
+
+// dailyFeeder serves a daily candle for every day in the requested period
+type dailyFeeder struct {
+	core.Feeder
+}
+
+func (dailyFeeder) CandlesByPeriod(_ context.Context, pair, _ string, start, end time.Time) ([]core.Candle, error) {
+	var candles []core.Candle
+	for t := start; !t.After(end); t = t.Add(24 * time.Hour) {
+		candles = append(candles, core.Candle{Pair: pair, Time: t, Open: 1, Close: 1, Low: 1, High: 1, Complete: true})
+	}
+	return candles, nil
+}
+
+func TestDownloader_DownloadToStorage(t *testing.T) {
+	ctx := context.Background()
+	db, err := storage.FromMemory()
+	require.NoError(t, err)
+
+	today := time.Now().UTC().Truncate(24 * time.Hour)
+	downloader := NewDownloader(dailyFeeder{}, getLog())
+	err = downloader.DownloadToStorage(ctx, "BTCUSDT", "1d", db, WithInterval(today.AddDate(0, 0, -3), today))
+	require.NoError(t, err)
+
+	// the candle still open today is not stored
+	candles, err := db.Candles(ctx, "BTCUSDT", "1d", today.AddDate(0, 0, -3), today)
+	require.NoError(t, err)
+	require.Len(t, candles, 3)
+	assert.Equal(t, today.AddDate(0, 0, -1), candles[2].Time)
+}
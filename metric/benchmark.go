@@ -0,0 +1,125 @@
+package metric
+
+import (
+	"math"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// BenchmarkMetrics represents the performance of an equity curve relative to a benchmark curve.
+// Ratios are annualized and zero when undefined, e.g. without benchmark volatility.
+type BenchmarkMetrics struct {
+	Return           float64 `json:"return"`            // Total return of the equity curve
+	BenchmarkReturn  float64 `json:"benchmark_return"`  // Total return of the benchmark curve
+	ExcessReturn     float64 `json:"excess_return"`     // Total return above the benchmark
+	Alpha            float64 `json:"alpha"`             // Annualized return not explained by the benchmark exposure
+	Beta             float64 `json:"beta"`              // Sensitivity of period returns to benchmark returns
+	Correlation      float64 `json:"correlation"`       // Correlation of period returns with benchmark returns
+	InformationRatio float64 `json:"information_ratio"` // Annualized mean active return over tracking error
+	UpCapture        float64 `json:"up_capture"`        // Mean return over mean benchmark return, in periods the benchmark rose
+	DownCapture      float64 `json:"down_capture"`      // Mean return over mean benchmark return, in periods the benchmark fell
+}
+
+// CalculateBenchmarkMetrics compares an equity curve with a benchmark curve over their common times.
+// Parameters:
+//   - points: The equity curve
+//   - benchmark: The benchmark curve, e.g. a buy-and-hold portfolio of the same candles
+//   - timeframe: The candle timeframe used to annualize returns, zero infers it from the points
+func CalculateBenchmarkMetrics(points, benchmark []EquityPoint, timeframe time.Duration) BenchmarkMetrics {
+	curve, benchmarkCurve := alignEquityPoints(MergeEquityPoints(points), MergeEquityPoints(benchmark))
+	if len(curve) < 2 {
+		return BenchmarkMetrics{}
+	}
+
+	if timeframe <= 0 {
+		timeframe = inferTimeframe(curve)
+	}
+	periodsPerYear := float64(year) / float64(timeframe)
+
+	var metrics BenchmarkMetrics
+
+	first, last := curve[0].Value, curve[len(curve)-1].Value
+	benchmarkFirst, benchmarkLast := benchmarkCurve[0].Value, benchmarkCurve[len(benchmarkCurve)-1].Value
+	if first > 0 {
+		metrics.Return = last/first - 1
+	}
+	if benchmarkFirst > 0 {
+		metrics.BenchmarkReturn = benchmarkLast/benchmarkFirst - 1
+	}
+	metrics.ExcessReturn = metrics.Return - metrics.BenchmarkReturn
+
+	// Period returns where both curves have a positive previous value
+	var returns, benchmarkReturns, active []float64
+	for i := 1; i < len(curve); i++ {
+		if curve[i-1].Value <= 0 || benchmarkCurve[i-1].Value <= 0 {
+			continue
+		}
+
+		value := curve[i].Value/curve[i-1].Value - 1
+		benchmarkValue := benchmarkCurve[i].Value/benchmarkCurve[i-1].Value - 1
+		returns = append(returns, value)
+		benchmarkReturns = append(benchmarkReturns, benchmarkValue)
+		active = append(active, value-benchmarkValue)
+	}
+
+	if len(returns) < 2 {
+		return metrics
+	}
+
+	if variance := stat.Variance(benchmarkReturns, nil); variance > 0 {
+		metrics.Beta = stat.Covariance(returns, benchmarkReturns, nil) / variance
+		metrics.Alpha = (stat.Mean(returns, nil) - metrics.Beta*stat.Mean(benchmarkReturns, nil)) * periodsPerYear
+
+		if stat.Variance(returns, nil) > 0 {
+			metrics.Correlation = stat.Correlation(returns, benchmarkReturns, nil)
+		}
+	}
+
+	if mean, stdDev := stat.MeanStdDev(active, nil); stdDev > 0 {
+		metrics.InformationRatio = mean / stdDev * math.Sqrt(periodsPerYear)
+	}
+
+	metrics.UpCapture = captureRatio(returns, benchmarkReturns, func(value float64) bool { return value > 0 })
+	metrics.DownCapture = captureRatio(returns, benchmarkReturns, func(value float64) bool { return value < 0 })
+
+	return metrics
+}
+
+// captureRatio returns the mean return over the mean benchmark return in the selected periods.
+func captureRatio(returns, benchmarkReturns []float64, selected func(float64) bool) float64 {
+	var sum, benchmarkSum float64
+	for i, benchmarkValue := range benchmarkReturns {
+		if selected(benchmarkValue) {
+			sum += returns[i]
+			benchmarkSum += benchmarkValue
+		}
+	}
+
+	if benchmarkSum == 0 {
+		return 0
+	}
+	return sum / benchmarkSum
+}
+
+// alignEquityPoints keeps the points of two sorted curves sharing the same times.
+func alignEquityPoints(a, b []EquityPoint) ([]EquityPoint, []EquityPoint) {
+	alignedA := make([]EquityPoint, 0, min(len(a), len(b)))
+	alignedB := make([]EquityPoint, 0, min(len(a), len(b)))
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i].Time.Before(b[j].Time):
+			i++
+		case b[j].Time.Before(a[i].Time):
+			j++
+		default:
+			alignedA = append(alignedA, a[i])
+			alignedB = append(alignedB, b[j])
+			i++
+			j++
+		}
+	}
+
+	return alignedA, alignedB
+}
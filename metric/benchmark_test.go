@@ -0,0 +1,63 @@
+package metric
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gonum.org/v1/gonum/stat"
+)
+
+func TestCalculateBenchmarkMetrics(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+	returns := []float64{0.1, -0.05, 0.02, -0.03, 0.04}
+
+	curve := func(scale float64) []EquityPoint {
+		points := []EquityPoint{{Time: start, Value: 100}}
+		for i, value := range returns {
+			last := points[len(points)-1].Value
+			points = append(points, EquityPoint{Time: start.Add(time.Duration(i+1) * day), Value: last * (1 + scale*value)})
+		}
+		return points
+	}
+
+	t.Run("same curve", func(t *testing.T) {
+		metrics := CalculateBenchmarkMetrics(curve(1), curve(1), day)
+
+		assert.InDelta(t, metrics.BenchmarkReturn, metrics.Return, 1e-12)
+		assert.InDelta(t, 0, metrics.ExcessReturn, 1e-12)
+		assert.InDelta(t, 1, metrics.Beta, 1e-9)
+		assert.InDelta(t, 1, metrics.Correlation, 1e-9)
+		assert.InDelta(t, 0, metrics.Alpha, 1e-9)
+		assert.Zero(t, metrics.InformationRatio)
+		assert.InDelta(t, 1, metrics.UpCapture, 1e-9)
+		assert.InDelta(t, 1, metrics.DownCapture, 1e-9)
+	})
+
+	t.Run("leveraged curve", func(t *testing.T) {
+		metrics := CalculateBenchmarkMetrics(curve(2), curve(1), day)
+
+		mean, stdDev := stat.MeanStdDev(returns, nil)
+		assert.Greater(t, metrics.ExcessReturn, 0.0)
+		assert.InDelta(t, 2, metrics.Beta, 1e-9)
+		assert.InDelta(t, 1, metrics.Correlation, 1e-9)
+		assert.InDelta(t, 0, metrics.Alpha, 1e-9)
+		assert.InDelta(t, mean/stdDev*math.Sqrt(365), metrics.InformationRatio, 1e-9)
+		assert.InDelta(t, 2, metrics.UpCapture, 1e-9)
+		assert.InDelta(t, 2, metrics.DownCapture, 1e-9)
+	})
+
+	t.Run("common times", func(t *testing.T) {
+		benchmark := curve(1)
+		metrics := CalculateBenchmarkMetrics(curve(1)[2:], benchmark, day)
+
+		assert.InDelta(t, benchmark[len(benchmark)-1].Value/benchmark[2].Value-1, metrics.BenchmarkReturn, 1e-12)
+		assert.InDelta(t, 0, metrics.ExcessReturn, 1e-12)
+	})
+
+	t.Run("no common times", func(t *testing.T) {
+		assert.Equal(t, BenchmarkMetrics{}, CalculateBenchmarkMetrics(curve(1)[:2], curve(1)[3:], day))
+	})
+}
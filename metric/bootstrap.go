@@ -9,10 +9,10 @@ import (
 
 // BootstrapInterval represents the confidence interval calculated by the bootstrap method.
 type BootstrapInterval struct {
-	Lower  float64 // Lower bound of the confidence interval
-	Upper  float64 // Upper bound of the confidence interval
-	StdDev float64 // Standard deviation of the bootstrap samples
-	Mean   float64 // Mean of the bootstrap samples
+	Lower  float64 `json:"lower"`   // Lower bound of the confidence interval
+	Upper  float64 `json:"upper"`   // Upper bound of the confidence interval
+	StdDev float64 `json:"std_dev"` // Standard deviation of the bootstrap samples
+	Mean   float64 `json:"mean"`    // Mean of the bootstrap samples
 }
 
 // Bootstrap calculates the confidence interval of a sample using the bootstrap method.
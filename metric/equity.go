@@ -0,0 +1,168 @@
+package metric
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// year is the duration used to annualize metrics, markets are assumed to trade every day
+const year = 365 * 24 * time.Hour
+
+// EquityPoint represents the value of a portfolio at a point in time.
+type EquityPoint struct {
+	Time     time.Time `json:"time"`      // Time of the valuation
+	Value    float64   `json:"value"`     // Total portfolio value in the quote currency
+	InMarket bool      `json:"in_market"` // Whether any position was open
+}
+
+// EquityMetrics represents the performance metrics calculated from an equity curve.
+// Ratios are annualized and zero when undefined, e.g. without volatility.
+type EquityMetrics struct {
+	AnnualReturn        float64       `json:"annual_return"`         // Compound annual growth rate (CAGR)
+	Volatility          float64       `json:"volatility"`            // Annualized standard deviation of period returns
+	SharpeRatio         float64       `json:"sharpe_ratio"`          // Annualized mean return over volatility, without risk-free rate
+	SortinoRatio        float64       `json:"sortino_ratio"`         // Annualized mean return over downside deviation
+	CalmarRatio         float64       `json:"calmar_ratio"`          // Annual return over maximum drawdown
+	MaxDrawdown         float64       `json:"max_drawdown"`          // Largest decline from an equity peak, as a fraction of the peak
+	UlcerIndex          float64       `json:"ulcer_index"`           // Root mean square of drawdowns, as a fraction of the peak
+	TimeInMarket        float64       `json:"time_in_market"`        // Fraction of periods with an open position
+	AvgDrawdownDuration time.Duration `json:"avg_drawdown_duration"` // Average time from an equity peak to its recovery
+}
+
+// CalculateEquityMetrics calculates performance metrics from an equity curve.
+// Parameters:
+//   - points: The equity curve, points sharing a time are merged keeping the last value
+//   - timeframe: The candle timeframe used to annualize returns, zero infers it from the points
+func CalculateEquityMetrics(points []EquityPoint, timeframe time.Duration) EquityMetrics {
+	curve := MergeEquityPoints(points)
+	if len(curve) < 2 {
+		return EquityMetrics{}
+	}
+
+	if timeframe <= 0 {
+		timeframe = inferTimeframe(curve)
+	}
+	periodsPerYear := float64(year) / float64(timeframe)
+
+	var metrics EquityMetrics
+
+	// Compound annual growth rate over the elapsed time
+	first, last := curve[0], curve[len(curve)-1]
+	elapsed := last.Time.Sub(first.Time)
+	if first.Value > 0 && last.Value > 0 && elapsed > 0 {
+		metrics.AnnualReturn = math.Pow(last.Value/first.Value, float64(year)/float64(elapsed)) - 1
+	}
+
+	// Period returns and their deviations
+	returns := make([]float64, 0, len(curve)-1)
+	for i := 1; i < len(curve); i++ {
+		if curve[i-1].Value > 0 {
+			returns = append(returns, curve[i].Value/curve[i-1].Value-1)
+		}
+	}
+
+	if len(returns) > 1 {
+		mean, stdDev := stat.MeanStdDev(returns, nil)
+		metrics.Volatility = stdDev * math.Sqrt(periodsPerYear)
+		if stdDev > 0 {
+			metrics.SharpeRatio = mean / stdDev * math.Sqrt(periodsPerYear)
+		}
+
+		downside := 0.0
+		for _, value := range returns {
+			if value < 0 {
+				downside += value * value
+			}
+		}
+		if downside > 0 {
+			downsideDev := math.Sqrt(downside / float64(len(returns)))
+			metrics.SortinoRatio = mean / downsideDev * math.Sqrt(periodsPerYear)
+		}
+	}
+
+	// Drawdowns from the running peak
+	var (
+		peak          = curve[0].Value
+		peakTime      = curve[0].Time
+		squares       float64
+		inMarket      int
+		drawdownTotal time.Duration
+		drawdownCount int
+		inDrawdown    bool
+	)
+
+	for _, point := range curve {
+		if point.InMarket {
+			inMarket++
+		}
+
+		if point.Value >= peak {
+			if inDrawdown {
+				drawdownTotal += point.Time.Sub(peakTime)
+				drawdownCount++
+				inDrawdown = false
+			}
+			peak, peakTime = point.Value, point.Time
+			continue
+		}
+
+		inDrawdown = true
+		if peak > 0 {
+			drawdown := (peak - point.Value) / peak
+			metrics.MaxDrawdown = math.Max(metrics.MaxDrawdown, drawdown)
+			squares += drawdown * drawdown
+		}
+	}
+
+	// A drawdown still open at the end lasts until the last point
+	if inDrawdown {
+		drawdownTotal += last.Time.Sub(peakTime)
+		drawdownCount++
+	}
+
+	metrics.UlcerIndex = math.Sqrt(squares / float64(len(curve)))
+	metrics.TimeInMarket = float64(inMarket) / float64(len(curve))
+	if drawdownCount > 0 {
+		metrics.AvgDrawdownDuration = drawdownTotal / time.Duration(drawdownCount)
+	}
+	if metrics.MaxDrawdown > 0 {
+		metrics.CalmarRatio = metrics.AnnualReturn / metrics.MaxDrawdown
+	}
+
+	return metrics
+}
+
+// MergeEquityPoints sorts the points by time and merges points sharing a time, keeping the last value,
+// as a portfolio is valued once per pair on each candle.
+func MergeEquityPoints(points []EquityPoint) []EquityPoint {
+	sorted := make([]EquityPoint, len(points))
+	copy(sorted, points)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	merged := make([]EquityPoint, 0, len(sorted))
+	for _, point := range sorted {
+		if n := len(merged); n > 0 && merged[n-1].Time.Equal(point.Time) {
+			merged[n-1].Value = point.Value
+			merged[n-1].InMarket = merged[n-1].InMarket || point.InMarket
+			continue
+		}
+		merged = append(merged, point)
+	}
+
+	return merged
+}
+
+// inferTimeframe returns the median interval between consecutive points.
+func inferTimeframe(curve []EquityPoint) time.Duration {
+	intervals := make([]float64, 0, len(curve)-1)
+	for i := 1; i < len(curve); i++ {
+		intervals = append(intervals, float64(curve[i].Time.Sub(curve[i-1].Time)))
+	}
+	sort.Float64s(intervals)
+	return time.Duration(stat.Quantile(0.5, stat.Empirical, intervals, nil))
+}
@@ -0,0 +1,68 @@
+package metric
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateEquityMetrics(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	day := 24 * time.Hour
+
+	points := []EquityPoint{
+		{Time: start, Value: 100},
+		{Time: start.Add(day), Value: 105, InMarket: true},
+		// Valued again by a second pair on the same candle
+		{Time: start.Add(day), Value: 110, InMarket: true},
+		{Time: start.Add(2 * day), Value: 99, InMarket: true},
+		{Time: start.Add(3 * day), Value: 121, InMarket: true},
+		{Time: start.Add(4 * day), Value: 121},
+	}
+
+	t.Run("daily", func(t *testing.T) {
+		metrics := CalculateEquityMetrics(points, day)
+
+		assert.InDelta(t, 35823253.742, metrics.AnnualReturn, 1e-3)
+		assert.InDelta(t, 2.63429, metrics.Volatility, 1e-5)
+		assert.InDelta(t, 7.69761, metrics.SharpeRatio, 1e-5)
+		assert.InDelta(t, 21.22775, metrics.SortinoRatio, 1e-5)
+		assert.InDelta(t, 0.1, metrics.MaxDrawdown, 1e-9)
+		assert.InDelta(t, metrics.AnnualReturn/0.1, metrics.CalmarRatio, 1e-3)
+		assert.InDelta(t, 0.04472, metrics.UlcerIndex, 1e-5)
+		assert.InDelta(t, 0.6, metrics.TimeInMarket, 1e-9)
+		assert.Equal(t, 2*day, metrics.AvgDrawdownDuration)
+	})
+
+	t.Run("inferred timeframe", func(t *testing.T) {
+		require.Equal(t, CalculateEquityMetrics(points, day), CalculateEquityMetrics(points, 0))
+	})
+
+	t.Run("timeframe annualization", func(t *testing.T) {
+		daily := CalculateEquityMetrics(points, day)
+		hourly := CalculateEquityMetrics(points, time.Hour)
+
+		assert.InDelta(t, daily.SharpeRatio*math.Sqrt(24), hourly.SharpeRatio, 1e-9)
+		assert.Equal(t, daily.AnnualReturn, hourly.AnnualReturn)
+	})
+
+	t.Run("open drawdown", func(t *testing.T) {
+		metrics := CalculateEquityMetrics([]EquityPoint{
+			{Time: start, Value: 100},
+			{Time: start.Add(day), Value: 90},
+			{Time: start.Add(3 * day), Value: 95},
+		}, day)
+
+		assert.Equal(t, 3*day, metrics.AvgDrawdownDuration)
+		assert.Zero(t, metrics.TimeInMarket)
+		assert.Less(t, metrics.AnnualReturn, 0.0)
+		assert.Less(t, metrics.CalmarRatio, 0.0)
+	})
+
+	t.Run("not enough points", func(t *testing.T) {
+		assert.Equal(t, EquityMetrics{}, CalculateEquityMetrics(points[:1], day))
+	})
+}
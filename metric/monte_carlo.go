@@ -0,0 +1,226 @@
+package metric
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"gonum.org/v1/gonum/stat"
+)
+
+// MonteCarloMethod defines how trade sequences are generated in a Monte Carlo simulation.
+type MonteCarloMethod int
+
+const (
+	// MonteCarloResample draws trades with replacement, so each sequence may repeat or omit trades
+	MonteCarloResample MonteCarloMethod = iota
+	// MonteCarloShuffle reorders the original trades, so every sequence ends with the same equity
+	MonteCarloShuffle
+)
+
+// Default settings of the Monte Carlo simulation
+const (
+	DefaultMonteCarloSimulations = 10000
+	DefaultRuinThreshold         = 0.5
+)
+
+// MonteCarloOption defines an option function to configure a Monte Carlo simulation.
+type MonteCarloOption func(*monteCarloConfig)
+
+// monteCarloConfig holds the settings of a Monte Carlo simulation.
+type monteCarloConfig struct {
+	simulations   int
+	method        MonteCarloMethod
+	ruinThreshold float64
+	seed          int64
+}
+
+// WithSimulations sets the number of simulated trade sequences, zero uses the default
+func WithSimulations(n int) MonteCarloOption {
+	return func(config *monteCarloConfig) {
+		config.simulations = n
+	}
+}
+
+// WithMethod sets how trade sequences are generated
+func WithMethod(method MonteCarloMethod) MonteCarloOption {
+	return func(config *monteCarloConfig) {
+		config.method = method
+	}
+}
+
+// WithRuinThreshold sets the fraction of the initial equity whose loss is considered ruin,
+// e.g. 0.5 counts a sequence as ruined when equity falls to half of its initial value
+func WithRuinThreshold(threshold float64) MonteCarloOption {
+	return func(config *monteCarloConfig) {
+		config.ruinThreshold = threshold
+	}
+}
+
+// WithSeed sets the seed of the random generator, zero uses the current time
+func WithSeed(seed int64) MonteCarloOption {
+	return func(config *monteCarloConfig) {
+		config.seed = seed
+	}
+}
+
+// Distribution represents the simulated values of a measure.
+type Distribution struct {
+	Mean   float64 // Mean of the simulated values
+	StdDev float64 // Standard deviation of the simulated values
+	Min    float64 // Lowest simulated value
+	Max    float64 // Highest simulated value
+
+	values []float64
+}
+
+// newDistribution creates a distribution from simulated values, sorting them in place.
+func newDistribution(values []float64) Distribution {
+	if len(values) == 0 {
+		return Distribution{}
+	}
+
+	sort.Float64s(values)
+	mean, stdDev := stat.MeanStdDev(values, nil)
+
+	return Distribution{
+		Mean:   mean,
+		StdDev: stdDev,
+		Min:    values[0],
+		Max:    values[len(values)-1],
+		values: values,
+	}
+}
+
+// Percentile returns the value below which the given fraction of simulations fall (e.g., 0.95).
+func (d Distribution) Percentile(p float64) float64 {
+	if len(d.values) == 0 {
+		return 0
+	}
+	return stat.Quantile(p, stat.LinInterp, d.values, nil)
+}
+
+// MonteCarloResult represents the distributions calculated by a Monte Carlo simulation.
+type MonteCarloResult struct {
+	Simulations   int          // Number of simulated trade sequences
+	MaxDrawdown   Distribution // Maximum drawdown of each sequence, as a fraction of the equity peak
+	FinalEquity   Distribution // Equity at the end of each sequence
+	LosingStreak  Distribution // Longest run of consecutive losing trades of each sequence
+	RuinThreshold float64      // Fraction of the initial equity whose loss is considered ruin
+	RiskOfRuin    float64      // Fraction of sequences reaching the ruin threshold
+}
+
+// MonteCarlo simulates the equity path of trade sequences generated from the trade profits.
+// Parameters:
+//   - trades: The profit of each trade, in the quote currency
+//   - initialEquity: The equity before the first trade
+//   - options: Simulation settings, by default 10000 resampled sequences and a 50% ruin threshold
+func MonteCarlo(trades []float64, initialEquity float64, options ...MonteCarloOption) MonteCarloResult {
+	config := monteCarloConfig{
+		simulations:   DefaultMonteCarloSimulations,
+		method:        MonteCarloResample,
+		ruinThreshold: DefaultRuinThreshold,
+	}
+	for _, option := range options {
+		option(&config)
+	}
+
+	if config.simulations <= 0 {
+		config.simulations = DefaultMonteCarloSimulations
+	}
+
+	if len(trades) == 0 {
+		return MonteCarloResult{RuinThreshold: config.ruinThreshold}
+	}
+
+	seed := config.seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	rng := rand.New(rand.NewSource(seed))
+
+	var (
+		drawdowns = make([]float64, config.simulations)
+		equities  = make([]float64, config.simulations)
+		streaks   = make([]float64, config.simulations)
+		ruined    int
+		sequence  = make([]float64, len(trades))
+	)
+
+	ruinEquity := initialEquity * (1 - config.ruinThreshold)
+	for i := 0; i < config.simulations; i++ {
+		generateSequence(rng, config.method, trades, sequence)
+
+		path := simulateEquity(sequence, initialEquity, ruinEquity)
+		drawdowns[i] = path.maxDrawdown
+		equities[i] = path.finalEquity
+		streaks[i] = float64(path.losingStreak)
+		if path.ruined {
+			ruined++
+		}
+	}
+
+	return MonteCarloResult{
+		Simulations:   config.simulations,
+		MaxDrawdown:   newDistribution(drawdowns),
+		FinalEquity:   newDistribution(equities),
+		LosingStreak:  newDistribution(streaks),
+		RuinThreshold: config.ruinThreshold,
+		RiskOfRuin:    float64(ruined) / float64(config.simulations),
+	}
+}
+
+// generateSequence fills the sequence with trades using the given method.
+func generateSequence(rng *rand.Rand, method MonteCarloMethod, trades, sequence []float64) {
+	if method == MonteCarloShuffle {
+		copy(sequence, trades)
+		rng.Shuffle(len(sequence), func(i, j int) {
+			sequence[i], sequence[j] = sequence[j], sequence[i]
+		})
+		return
+	}
+
+	for i := range sequence {
+		sequence[i] = trades[rng.Intn(len(trades))]
+	}
+}
+
+// equityPath summarizes the equity path of a trade sequence.
+type equityPath struct {
+	maxDrawdown  float64
+	finalEquity  float64
+	losingStreak int
+	ruined       bool
+}
+
+// simulateEquity applies the trades in order to the initial equity.
+func simulateEquity(sequence []float64, initialEquity, ruinEquity float64) equityPath {
+	var (
+		path   = equityPath{finalEquity: initialEquity}
+		peak   = initialEquity
+		streak int
+	)
+
+	for _, profit := range sequence {
+		path.finalEquity += profit
+		peak = math.Max(peak, path.finalEquity)
+
+		if peak > 0 {
+			path.maxDrawdown = math.Max(path.maxDrawdown, (peak-path.finalEquity)/peak)
+		}
+
+		if path.finalEquity <= ruinEquity {
+			path.ruined = true
+		}
+
+		if profit < 0 {
+			streak++
+			path.losingStreak = max(path.losingStreak, streak)
+		} else {
+			streak = 0
+		}
+	}
+
+	return path
+}
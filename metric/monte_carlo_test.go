@@ -0,0 +1,52 @@
+package metric
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonteCarlo(t *testing.T) {
+	trades := []float64{100, -50, 200, -100, 50, -25, 150, -75}
+
+	t.Run("shuffle", func(t *testing.T) {
+		result := MonteCarlo(trades, 1000, WithSimulations(500), WithMethod(MonteCarloShuffle), WithSeed(1))
+
+		require.Equal(t, 500, result.Simulations)
+		assert.InDelta(t, 1250, result.FinalEquity.Min, 1e-9)
+		assert.InDelta(t, 1250, result.FinalEquity.Max, 1e-9)
+
+		// Four losing trades in a row is the worst ordering, ending a 1000 peak at 750
+		assert.LessOrEqual(t, result.LosingStreak.Max, 4.0)
+		assert.GreaterOrEqual(t, result.LosingStreak.Min, 1.0)
+		assert.LessOrEqual(t, result.MaxDrawdown.Max, 0.25+1e-9)
+		assert.Zero(t, result.RiskOfRuin)
+	})
+
+	t.Run("resample", func(t *testing.T) {
+		result := MonteCarlo(trades, 1000, WithSimulations(2000), WithSeed(1))
+		again := MonteCarlo(trades, 1000, WithSimulations(2000), WithSeed(1))
+
+		assert.Equal(t, result, again)
+		assert.Less(t, result.FinalEquity.Min, result.FinalEquity.Max)
+		assert.LessOrEqual(t, result.FinalEquity.Percentile(0.05), result.FinalEquity.Percentile(0.5))
+		assert.LessOrEqual(t, result.FinalEquity.Percentile(0.5), result.FinalEquity.Percentile(0.95))
+		assert.InDelta(t, 1250, result.FinalEquity.Mean, 25)
+	})
+
+	t.Run("risk of ruin", func(t *testing.T) {
+		result := MonteCarlo([]float64{-100, -100, 50}, 1000, WithSimulations(1000), WithRuinThreshold(0.1), WithSeed(1))
+
+		assert.InDelta(t, 0.1, result.RuinThreshold, 1e-9)
+		assert.Greater(t, result.RiskOfRuin, 0.0)
+		assert.Less(t, result.RiskOfRuin, 1.0)
+	})
+
+	t.Run("no trades", func(t *testing.T) {
+		result := MonteCarlo(nil, 1000)
+
+		assert.Zero(t, result.Simulations)
+		assert.Zero(t, result.FinalEquity.Percentile(0.5))
+	})
+}
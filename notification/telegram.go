@@ -237,8 +237,8 @@ func (t *Telegram) ProfitHandle(m *tb.Message) {
 		return
 	}
 
-	for pair, summary := range t.orderController.Results {
-		t.sendMessage(m.Sender, fmt.Sprintf("*PAIR*: `%s`\n`%s`", pair, summary.String()))
+	for _, summary := range t.orderController.Results {
+		t.sendMessage(m.Sender, fmt.Sprintf("*PAIR*: `%s`\n`%s`", summary.Pair, summary.String()))
 	}
 }
 
@@ -0,0 +1,413 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/raykavin/backnrun/core"
+)
+
+// Default settings of the genetic search
+const (
+	DefaultPopulationSize = 20
+	DefaultTournamentSize = 3
+	DefaultCrossoverRate  = 0.9
+	DefaultMutationRate   = 0.1
+	DefaultElitism        = 2
+)
+
+// GeneticSearch implements an evolutionary optimization algorithm.
+// Each generation is bred from the previous one by tournament selection,
+// uniform crossover and per-parameter mutation, keeping the best members unchanged.
+type GeneticSearch struct {
+	parameters     []core.Parameter
+	maxIterations  int
+	parallelism    int
+	log            core.Logger
+	store          ResultStore
+	rng            *rand.Rand
+	populationSize int
+	generations    int
+	tournamentSize int
+	crossoverRate  float64
+	mutationRate   float64
+	elitism        int
+}
+
+// geneticMember is a population member with its evaluation
+type geneticMember struct {
+	params  core.ParameterSet
+	result  *core.OptimizerResult
+	fitness float64
+}
+
+// NewGeneticSearch creates a new genetic search optimizer
+func NewGeneticSearch(config *Config) (*GeneticSearch, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if len(config.Parameters) == 0 {
+		return nil, fmt.Errorf("at least one parameter must be provided")
+	}
+
+	return &GeneticSearch{
+		parameters:     config.Parameters,
+		maxIterations:  config.MaxIterations,
+		parallelism:    config.Parallelism,
+		log:            config.Logger,
+		store:          config.Store,
+		rng:            config.newRand(),
+		populationSize: DefaultPopulationSize,
+		tournamentSize: DefaultTournamentSize,
+		crossoverRate:  DefaultCrossoverRate,
+		mutationRate:   DefaultMutationRate,
+		elitism:        DefaultElitism,
+	}, nil
+}
+
+// SetParameters sets the parameters to be optimized
+func (g *GeneticSearch) SetParameters(params []core.Parameter) error {
+	if len(params) == 0 {
+		return fmt.Errorf("at least one parameter must be provided")
+	}
+	g.parameters = params
+	return nil
+}
+
+// SetMaxIterations sets the maximum number of evaluations,
+// it also bounds the number of generations when they are not set
+func (g *GeneticSearch) SetMaxIterations(iterations int) {
+	g.maxIterations = iterations
+}
+
+// SetParallelism sets the number of parallel evaluations
+func (g *GeneticSearch) SetParallelism(n int) {
+	g.parallelism = n
+}
+
+// SetPopulationSize sets the number of members in each generation
+func (g *GeneticSearch) SetPopulationSize(n int) {
+	g.populationSize = n
+}
+
+// SetGenerations sets the number of generations, by default MaxIterations / population size
+func (g *GeneticSearch) SetGenerations(n int) {
+	g.generations = n
+}
+
+// SetTournamentSize sets the number of members competing to be selected as a parent
+func (g *GeneticSearch) SetTournamentSize(n int) {
+	g.tournamentSize = n
+}
+
+// SetCrossoverRate sets the probability of breeding a child from two parents instead of copying one
+func (g *GeneticSearch) SetCrossoverRate(rate float64) {
+	g.crossoverRate = rate
+}
+
+// SetMutationRate sets the probability of mutating each parameter of a child
+func (g *GeneticSearch) SetMutationRate(rate float64) {
+	g.mutationRate = rate
+}
+
+// SetElitism sets the number of best members copied unchanged to the next generation
+func (g *GeneticSearch) SetElitism(n int) {
+	g.elitism = n
+}
+
+// Optimize runs the genetic search optimization process.
+// Parameter sets already evaluated are not evaluated again, so the results
+// hold each distinct parameter set once.
+func (g *GeneticSearch) Optimize(
+	ctx context.Context,
+	evaluator core.Evaluator,
+	targetMetric core.MetricName,
+	maximize bool,
+) ([]*core.OptimizerResult, error) {
+	if evaluator == nil {
+		return nil, fmt.Errorf("evaluator cannot be nil")
+	}
+	evaluator = withStore(evaluator, g.store)
+
+	populationSize := max(g.populationSize, 2)
+	generations := g.generations
+	if generations <= 0 {
+		generations = max(g.maxIterations/populationSize, 1)
+	}
+
+	g.logf("Starting genetic search with %d generations of %d members", generations, populationSize)
+
+	evaluated := make(map[string]*core.OptimizerResult)
+	results := make([]*core.OptimizerResult, 0, populationSize*generations)
+
+	population := make([]*geneticMember, populationSize)
+	for i := range population {
+		population[i] = &geneticMember{params: g.randomParameterSet()}
+	}
+
+	for generation := 1; ; generation++ {
+		// Evaluate members not seen before
+		var pending []core.ParameterSet
+		for _, member := range population {
+			key := FormatParameterSet(member.params)
+			if _, ok := evaluated[key]; !ok {
+				evaluated[key] = nil
+				pending = append(pending, member.params)
+			}
+		}
+
+		pendingResults, err := evaluateAll(ctx, evaluator, pending, g.parallelism)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, result := range pendingResults {
+			evaluated[FormatParameterSet(pending[i])] = result
+			results = append(results, result)
+		}
+
+		// Rank the generation by fitness
+		sum := 0.0
+		for _, member := range population {
+			member.result = evaluated[FormatParameterSet(member.params)]
+			member.fitness = member.result.Metrics[string(targetMetric)]
+			if !maximize {
+				member.fitness = -member.fitness
+			}
+			sum += member.result.Metrics[string(targetMetric)]
+		}
+
+		sort.SliceStable(population, func(i, j int) bool {
+			return population[i].fitness > population[j].fitness
+		})
+
+		g.logf("Generation %d/%d: best %s %.4f, mean %.4f (%d new evaluations)",
+			generation, generations, targetMetric, population[0].result.Metrics[string(targetMetric)],
+			sum/float64(len(population)), len(pending))
+
+		if generation == generations {
+			break
+		}
+
+		population = g.breed(population)
+	}
+
+	// Sort results by the target metric
+	sorter := ResultSorter{
+		Results:    results,
+		MetricName: string(targetMetric),
+		Maximize:   maximize,
+	}
+	sort.Stable(sorter)
+
+	g.logf("Genetic search completed with %d results", len(results))
+	return results, nil
+}
+
+// breed creates the next generation from a population sorted by fitness
+func (g *GeneticSearch) breed(population []*geneticMember) []*geneticMember {
+	next := make([]*geneticMember, 0, len(population))
+
+	elitism := min(max(g.elitism, 0), len(population))
+	for _, member := range population[:elitism] {
+		next = append(next, &geneticMember{params: member.params})
+	}
+
+	for len(next) < len(population) {
+		first := g.tournament(population)
+
+		var child core.ParameterSet
+		if g.rng.Float64() < g.crossoverRate {
+			child = g.crossover(first.params, g.tournament(population).params)
+		} else {
+			child = copyParameterSet(first.params)
+		}
+
+		for _, param := range g.parameters {
+			if g.rng.Float64() < g.mutationRate {
+				child[param.Name] = g.mutate(param, child[param.Name])
+			}
+		}
+
+		next = append(next, &geneticMember{params: child})
+	}
+
+	return next
+}
+
+// tournament returns the fittest of randomly picked members
+func (g *GeneticSearch) tournament(population []*geneticMember) *geneticMember {
+	best := population[g.rng.Intn(len(population))]
+	for i := 1; i < g.tournamentSize; i++ {
+		contender := population[g.rng.Intn(len(population))]
+		if contender.fitness > best.fitness {
+			best = contender
+		}
+	}
+	return best
+}
+
+// crossover creates a child taking each parameter from one of the parents
+func (g *GeneticSearch) crossover(first, second core.ParameterSet) core.ParameterSet {
+	child := make(core.ParameterSet, len(first))
+	for _, param := range g.parameters {
+		if g.rng.Intn(2) == 0 {
+			child[param.Name] = first[param.Name]
+		} else {
+			child[param.Name] = second[param.Name]
+		}
+	}
+	return child
+}
+
+// randomParameterSet creates a parameter set with random values on the parameter grids
+func (g *GeneticSearch) randomParameterSet() core.ParameterSet {
+	params := make(core.ParameterSet)
+	for _, param := range g.parameters {
+		params[param.Name] = g.randomValue(param)
+	}
+	return params
+}
+
+// randomValue creates a random value respecting the parameter range, step and options
+func (g *GeneticSearch) randomValue(param core.Parameter) any {
+	switch param.Type {
+	case core.TypeInt, core.TypeFloat:
+		low, high, ok := numericBounds(param)
+		if !ok {
+			return param.Default
+		}
+
+		step := numericStep(param)
+		if step <= 0 {
+			return numericValue(param, low+g.rng.Float64()*(high-low))
+		}
+		return numericValue(param, low+float64(g.rng.Intn(int((high-low)/step+1e-9)+1))*step)
+
+	case core.TypeBool:
+		return g.rng.Intn(2) == 1
+
+	case core.TypeString, core.TypeCategorical:
+		if len(param.Options) == 0 {
+			return param.Default
+		}
+		return param.Options[g.rng.Intn(len(param.Options))]
+
+	default:
+		return param.Default
+	}
+}
+
+// mutate changes a value respecting the parameter range, step and options.
+// Numeric values move by a gaussian amount of steps, about a tenth of the range.
+func (g *GeneticSearch) mutate(param core.Parameter, value any) any {
+	switch param.Type {
+	case core.TypeInt, core.TypeFloat:
+		low, high, ok := numericBounds(param)
+		if !ok {
+			return value
+		}
+
+		current, ok := toFloat(value)
+		if !ok {
+			return g.randomValue(param)
+		}
+
+		step := numericStep(param)
+		if step <= 0 {
+			mutated := current + g.rng.NormFloat64()*(high-low)/10
+			return numericValue(param, math.Min(math.Max(mutated, low), high))
+		}
+
+		steps := math.Round(g.rng.NormFloat64() * math.Max((high-low)/step/10, 1))
+		if steps == 0 {
+			steps = 1
+			if g.rng.Intn(2) == 0 {
+				steps = -1
+			}
+		}
+
+		return numericValue(param, snapToStep(current+steps*step, low, high, step))
+
+	case core.TypeBool:
+		flag, _ := value.(bool)
+		return !flag
+
+	case core.TypeString, core.TypeCategorical:
+		// Pick a different option, keeping the value when there is none
+		others := make([]any, 0, len(param.Options))
+		for _, option := range param.Options {
+			if option != value {
+				others = append(others, option)
+			}
+		}
+		if len(others) == 0 {
+			return value
+		}
+		return others[g.rng.Intn(len(others))]
+
+	default:
+		return value
+	}
+}
+
+// logf logs a message if a log is configured
+func (g *GeneticSearch) logf(format string, args ...any) {
+	if g.log != nil {
+		g.log.Infof(format, args...)
+	}
+}
+
+// numericStep returns the step of an int or float parameter, zero when not set
+func numericStep(param core.Parameter) float64 {
+	switch step := param.Step.(type) {
+	case int:
+		return float64(step)
+	case float64:
+		return step
+	default:
+		return 0
+	}
+}
+
+// snapToStep rounds a value to the nearest point of the grid low + k*step inside the range
+func snapToStep(value, low, high, step float64) float64 {
+	snapped := low + math.Round((value-low)/step)*step
+	for snapped > high+1e-9 {
+		snapped -= step
+	}
+	return math.Max(snapped, low)
+}
+
+// numericValue converts a float to the parameter type
+func numericValue(param core.Parameter, value float64) any {
+	if param.Type == core.TypeInt {
+		return int(math.Round(value))
+	}
+	return value
+}
+
+// toFloat converts an int or float value to float64
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case int:
+		return float64(v), true
+	case float64:
+		return v, true
+	default:
+		return 0, false
+	}
+}
+
+// copyParameterSet returns a shallow copy of a parameter set
+func copyParameterSet(params core.ParameterSet) core.ParameterSet {
+	copied := make(core.ParameterSet, len(params))
+	for name, value := range params {
+		copied[name] = value
+	}
+	return copied
+}
@@ -15,6 +15,7 @@ type GridSearch struct {
 	maxIterations int
 	parallelism   int
 	log           core.Logger
+	store         ResultStore
 }
 
 // NewGridSearch creates a new grid search optimizer
@@ -32,6 +33,7 @@ func NewGridSearch(config *Config) (*GridSearch, error) {
 		maxIterations: config.MaxIterations,
 		parallelism:   config.Parallelism,
 		log:           config.Logger,
+		store:         config.Store,
 	}, nil
 }
 
@@ -59,6 +61,7 @@ func (g *GridSearch) Optimize(ctx context.Context, evaluator core.Evaluator, tar
 	if evaluator == nil {
 		return nil, fmt.Errorf("evaluator cannot be nil")
 	}
+	evaluator = withStore(evaluator, g.store)
 
 	// Generate all parameter combinations
 	parameterSets, err := g.generateParameterSets()
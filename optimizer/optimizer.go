@@ -1,7 +1,11 @@
 package optimizer
 
 import (
+	"context"
 	"fmt"
+	"math/rand"
+	"sync"
+	"time"
 
 	"github.com/raykavin/backnrun/core"
 )
@@ -22,6 +26,10 @@ type Config struct {
 	Maximize bool
 	// Top N results to return
 	TopN int
+	// Seed of the random number generator, zero seeds it from the current time
+	Seed int64
+	// Store persisting every evaluation, parameter sets already stored are not evaluated again
+	Store ResultStore
 }
 
 // NewConfig creates a default configuration
@@ -74,6 +82,69 @@ func (c *Config) WithTopN(n int) *Config {
 	return c
 }
 
+// WithSeed sets the seed of the random number generator, making runs reproducible
+func (c *Config) WithSeed(seed int64) *Config {
+	c.Seed = seed
+	return c
+}
+
+// WithResultStore sets the store used to checkpoint and resume evaluations
+func (c *Config) WithResultStore(store ResultStore) *Config {
+	c.Store = store
+	return c
+}
+
+// newRand creates the random number generator for the configured seed
+func (c *Config) newRand() *rand.Rand {
+	seed := c.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// evaluateAll evaluates parameter sets with at most parallelism concurrent evaluations,
+// returning the results in the same order as the parameter sets
+func evaluateAll(
+	ctx context.Context,
+	evaluator core.Evaluator,
+	parameterSets []core.ParameterSet,
+	parallelism int,
+) ([]*core.OptimizerResult, error) {
+	var (
+		wg        sync.WaitGroup
+		results   = make([]*core.OptimizerResult, len(parameterSets))
+		errs      = make([]error, len(parameterSets))
+		semaphore = make(chan struct{}, max(parallelism, 1))
+	)
+
+	for i, params := range parameterSets {
+		if err := ctx.Err(); err != nil {
+			wg.Wait()
+			return nil, err
+		}
+
+		wg.Add(1)
+		semaphore <- struct{}{} // Acquire semaphore
+
+		go func(index int, paramSet core.ParameterSet) {
+			defer wg.Done()
+			defer func() { <-semaphore }() // Release semaphore
+
+			results[index], errs[index] = evaluator.Evaluate(ctx, paramSet)
+		}(i, params)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("evaluation error: %w", err)
+		}
+	}
+
+	return results, nil
+}
+
 // ValidateParameterSet checks if a parameter set contains all required parameters
 // with values of the correct type
 func ValidateParameterSet(params core.ParameterSet, definitions []core.Parameter) error {
@@ -2,10 +2,16 @@ package optimizer
 
 import (
 	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/exchange"
 )
 
 // MockEvaluator is a simple evaluator for testing
@@ -180,6 +186,501 @@ func TestRandomSearch(t *testing.T) {
 	}
 }
 
+// QuadraticEvaluator is a mock evaluator with a single optimum at x=30, y=1.5, mode=fast
+type QuadraticEvaluator struct{}
+
+// Evaluate implements the Evaluator interface
+func (QuadraticEvaluator) Evaluate(_ context.Context, params core.ParameterSet) (*core.OptimizerResult, error) {
+	x := float64(params["x"].(int))
+	y := params["y"].(float64)
+	profit := 1000 - math.Pow(x-30, 2) - 10*math.Pow(y-1.5, 2)
+	if params["mode"] != "fast" {
+		profit -= 200
+	}
+	if params["enabled"].(bool) {
+		profit += 50
+	}
+
+	return &core.OptimizerResult{
+		Parameters: params,
+		Metrics:    map[string]float64{"profit": profit},
+	}, nil
+}
+
+// TestTPE tests the Tree-structured Parzen Estimator optimizer
+func TestTPE(t *testing.T) {
+	parameters := []core.Parameter{
+		{Name: "x", Min: 0, Max: 100, Type: core.TypeInt},
+		{Name: "y", Min: -5.0, Max: 5.0, Type: core.TypeFloat},
+		{Name: "mode", Options: []any{"slow", "fast", "medium"}, Type: core.TypeCategorical},
+		{Name: "enabled", Type: core.TypeBool},
+	}
+
+	run := func(seed int64) []*core.OptimizerResult {
+		config := NewConfig().
+			WithParameters(parameters...).
+			WithMaxIterations(80).
+			WithParallelism(4).
+			WithSeed(seed)
+
+		tpe, err := NewTPE(config)
+		if err != nil {
+			t.Fatalf("Failed to create TPE: %v", err)
+		}
+
+		results, err := tpe.Optimize(context.Background(), QuadraticEvaluator{}, core.MetricProfit, true)
+		if err != nil {
+			t.Fatalf("TPE optimization failed: %v", err)
+		}
+		return results
+	}
+
+	results := run(42)
+	if len(results) != 80 {
+		t.Fatalf("Expected 80 results, got %d", len(results))
+	}
+
+	best := results[0]
+	if best.Metrics["profit"] < 900 {
+		t.Errorf("Expected best profit above 900, got %.2f with %s",
+			best.Metrics["profit"], FormatParameterSet(best.Parameters))
+	}
+
+	if x := best.Parameters["x"].(int); x < 0 || x > 100 {
+		t.Errorf("Parameter x out of range: %d", x)
+	}
+
+	// Same seed, same trials
+	again := run(42)
+	for i := range results {
+		if FormatParameterSet(results[i].Parameters) != FormatParameterSet(again[i].Parameters) {
+			t.Fatalf("Expected reproducible results, got %s and %s",
+				FormatParameterSet(results[i].Parameters), FormatParameterSet(again[i].Parameters))
+		}
+	}
+}
+
+func TestTPE_Step(t *testing.T) {
+	config := NewConfig().
+		WithParameters(
+			core.Parameter{Name: "x", Min: 0, Max: 100, Step: 5, Type: core.TypeInt},
+			core.Parameter{Name: "y", Min: -5.0, Max: 5.0, Step: 0.25, Type: core.TypeFloat},
+			core.Parameter{Name: "enabled", Type: core.TypeBool},
+		).
+		WithMaxIterations(40).
+		WithParallelism(4).
+		WithSeed(3)
+
+	tpe, err := NewTPE(config)
+	if err != nil {
+		t.Fatalf("Failed to create TPE: %v", err)
+	}
+
+	results, err := tpe.Optimize(context.Background(), QuadraticEvaluator{}, core.MetricProfit, true)
+	if err != nil {
+		t.Fatalf("TPE optimization failed: %v", err)
+	}
+
+	for _, result := range results {
+		if x := result.Parameters["x"].(int); x < 0 || x > 100 || x%5 != 0 {
+			t.Errorf("Parameter x outside of its grid: %d", x)
+		}
+
+		y := result.Parameters["y"].(float64)
+		if y < -5 || y > 5 || math.Abs(y*4-math.Round(y*4)) > 1e-9 {
+			t.Errorf("Parameter y outside of its grid: %f", y)
+		}
+	}
+}
+
+// TestGeneticSearch tests the genetic search optimizer
+func TestGeneticSearch(t *testing.T) {
+	parameters := []core.Parameter{
+		{Name: "x", Min: 0, Max: 100, Step: 2, Type: core.TypeInt},
+		{Name: "y", Min: -5.0, Max: 5.0, Step: 0.5, Type: core.TypeFloat},
+		{Name: "mode", Options: []any{"slow", "fast", "medium"}, Type: core.TypeCategorical},
+		{Name: "enabled", Type: core.TypeBool},
+	}
+
+	run := func(seed int64) []*core.OptimizerResult {
+		config := NewConfig().
+			WithParameters(parameters...).
+			WithMaxIterations(200).
+			WithParallelism(4).
+			WithSeed(seed)
+
+		genetic, err := NewGeneticSearch(config)
+		if err != nil {
+			t.Fatalf("Failed to create genetic search: %v", err)
+		}
+		genetic.SetMutationRate(0.3)
+
+		results, err := genetic.Optimize(context.Background(), QuadraticEvaluator{}, core.MetricProfit, true)
+		if err != nil {
+			t.Fatalf("Genetic search optimization failed: %v", err)
+		}
+		return results
+	}
+
+	results := run(7)
+
+	// 10 generations of 20 members, each distinct parameter set evaluated once
+	if len(results) == 0 || len(results) > 200 {
+		t.Fatalf("Expected between 1 and 200 results, got %d", len(results))
+	}
+
+	seen := make(map[string]bool)
+	for _, result := range results {
+		key := FormatParameterSet(result.Parameters)
+		if seen[key] {
+			t.Errorf("Parameter set evaluated twice: %s", key)
+		}
+		seen[key] = true
+
+		x := result.Parameters["x"].(int)
+		if x < 0 || x > 100 || x%2 != 0 {
+			t.Errorf("Parameter x outside of its grid: %d", x)
+		}
+
+		y := result.Parameters["y"].(float64)
+		if y < -5 || y > 5 || math.Abs(y*2-math.Round(y*2)) > 1e-9 {
+			t.Errorf("Parameter y outside of its grid: %f", y)
+		}
+	}
+
+	if best := results[0]; best.Metrics["profit"] < 1000 {
+		t.Errorf("Expected best profit above 1000, got %.2f with %s",
+			best.Metrics["profit"], FormatParameterSet(best.Parameters))
+	}
+
+	// Same seed, same populations
+	again := run(7)
+	if len(again) != len(results) {
+		t.Fatalf("Expected reproducible results, got %d and %d results", len(results), len(again))
+	}
+	for i := range results {
+		if FormatParameterSet(results[i].Parameters) != FormatParameterSet(again[i].Parameters) {
+			t.Fatalf("Expected reproducible results, got %s and %s",
+				FormatParameterSet(results[i].Parameters), FormatParameterSet(again[i].Parameters))
+		}
+	}
+}
+
+func TestGeneticSearch_MutateOptions(t *testing.T) {
+	genetic, err := NewGeneticSearch(NewConfig().WithParameters(
+		core.Parameter{Name: "mode", Options: []any{"fast"}, Type: core.TypeCategorical},
+	).WithSeed(1))
+	if err != nil {
+		t.Fatalf("Failed to create genetic search: %v", err)
+	}
+
+	// Options equal to the value leave nothing to mutate into
+	for _, options := range [][]any{{"fast"}, {"fast", "fast"}} {
+		param := core.Parameter{Name: "mode", Options: options, Type: core.TypeCategorical}
+		if value := genetic.mutate(param, "fast"); value != "fast" {
+			t.Errorf("Expected fast to be kept with options %v, got %v", options, value)
+		}
+	}
+
+	param := core.Parameter{Name: "mode", Options: []any{"fast", "fast", "slow"}, Type: core.TypeCategorical}
+	for i := 0; i < 10; i++ {
+		if value := genetic.mutate(param, "fast"); value != "slow" {
+			t.Fatalf("Expected fast to mutate into slow, got %v", value)
+		}
+	}
+}
+
+// CountingEvaluator is a mock evaluator counting its evaluations
+type CountingEvaluator struct {
+	MockEvaluator
+	mu    sync.Mutex
+	Count int
+}
+
+// Evaluate implements the Evaluator interface
+func (c *CountingEvaluator) Evaluate(ctx context.Context, params core.ParameterSet) (*core.OptimizerResult, error) {
+	c.mu.Lock()
+	c.Count++
+	c.mu.Unlock()
+	return c.MockEvaluator.Evaluate(ctx, params)
+}
+
+// TestResultStore tests checkpointing and resuming evaluations
+func TestResultStore(t *testing.T) {
+	parameters := []core.Parameter{
+		{Name: "emaLength", Min: 5, Max: 20, Step: 5, Type: core.TypeInt},
+		{Name: "smaLength", Min: 10, Max: 30, Step: 10, Type: core.TypeInt},
+	}
+
+	optimize := func(store ResultStore) ([]*core.OptimizerResult, int) {
+		gridSearch, err := NewGridSearch(NewConfig().
+			WithParameters(parameters...).
+			WithParallelism(2).
+			WithResultStore(store))
+		if err != nil {
+			t.Fatalf("Failed to create grid search: %v", err)
+		}
+
+		evaluator := &CountingEvaluator{MockEvaluator: MockEvaluator{ResultMap: map[string]map[string]float64{}}}
+		results, err := gridSearch.Optimize(context.Background(), evaluator, core.MetricProfit, true)
+		if err != nil {
+			t.Fatalf("Grid search optimization failed: %v", err)
+		}
+		return results, evaluator.Count
+	}
+
+	t.Run("jsonl", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "results.jsonl")
+		store, err := NewJSONLResultStore(file)
+		if err != nil {
+			t.Fatalf("Failed to open result store: %v", err)
+		}
+
+		results, count := optimize(store)
+		if count != 12 || store.Len() != 12 {
+			t.Fatalf("Expected 12 evaluations stored, got %d evaluated and %d stored", count, store.Len())
+		}
+		store.Close()
+
+		// Simulate a crash in the middle of a write
+		f, err := os.OpenFile(file, os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			t.Fatalf("Failed to open result store: %v", err)
+		}
+		f.WriteString(`{"key":"abc","metr`)
+		f.Close()
+
+		store, err = NewJSONLResultStore(file)
+		if err != nil {
+			t.Fatalf("Failed to reopen result store: %v", err)
+		}
+
+		resumed, count := optimize(store)
+		if count != 0 {
+			t.Errorf("Expected no evaluation on resume, got %d", count)
+		}
+
+		if FormatParameterSet(resumed[0].Parameters) != FormatParameterSet(results[0].Parameters) ||
+			resumed[0].Metrics["profit"] != results[0].Metrics["profit"] ||
+			resumed[0].Duration != results[0].Duration {
+			t.Errorf("Expected resumed best result %v, got %v", results[0], resumed[0])
+		}
+
+		// Cached parameters keep their types
+		if _, ok := resumed[0].Parameters["emaLength"].(int); !ok {
+			t.Errorf("Expected int parameter, got %T", resumed[0].Parameters["emaLength"])
+		}
+
+		// Results written after the truncated line are kept
+		result := &core.OptimizerResult{Metrics: map[string]float64{"profit": 1}}
+		if err := store.Put(context.Background(), "def", result); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+		store.Close()
+
+		store, err = NewJSONLResultStore(file)
+		if err != nil {
+			t.Fatalf("Failed to reopen result store: %v", err)
+		}
+		defer store.Close()
+
+		if store.Len() != 13 {
+			t.Errorf("Expected 13 stored results, got %d", store.Len())
+		}
+	})
+
+	t.Run("non-finite metrics", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "results.jsonl")
+		store, err := NewJSONLResultStore(file)
+		if err != nil {
+			t.Fatalf("Failed to open result store: %v", err)
+		}
+
+		metrics := map[string]float64{"sharpe": math.NaN(), "profit_factor": math.Inf(1), "sortino": math.Inf(-1), "profit": 2.5}
+		if err := store.Put(context.Background(), "abc", &core.OptimizerResult{Metrics: metrics}); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+		store.Close()
+
+		store, err = NewJSONLResultStore(file)
+		if err != nil {
+			t.Fatalf("Failed to reopen result store: %v", err)
+		}
+		defer store.Close()
+
+		result, ok, err := store.Get(context.Background(), "abc")
+		if err != nil || !ok {
+			t.Fatalf("Expected stored result, got %v, %v", ok, err)
+		}
+
+		if !math.IsNaN(result.Metrics["sharpe"]) || !math.IsInf(result.Metrics["profit_factor"], 1) ||
+			!math.IsInf(result.Metrics["sortino"], -1) || result.Metrics["profit"] != 2.5 {
+			t.Errorf("Expected metrics %v, got %v", metrics, result.Metrics)
+		}
+	})
+
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := NewSQLiteResultStore(filepath.Join(t.TempDir(), "results.db"))
+		if err != nil {
+			t.Fatalf("Failed to open result store: %v", err)
+		}
+		defer store.Close()
+
+		if _, count := optimize(store); count != 12 {
+			t.Fatalf("Expected 12 evaluations, got %d", count)
+		}
+
+		if _, count := optimize(store); count != 0 {
+			t.Errorf("Expected no evaluation on resume, got %d", count)
+		}
+
+		result := &core.OptimizerResult{Metrics: map[string]float64{"sharpe": math.NaN()}}
+		if err := store.Put(context.Background(), "abc", result); err != nil {
+			t.Fatalf("Failed to store result: %v", err)
+		}
+
+		stored, ok, err := store.Get(context.Background(), "abc")
+		if err != nil || !ok || !math.IsNaN(stored.Metrics["sharpe"]) {
+			t.Errorf("Expected NaN metric, got %v, %v, %v", stored, ok, err)
+		}
+	})
+}
+
+// TestBacktestStrategyEvaluator_EvaluationKey tests the keys of cached backtests
+func TestBacktestStrategyEvaluator_EvaluationKey(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]core.Candle, 0, 48)
+	for i := 0; i < 48; i++ {
+		candles = append(candles, core.Candle{Pair: "BTCUSDT", Time: start.Add(time.Duration(i) * time.Hour), Close: float64(i)})
+	}
+	dataFeed := &exchange.CSVFeed{
+		Feeds:               map[string]exchange.PairFeed{"BTCUSDT": {Pair: "BTCUSDT", Timeframe: "1h"}},
+		CandlePairTimeFrame: map[string][]core.Candle{"BTCUSDT--1h": candles},
+	}
+
+	settings := &core.Settings{Pairs: []string{"BTCUSDT"}}
+	evaluator := NewBacktestStrategyEvaluator(CreateEMAStrategyFactory(), settings, dataFeed, nil, 1000, "USDT")
+	params := core.ParameterSet{"emaLength": 9, "smaLength": 21, "minQuoteAmount": 10.0}
+
+	key, err := evaluator.EvaluationKey(params)
+	if err != nil {
+		t.Fatalf("Failed to create key: %v", err)
+	}
+
+	same, _ := NewBacktestStrategyEvaluator(CreateEMAStrategyFactory(), settings, dataFeed, nil, 1000, "USDT").EvaluationKey(params)
+	if key != same {
+		t.Errorf("Expected identical studies to share keys")
+	}
+
+	sliced, _ := evaluator.ForFeed(dataFeed.Slice(start, start.Add(24*time.Hour))).EvaluationKey(params)
+	if key == sliced {
+		t.Errorf("Expected a different key for a different data feed")
+	}
+
+	other, _ := evaluator.EvaluationKey(core.ParameterSet{"emaLength": 10, "smaLength": 21, "minQuoteAmount": 10.0})
+	if key == other {
+		t.Errorf("Expected a different key for different parameters")
+	}
+
+	windowed, _ := evaluator.ForWindow(dataFeed, start.Add(24*time.Hour)).EvaluationKey(params)
+	if key == windowed {
+		t.Errorf("Expected a different key for a different scored window")
+	}
+
+	// 200 candles of 5m
+	if warmup, err := evaluator.WarmupDuration(params); err != nil || warmup != 1000*time.Minute {
+		t.Errorf("Expected a warmup of 1000m, got %s (%v)", warmup, err)
+	}
+}
+
+// TestParetoFront tests multi-objective results
+func TestParetoFront(t *testing.T) {
+	result := func(param int, profit, drawdown float64) *core.OptimizerResult {
+		return &core.OptimizerResult{
+			Parameters: core.ParameterSet{"param": param},
+			Metrics:    map[string]float64{"profit": profit, "drawdown": drawdown},
+		}
+	}
+
+	results := []*core.OptimizerResult{
+		result(1, 100, 10),
+		result(2, 200, 30),
+		result(3, 150, 30), // dominated by 2
+		result(4, 50, 5),
+		result(5, 90, 10), // dominated by 1
+	}
+
+	objectives := []core.Objective{
+		{Metric: core.MetricProfit, Maximize: true},
+		{Metric: core.MetricDrawdown, Maximize: false},
+	}
+
+	if !Dominates(results[1], results[2], objectives...) {
+		t.Errorf("Expected higher profit with the same drawdown to dominate")
+	}
+
+	if Dominates(results[0], results[1], objectives...) || Dominates(results[1], results[0], objectives...) {
+		t.Errorf("Expected trade-offs not to dominate each other")
+	}
+
+	front := ParetoFront(results, objectives...)
+	params := make([]int, 0, len(front))
+	for _, result := range front {
+		params = append(params, result.Parameters["param"].(int))
+	}
+
+	if len(params) != 3 || params[0] != 2 || params[1] != 1 || params[2] != 4 {
+		t.Fatalf("Expected front [2 1 4] sorted by profit, got %v", params)
+	}
+
+	if dominant := DominantObjectives(front[0], front, objectives...); len(dominant) != 1 || dominant[0].Metric != core.MetricProfit {
+		t.Errorf("Expected best profit to dominate on profit, got %v", dominant)
+	}
+
+	if dominant := DominantObjectives(front[1], front, objectives...); len(dominant) != 0 {
+		t.Errorf("Expected middle result not to dominate any objective, got %v", dominant)
+	}
+
+	file := filepath.Join(t.TempDir(), "pareto.csv")
+	if err := SaveResultsToCSV(front, core.MetricProfit, file, objectives...); err != nil {
+		t.Fatalf("Failed to save results: %v", err)
+	}
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read results: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	if !strings.HasSuffix(lines[0], "Dominates") || !strings.HasSuffix(lines[1], "profit") ||
+		!strings.HasSuffix(lines[3], "drawdown") {
+		t.Errorf("Unexpected CSV content: %s", content)
+	}
+
+	t.Run("optimize", func(t *testing.T) {
+		gridSearch, err := NewGridSearch(NewConfig().WithParameters(
+			core.Parameter{Name: "emaLength", Min: 5, Max: 20, Step: 5, Type: core.TypeInt},
+			core.Parameter{Name: "smaLength", Min: 10, Max: 30, Step: 10, Type: core.TypeInt},
+		))
+		if err != nil {
+			t.Fatalf("Failed to create grid search: %v", err)
+		}
+
+		// Profit grows with emaLength, win rate is constant
+		front, err := OptimizeMultiObjective(context.Background(), gridSearch, &MockEvaluator{},
+			core.Objective{Metric: core.MetricProfit, Maximize: true},
+			core.Objective{Metric: core.MetricWinRate, Maximize: true},
+		)
+		if err != nil {
+			t.Fatalf("Multi-objective optimization failed: %v", err)
+		}
+
+		if len(front) != 1 || front[0].Parameters["emaLength"] != 20 || front[0].Parameters["smaLength"] != 10 {
+			t.Errorf("Expected a single best result, got %d results", len(front))
+		}
+	})
+}
+
 // TestParameterValidation tests parameter validation
 func TestParameterValidation(t *testing.T) {
 	// Define parameters
@@ -297,3 +798,236 @@ func TestResultSorter(t *testing.T) {
 		t.Errorf("Result with lower risk should be sorted first when minimizing")
 	}
 }
+
+// FeedEvaluator is a mock evaluator whose profit grows with the candles in its feed from start
+type FeedEvaluator struct {
+	dataFeed *exchange.CSVFeed
+	start    time.Time
+}
+
+// Evaluate implements the Evaluator interface
+func (f *FeedEvaluator) Evaluate(_ context.Context, params core.ParameterSet) (*core.OptimizerResult, error) {
+	candles := 0.0
+	for _, candle := range f.dataFeed.CandlePairTimeFrame["BTCUSDT--1h"] {
+		if !candle.Time.Before(f.start) {
+			candles++
+		}
+	}
+	factor := float64(params["factor"].(int))
+
+	return &core.OptimizerResult{
+		Parameters: params,
+		Metrics: map[string]float64{
+			"profit":      candles * factor,
+			"trade_count": candles,
+			"win_rate":    factor / 10,
+		},
+	}, nil
+}
+
+// TestWalkForward tests the walk-forward analysis
+func TestWalkForward(t *testing.T) {
+	// 10 days of hourly candles
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]core.Candle, 0, 240)
+	for i := 0; i < 240; i++ {
+		candles = append(candles, core.Candle{Pair: "BTCUSDT", Time: start.Add(time.Duration(i) * time.Hour)})
+	}
+	dataFeed := &exchange.CSVFeed{
+		Feeds:               map[string]exchange.PairFeed{"BTCUSDT": {Pair: "BTCUSDT", Timeframe: "1h"}},
+		CandlePairTimeFrame: map[string][]core.Candle{"BTCUSDT--1h": candles},
+	}
+
+	config := NewConfig().WithParameters(core.Parameter{
+		Name: "factor", Default: 1, Min: 1, Max: 2, Step: 1, Type: core.TypeInt,
+	})
+	gridSearch, err := NewGridSearch(config)
+	if err != nil {
+		t.Fatalf("Failed to create grid search: %v", err)
+	}
+
+	day := 24 * time.Hour
+	newWalkForward := func(config *WalkForwardConfig) *WalkForward {
+		walkForward, err := NewWalkForward(gridSearch, NewBacktestStrategyEvaluator(nil, nil, dataFeed, nil, 0, "USDT"), config)
+		if err != nil {
+			t.Fatalf("Failed to create walk-forward: %v", err)
+		}
+
+		walkForward.evaluator = func(dataFeed *exchange.CSVFeed, start time.Time) core.Evaluator {
+			return &FeedEvaluator{dataFeed: dataFeed, start: start}
+		}
+		walkForward.warmup = func(core.ParameterSet) (time.Duration, error) {
+			return 0, nil
+		}
+		return walkForward
+	}
+
+	t.Run("rolling", func(t *testing.T) {
+		result, err := newWalkForward(NewWalkForwardConfig(4*day, 2*day)).Run(context.Background())
+		if err != nil {
+			t.Fatalf("Walk-forward failed: %v", err)
+		}
+
+		if len(result.Windows) != 3 {
+			t.Fatalf("Expected 3 windows, got %d", len(result.Windows))
+		}
+
+		last := result.Windows[2]
+		if !last.InSampleStart.Equal(start.Add(4*day)) || !last.OutOfSampleStart.Equal(start.Add(8*day)) {
+			t.Errorf("Unexpected last window %s - %s", last.InSampleStart, last.OutOfSampleStart)
+		}
+
+		for _, window := range result.Windows {
+			if window.OutOfSample.Parameters["factor"] != 2 {
+				t.Errorf("Expected best factor 2 out-of-sample, got %v", window.OutOfSample.Parameters["factor"])
+			}
+		}
+
+		// three out-of-sample windows of 48 candles
+		if result.OutOfSample["profit"] != 288 {
+			t.Errorf("Expected stitched profit 288, got %.2f", result.OutOfSample["profit"])
+		}
+
+		if math.Abs(result.OutOfSample["win_rate"]-0.2) > 1e-9 {
+			t.Errorf("Expected stitched win rate 0.2, got %.2f", result.OutOfSample["win_rate"])
+		}
+
+		if result.Efficiency < 0.99 || result.Efficiency > 1.01 {
+			t.Errorf("Expected efficiency close to 1, got %.4f", result.Efficiency)
+		}
+
+		file := filepath.Join(t.TempDir(), "walk_forward.csv")
+		if err := SaveWalkForwardToCSV(result, file); err != nil {
+			t.Fatalf("Failed to save walk-forward: %v", err)
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read walk-forward: %v", err)
+		}
+
+		lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+		if len(lines) != 4 || !strings.Contains(lines[0], "oos_profit") {
+			t.Errorf("Unexpected CSV content: %s", content)
+		}
+	})
+
+	t.Run("out-of-sample warmup", func(t *testing.T) {
+		walkForward := newWalkForward(NewWalkForwardConfig(4*day, 2*day))
+		walkForward.warmup = func(params core.ParameterSet) (time.Duration, error) {
+			return time.Duration(params["factor"].(int)) * 6 * time.Hour, nil
+		}
+
+		var feedStarts []time.Time
+		walkForward.evaluator = func(dataFeed *exchange.CSVFeed, start time.Time) core.Evaluator {
+			if !start.IsZero() {
+				first, _ := dataFeed.Period()
+				feedStarts = append(feedStarts, first)
+			}
+			return &FeedEvaluator{dataFeed: dataFeed, start: start}
+		}
+
+		result, err := walkForward.Run(context.Background())
+		if err != nil {
+			t.Fatalf("Walk-forward failed: %v", err)
+		}
+
+		if len(feedStarts) != len(result.Windows) {
+			t.Fatalf("Expected %d out-of-sample feeds, got %d", len(result.Windows), len(feedStarts))
+		}
+		for i, window := range result.Windows {
+			if expected := window.OutOfSampleStart.Add(-12 * time.Hour); !feedStarts[i].Equal(expected) {
+				t.Errorf("Expected out-of-sample feed %d to start at %s, got %s", i+1, expected, feedStarts[i])
+			}
+		}
+
+		// warmup candles are not scored
+		if result.OutOfSample["profit"] != 288 {
+			t.Errorf("Expected stitched profit 288, got %.2f", result.OutOfSample["profit"])
+		}
+	})
+
+	t.Run("anchored", func(t *testing.T) {
+		result, err := newWalkForward(NewWalkForwardConfig(4*day, 2*day).WithAnchored(true)).Run(context.Background())
+		if err != nil {
+			t.Fatalf("Walk-forward failed: %v", err)
+		}
+
+		for _, window := range result.Windows {
+			if !window.InSampleStart.Equal(start) {
+				t.Errorf("Expected anchored window to start at %s, got %s", start, window.InSampleStart)
+			}
+		}
+
+		if result.Windows[2].InSample.Metrics["profit"] != 384 {
+			t.Errorf("Expected 8 days of in-sample profit, got %.2f", result.Windows[2].InSample.Metrics["profit"])
+		}
+	})
+
+	t.Run("short feed", func(t *testing.T) {
+		_, err := newWalkForward(NewWalkForwardConfig(20*day, 2*day)).Run(context.Background())
+		if err != ErrNoWindows {
+			t.Errorf("Expected ErrNoWindows, got %v", err)
+		}
+	})
+}
+
+func TestStitchMetrics(t *testing.T) {
+	stitched := stitchMetrics([]*core.OptimizerResult{
+		{Metrics: map[string]float64{"profit": 100, "trade_count": 3, "drawdown": 12, "win_rate": 1}},
+		{Metrics: map[string]float64{"profit": -20, "trade_count": 1, "drawdown": 25, "win_rate": 0}},
+		{Metrics: map[string]float64{"profit": 40, "trade_count": 0, "drawdown": 4, "win_rate": 0}},
+	})
+
+	if stitched["profit"] != 120 || stitched["trade_count"] != 4 {
+		t.Errorf("Expected summed profit 120 and 4 trades, got %.2f and %.0f", stitched["profit"], stitched["trade_count"])
+	}
+
+	if stitched["drawdown"] != 25 {
+		t.Errorf("Expected the largest window drawdown 25, got %.2f", stitched["drawdown"])
+	}
+
+	if math.Abs(stitched["win_rate"]-0.75) > 1e-9 {
+		t.Errorf("Expected win rate weighted by trades 0.75, got %.2f", stitched["win_rate"])
+	}
+}
+
+func TestFeedFingerprint(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newFeed := func(last float64) *exchange.CSVFeed {
+		candles := make([]core.Candle, 0, 48)
+		for i := 0; i < 48; i++ {
+			candles = append(candles, core.Candle{Pair: "BTCUSDT", Time: start.Add(time.Duration(i) * time.Hour), Close: float64(i)})
+		}
+		candles[47].Close = last
+		return &exchange.CSVFeed{
+			Feeds:               map[string]exchange.PairFeed{"BTCUSDT": {Pair: "BTCUSDT", Timeframe: "1h"}},
+			CandlePairTimeFrame: map[string][]core.Candle{"BTCUSDT--1h": candles},
+		}
+	}
+
+	feed := newFeed(47)
+	if FeedFingerprint(feed) != FeedFingerprint(newFeed(47)) {
+		t.Error("Expected the same fingerprint for the same candles")
+	}
+	if FeedFingerprint(feed) == FeedFingerprint(newFeed(50)) {
+		t.Error("Expected a different fingerprint for different candles")
+	}
+
+	// fingerprints are taken while parallel backtests resample the feed
+	expected := FeedFingerprint(feed)
+	var wg sync.WaitGroup
+	for _, timeframe := range []string{"2h", "4h", "12h", "1d"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := feed.Resample("BTCUSDT", timeframe); err != nil {
+				t.Errorf("Failed to resample %s: %v", timeframe, err)
+			}
+			if fingerprint := FeedFingerprint(feed); fingerprint != expected {
+				t.Errorf("Expected fingerprint %s, got %s", expected, fingerprint)
+			}
+		}()
+	}
+	wg.Wait()
+}
@@ -0,0 +1,96 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/raykavin/backnrun/core"
+)
+
+// OptimizeMultiObjective runs the optimizer and returns the Pareto front of the evaluated results,
+// sorted by the first objective. Optimizers learning from previous results, such as TPE and
+// genetic search, are guided by the first objective while every result competes for the front.
+func OptimizeMultiObjective(
+	ctx context.Context,
+	optimizer core.Optimizer,
+	evaluator core.Evaluator,
+	objectives ...core.Objective,
+) ([]*core.OptimizerResult, error) {
+	if len(objectives) == 0 {
+		return nil, fmt.Errorf("at least one objective must be provided")
+	}
+
+	results, err := optimizer.Optimize(ctx, evaluator, objectives[0].Metric, objectives[0].Maximize)
+	if err != nil {
+		return nil, err
+	}
+
+	return ParetoFront(results, objectives...), nil
+}
+
+// ParetoFront returns the results not dominated by any other result, sorted by the first objective
+func ParetoFront(results []*core.OptimizerResult, objectives ...core.Objective) []*core.OptimizerResult {
+	front := make([]*core.OptimizerResult, 0)
+	for i, candidate := range results {
+		dominated := false
+		for j, other := range results {
+			if i != j && Dominates(other, candidate, objectives...) {
+				dominated = true
+				break
+			}
+		}
+
+		if !dominated {
+			front = append(front, candidate)
+		}
+	}
+
+	if len(objectives) > 0 {
+		sort.SliceStable(front, func(i, j int) bool {
+			metric := string(objectives[0].Metric)
+			return objectives[0].Better(front[i].Metrics[metric], front[j].Metrics[metric])
+		})
+	}
+
+	return front
+}
+
+// Dominates reports whether result a is at least as good as result b on every objective
+// and strictly better on at least one
+func Dominates(a, b *core.OptimizerResult, objectives ...core.Objective) bool {
+	better := false
+	for _, objective := range objectives {
+		valueA := a.Metrics[string(objective.Metric)]
+		valueB := b.Metrics[string(objective.Metric)]
+
+		if objective.Better(valueB, valueA) {
+			return false
+		}
+		if objective.Better(valueA, valueB) {
+			better = true
+		}
+	}
+	return better
+}
+
+// DominantObjectives returns the objectives on which the result is at least as good as every other result
+func DominantObjectives(result *core.OptimizerResult, results []*core.OptimizerResult, objectives ...core.Objective) []core.Objective {
+	dominant := make([]core.Objective, 0, len(objectives))
+	for _, objective := range objectives {
+		value := result.Metrics[string(objective.Metric)]
+
+		best := true
+		for _, other := range results {
+			if objective.Better(other.Metrics[string(objective.Metric)], value) {
+				best = false
+				break
+			}
+		}
+
+		if best {
+			dominant = append(dominant, objective)
+		}
+	}
+	return dominant
+}
@@ -6,7 +6,6 @@ import (
 	"math/rand"
 	"sort"
 	"sync"
-	"time"
 
 	"github.com/raykavin/backnrun/core"
 )
@@ -17,6 +16,7 @@ type RandomSearch struct {
 	maxIterations int
 	parallelism   int
 	log           core.Logger
+	store         ResultStore
 	rng           *rand.Rand
 }
 
@@ -30,16 +30,13 @@ func NewRandomSearch(config *Config) (*RandomSearch, error) {
 		return nil, fmt.Errorf("at least one parameter must be provided")
 	}
 
-	// Create a new random number generator with a time-based seed
-	source := rand.NewSource(time.Now().UnixNano())
-	rng := rand.New(source)
-
 	return &RandomSearch{
 		parameters:    config.Parameters,
 		maxIterations: config.MaxIterations,
 		parallelism:   config.Parallelism,
 		log:           config.Logger,
-		rng:           rng,
+		store:         config.Store,
+		rng:           config.newRand(),
 	}, nil
 }
 
@@ -72,6 +69,7 @@ func (r *RandomSearch) Optimize(
 	if evaluator == nil {
 		return nil, fmt.Errorf("evaluator cannot be nil")
 	}
+	evaluator = withStore(evaluator, r.store)
 
 	// Generate random parameter sets
 	parameterSets := r.generateRandomParameterSets()
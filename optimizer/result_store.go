@@ -0,0 +1,379 @@
+package optimizer
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/exchange"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ResultStore persists evaluated results so interrupted optimizations can resume
+type ResultStore interface {
+	// Get returns the result stored for an evaluation key
+	Get(ctx context.Context, key string) (*core.OptimizerResult, bool, error)
+	// Put stores the result of an evaluation key
+	Put(ctx context.Context, key string, result *core.OptimizerResult) error
+}
+
+// EvaluationKeyer is implemented by evaluators able to identify the evaluation
+// of a parameter set beyond the parameters, such as the strategy and data used
+type EvaluationKeyer interface {
+	EvaluationKey(params core.ParameterSet) (string, error)
+}
+
+// storedResult is the persisted form of an optimizer result.
+// Parameters are kept for inspection, cached results reuse the requested parameters.
+type storedResult struct {
+	Key        string            `json:"key" gorm:"column:evaluation_key;primaryKey"`
+	Parameters core.ParameterSet `json:"parameters" gorm:"serializer:json"`
+	Metrics    storedMetrics     `json:"metrics" gorm:"serializer:json"`
+	Duration   time.Duration     `json:"duration"`
+}
+
+// storedMetrics encodes the metrics of a result, writing the NaN and infinite
+// values JSON can't represent as strings
+type storedMetrics map[string]float64
+
+// MarshalJSON encodes the metrics, non-finite values as strings
+func (m storedMetrics) MarshalJSON() ([]byte, error) {
+	if m == nil {
+		return []byte("null"), nil
+	}
+
+	encoded := make(map[string]any, len(m))
+	for name, value := range m {
+		if math.IsNaN(value) || math.IsInf(value, 0) {
+			encoded[name] = strconv.FormatFloat(value, 'g', -1, 64)
+		} else {
+			encoded[name] = value
+		}
+	}
+	return json.Marshal(encoded)
+}
+
+// UnmarshalJSON decodes the metrics, parsing values encoded as strings
+func (m *storedMetrics) UnmarshalJSON(data []byte) error {
+	var encoded map[string]json.RawMessage
+	if err := json.Unmarshal(data, &encoded); err != nil {
+		return err
+	}
+
+	if encoded == nil {
+		*m = nil
+		return nil
+	}
+
+	metrics := make(storedMetrics, len(encoded))
+	for name, raw := range encoded {
+		var text string
+		if err := json.Unmarshal(raw, &text); err == nil {
+			value, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return fmt.Errorf("invalid metric %s: %w", name, err)
+			}
+			metrics[name] = value
+			continue
+		}
+
+		var value float64
+		if err := json.Unmarshal(raw, &value); err != nil {
+			return fmt.Errorf("invalid metric %s: %w", name, err)
+		}
+		metrics[name] = value
+	}
+
+	*m = metrics
+	return nil
+}
+
+// TableName returns the table of stored results
+func (storedResult) TableName() string {
+	return "optimizer_results"
+}
+
+// toResult converts a stored result back to an optimizer result
+func (s storedResult) toResult() *core.OptimizerResult {
+	return &core.OptimizerResult{
+		Parameters: s.Parameters,
+		Metrics:    map[string]float64(s.Metrics),
+		Duration:   s.Duration,
+	}
+}
+
+// newStoredResult creates the persisted form of an optimizer result
+func newStoredResult(key string, result *core.OptimizerResult) storedResult {
+	return storedResult{
+		Key:        key,
+		Parameters: result.Parameters,
+		Metrics:    storedMetrics(result.Metrics),
+		Duration:   result.Duration,
+	}
+}
+
+// JSONLResultStore stores results in a JSON lines file, one result per line
+type JSONLResultStore struct {
+	mu      sync.Mutex
+	file    *os.File
+	results map[string]*core.OptimizerResult
+}
+
+// NewJSONLResultStore opens or creates a JSON lines result store.
+// A truncated last line, left by an interrupted write, is removed from the file.
+func NewJSONLResultStore(filePath string) (*JSONLResultStore, error) {
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open result store: %w", err)
+	}
+
+	results := make(map[string]*core.OptimizerResult)
+	reader := bufio.NewReader(file)
+	var size int64 // size of the complete lines
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to read result store: %w", err)
+		}
+		size += int64(len(line))
+
+		var stored storedResult
+		if err := json.Unmarshal(line, &stored); err != nil || stored.Key == "" {
+			continue
+		}
+		results[stored.Key] = stored.toResult()
+	}
+
+	// Appending after a truncated line would corrupt the next result
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to repair result store: %w", err)
+	}
+
+	return &JSONLResultStore{
+		file:    file,
+		results: results,
+	}, nil
+}
+
+// Get returns the result stored for an evaluation key
+func (s *JSONLResultStore) Get(_ context.Context, key string) (*core.OptimizerResult, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result, ok := s.results[key]
+	return result, ok, nil
+}
+
+// Put appends the result of an evaluation key to the file
+func (s *JSONLResultStore) Put(_ context.Context, key string, result *core.OptimizerResult) error {
+	line, err := json.Marshal(newStoredResult(key, result))
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+
+	s.results[key] = result
+	return nil
+}
+
+// Len returns the number of stored results
+func (s *JSONLResultStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.results)
+}
+
+// Close closes the underlying file
+func (s *JSONLResultStore) Close() error {
+	return s.file.Close()
+}
+
+// SQLResultStore stores results in a SQL database via GORM
+type SQLResultStore struct {
+	db *gorm.DB
+}
+
+// NewSQLiteResultStore opens or creates a SQLite result store
+func NewSQLiteResultStore(dbPath string, opts ...gorm.Option) (*SQLResultStore, error) {
+	return NewSQLResultStore(sqlite.Open(dbPath), opts...)
+}
+
+// NewSQLResultStore creates a result store on the given database dialect
+func NewSQLResultStore(dialect gorm.Dialector, opts ...gorm.Option) (*SQLResultStore, error) {
+	db, err := gorm.Open(dialect, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	if err = db.AutoMigrate(&storedResult{}); err != nil {
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	return &SQLResultStore{db: db}, nil
+}
+
+// Get returns the result stored for an evaluation key
+func (s *SQLResultStore) Get(ctx context.Context, key string) (*core.OptimizerResult, bool, error) {
+	var stored []storedResult
+	if err := s.db.WithContext(ctx).Where("evaluation_key = ?", key).Limit(1).Find(&stored).Error; err != nil {
+		return nil, false, err
+	}
+
+	if len(stored) == 0 {
+		return nil, false, nil
+	}
+	return stored[0].toResult(), true, nil
+}
+
+// Put stores the result of an evaluation key, replacing a previous one
+func (s *SQLResultStore) Put(ctx context.Context, key string, result *core.OptimizerResult) error {
+	stored := newStoredResult(key, result)
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&stored).Error
+}
+
+// Close closes the database connection
+func (s *SQLResultStore) Close() error {
+	sqlDB, err := s.db.DB()
+	if err != nil {
+		return fmt.Errorf("failed to get database instance: %w", err)
+	}
+	return sqlDB.Close()
+}
+
+// CachedEvaluator wraps an evaluator, reusing results found in the store and
+// persisting every new evaluation as soon as it completes
+type CachedEvaluator struct {
+	evaluator core.Evaluator
+	store     ResultStore
+}
+
+// NewCachedEvaluator creates an evaluator backed by a result store
+func NewCachedEvaluator(evaluator core.Evaluator, store ResultStore) *CachedEvaluator {
+	return &CachedEvaluator{
+		evaluator: evaluator,
+		store:     store,
+	}
+}
+
+// Evaluate returns the stored result of the parameters, evaluating and storing it when missing
+func (c *CachedEvaluator) Evaluate(ctx context.Context, params core.ParameterSet) (*core.OptimizerResult, error) {
+	key, err := c.key(params)
+	if err != nil {
+		return nil, err
+	}
+
+	cached, ok, err := c.store.Get(ctx, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read result store: %w", err)
+	}
+
+	if ok {
+		return &core.OptimizerResult{
+			Parameters: params,
+			Metrics:    cached.Metrics,
+			Duration:   cached.Duration,
+		}, nil
+	}
+
+	result, err := c.evaluator.Evaluate(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.store.Put(ctx, key, result); err != nil {
+		return nil, fmt.Errorf("failed to write result store: %w", err)
+	}
+
+	return result, nil
+}
+
+// key returns the evaluation key of a parameter set
+func (c *CachedEvaluator) key(params core.ParameterSet) (string, error) {
+	if keyer, ok := c.evaluator.(EvaluationKeyer); ok {
+		return keyer.EvaluationKey(params)
+	}
+	return HashEvaluation("", "", params), nil
+}
+
+// withStore wraps an evaluator in a cached evaluator when a store is configured
+func withStore(evaluator core.Evaluator, store ResultStore) core.Evaluator {
+	if store == nil {
+		return evaluator
+	}
+	return NewCachedEvaluator(evaluator, store)
+}
+
+// HashEvaluation returns a key identifying the evaluation of parameters
+// for a strategy and data feed identifiers
+func HashEvaluation(strategy, feed string, params core.ParameterSet) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "strategy=%s\nfeed=%s\n", strategy, feed)
+
+	names := make([]string, 0, len(params))
+	for name := range params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s=%T:%v\n", name, params[name], params[name])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// FeedFingerprint returns a hash of the source candles of a CSV feed
+func FeedFingerprint(feed *exchange.CSVFeed) string {
+	hash := sha256.New()
+
+	pairs := make([]string, 0, len(feed.Feeds))
+	for pair := range feed.Feeds {
+		pairs = append(pairs, pair)
+	}
+	sort.Strings(pairs)
+
+	buffer := make([]byte, 8)
+	write := func(value uint64) {
+		binary.LittleEndian.PutUint64(buffer, value)
+		hash.Write(buffer)
+	}
+
+	for _, pair := range pairs {
+		pairFeed := feed.Feeds[pair]
+		fmt.Fprintf(hash, "%s--%s:%t\n", pair, pairFeed.Timeframe, pairFeed.HeikinAshi)
+
+		for _, candle := range feed.Candles(pair, pairFeed.Timeframe) {
+			write(uint64(candle.Time.UnixNano()))
+			for _, value := range []float64{candle.Open, candle.Close, candle.Low, candle.High, candle.Volume} {
+				write(math.Float64bits(value))
+			}
+		}
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
@@ -3,6 +3,8 @@ package optimizer
 import (
 	"context"
 	"fmt"
+	"math"
+	"sync"
 	"time"
 
 	"github.com/raykavin/backnrun/bot"
@@ -10,6 +12,7 @@ import (
 	"github.com/raykavin/backnrun/exchange"
 	"github.com/raykavin/backnrun/storage"
 	"github.com/raykavin/backnrun/strategies"
+	strg "github.com/raykavin/backnrun/strategy"
 )
 
 // StrategyFactory is a function that creates a strategy with the given parameters
@@ -23,6 +26,14 @@ type BacktestStrategyEvaluator struct {
 	logger          core.Logger
 	startBalance    float64
 	quoteCurrency   string
+	start           time.Time
+	fingerprint     *feedFingerprint
+}
+
+// feedFingerprint lazily computes the fingerprint of the data feed
+type feedFingerprint struct {
+	once  sync.Once
+	value string
 }
 
 // NewBacktestStrategyEvaluator creates a new evaluator for backtesting strategies
@@ -41,7 +52,76 @@ func NewBacktestStrategyEvaluator(
 		logger:          logger,
 		startBalance:    startBalance,
 		quoteCurrency:   quoteCurrency,
+		fingerprint:     &feedFingerprint{},
+	}
+}
+
+// DataFeed returns the data feed used by the backtests
+func (e *BacktestStrategyEvaluator) DataFeed() *exchange.CSVFeed {
+	return e.dataFeed
+}
+
+// ForFeed returns a copy of the evaluator running its backtests on another data feed
+func (e *BacktestStrategyEvaluator) ForFeed(dataFeed *exchange.CSVFeed) *BacktestStrategyEvaluator {
+	evaluator := *e
+	evaluator.dataFeed = dataFeed
+	evaluator.fingerprint = &feedFingerprint{}
+	return &evaluator
+}
+
+// ForWindow returns a copy of the evaluator running its backtests on another data feed,
+// whose candles opening before start only warm up the strategy
+func (e *BacktestStrategyEvaluator) ForWindow(dataFeed *exchange.CSVFeed, start time.Time) *BacktestStrategyEvaluator {
+	evaluator := e.ForFeed(dataFeed)
+	evaluator.start = start
+	return evaluator
+}
+
+// WarmupDuration returns the time covered by the warmup candles of the strategy built from
+// the parameters, the longest among its timeframe and informative feeds
+func (e *BacktestStrategyEvaluator) WarmupDuration(params core.ParameterSet) (time.Duration, error) {
+	strategy, err := e.strategyFactory(params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create strategy: %w", err)
+	}
+
+	warmup := time.Duration(strategy.WarmupPeriod()) * strg.TimeframeDuration(strategy.Timeframe())
+	if informative, ok := strategy.(core.InformativeStrategy); ok {
+		for _, feed := range informative.Informative() {
+			candles := feed.WarmupPeriod
+			if candles <= 0 {
+				candles = strategy.WarmupPeriod()
+			}
+			warmup = max(warmup, time.Duration(candles)*strg.TimeframeDuration(feed.Timeframe))
+		}
+	}
+
+	return warmup, nil
+}
+
+// EvaluationKey identifies the backtest of a parameter set by the strategy built from them,
+// the data feed candles, the traded pairs and the starting balance
+func (e *BacktestStrategyEvaluator) EvaluationKey(params core.ParameterSet) (string, error) {
+	strategy, err := e.strategyFactory(params)
+	if err != nil {
+		return "", fmt.Errorf("failed to create strategy: %w", err)
 	}
+
+	e.fingerprint.once.Do(func() {
+		e.fingerprint.value = FeedFingerprint(e.dataFeed)
+	})
+
+	var pairs []string
+	if e.settings != nil {
+		pairs = e.settings.Pairs
+	}
+
+	return HashEvaluation(
+		fmt.Sprintf("%T:%s:%d", strategy, strategy.Timeframe(), strategy.WarmupPeriod()),
+		fmt.Sprintf("%s:%v:%f:%s:%s", e.fingerprint.value, pairs, e.startBalance, e.quoteCurrency,
+			e.start.Format(time.RFC3339Nano)),
+		params,
+	), nil
 }
 
 // Evaluate runs a backtest with the given parameters and returns performance metrics
@@ -70,15 +150,12 @@ func (e *BacktestStrategyEvaluator) Evaluate(ctx context.Context, params core.Pa
 	)
 
 	// Set up the trading bot
-	bot, err := bot.NewBot(
-		ctx,
-		e.settings,
-		wallet,
-		e.logger,
-		strategy,
-		bot.WithBacktest(wallet),
-		bot.WithStorage(db),
-	)
+	options := []bot.Option{bot.WithBacktest(wallet), bot.WithStorage(db)}
+	if !e.start.IsZero() {
+		options = append(options, bot.WithBacktestStart(e.start))
+	}
+
+	bot, err := bot.NewBot(ctx, e.settings, wallet, e.logger, strategy, options...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize bot: %w", err)
 	}
@@ -89,7 +166,7 @@ func (e *BacktestStrategyEvaluator) Evaluate(ctx context.Context, params core.Pa
 	}
 
 	// Collect metrics
-	metrics, err := e.collectMetrics(bot)
+	metrics, err := e.collectMetrics(bot, wallet)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect metrics: %w", err)
 	}
@@ -105,7 +182,7 @@ func (e *BacktestStrategyEvaluator) Evaluate(ctx context.Context, params core.Pa
 }
 
 // collectMetrics extracts performance metrics from the bot after a backtest
-func (e *BacktestStrategyEvaluator) collectMetrics(bot *bot.Bot) (map[string]float64, error) {
+func (e *BacktestStrategyEvaluator) collectMetrics(bot *bot.Bot, wallet *exchange.PaperWallet) (map[string]float64, error) {
 	metrics := make(map[string]float64)
 
 	// Initialize counters
@@ -124,7 +201,7 @@ func (e *BacktestStrategyEvaluator) collectMetrics(bot *bot.Bot) (map[string]flo
 		trades := wins + losses
 
 		if trades > 0 {
-			totalProfit += summary.Profit()
+			totalProfit += summary.NetProfit()
 			totalWins += wins
 			totalLosses += losses
 			totalTrades += trades
@@ -141,7 +218,7 @@ func (e *BacktestStrategyEvaluator) collectMetrics(bot *bot.Bot) (map[string]flo
 			avgSQN += sqn
 
 			// Store pair-specific metrics
-			metrics[fmt.Sprintf("%s_profit", summary.Pair)] = summary.Profit()
+			metrics[fmt.Sprintf("%s_profit", summary.Pair)] = summary.NetProfit()
 			metrics[fmt.Sprintf("%s_win_rate", summary.Pair)] = winRate
 			metrics[fmt.Sprintf("%s_payoff", summary.Pair)] = payoff
 			metrics[fmt.Sprintf("%s_profit_factor", summary.Pair)] = profitFactor
@@ -172,7 +249,6 @@ func (e *BacktestStrategyEvaluator) collectMetrics(bot *bot.Bot) (map[string]flo
 		metrics[string(core.MetricTradeCount)] = 0
 	}
 
-	// We can't directly access the paper wallet as it's unexported
 	// The metrics we need are already calculated in the summary
 	// Just ensure we have profit metrics
 	if totalProfit != 0 {
@@ -183,6 +259,24 @@ func (e *BacktestStrategyEvaluator) collectMetrics(bot *bot.Bot) (map[string]flo
 		metrics["return_pct"] = 0
 	}
 
+	// Maximum drawdown of the wallet equity, as a positive percentage
+	metrics[string(core.MetricDrawdown)] = 0
+	if len(wallet.EquityValues()) > 1 {
+		drawdown, _, _ := wallet.MaxDrawdown()
+		metrics[string(core.MetricDrawdown)] = math.Max(-drawdown*100, 0)
+	}
+
+	// Metrics of the equity curve, annualized by the strategy timeframe
+	equity := bot.EquityMetrics()
+	metrics[string(core.MetricAnnualReturn)] = equity.AnnualReturn
+	metrics[string(core.MetricVolatility)] = equity.Volatility
+	metrics[string(core.MetricSharpeRatio)] = equity.SharpeRatio
+	metrics[string(core.MetricSortinoRatio)] = equity.SortinoRatio
+	metrics[string(core.MetricCalmarRatio)] = equity.CalmarRatio
+	metrics[string(core.MetricUlcerIndex)] = equity.UlcerIndex
+	metrics[string(core.MetricTimeInMarket)] = equity.TimeInMarket
+	metrics[string(core.MetricAvgDrawdownDuration)] = equity.AvgDrawdownDuration.Hours()
+
 	return metrics, nil
 }
 
@@ -0,0 +1,430 @@
+package optimizer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/raykavin/backnrun/core"
+)
+
+// Default settings of the Tree-structured Parzen Estimator
+const (
+	DefaultTPEStartupTrials = 10
+	DefaultTPECandidates    = 24
+	DefaultTPEGamma         = 0.25
+)
+
+// TPE implements a Tree-structured Parzen Estimator optimization algorithm.
+// After a few random trials, each parameter value is proposed by modelling the
+// densities of good and bad trials and picking the candidate maximizing their ratio.
+type TPE struct {
+	parameters    []core.Parameter
+	maxIterations int
+	parallelism   int
+	log           core.Logger
+	store         ResultStore
+	rng           *rand.Rand
+	sampler       *RandomSearch
+	startupTrials int
+	candidates    int
+	gamma         float64
+}
+
+// tpeTrial is an evaluated parameter set, score is higher for better results
+type tpeTrial struct {
+	params core.ParameterSet
+	score  float64
+}
+
+// NewTPE creates a new Tree-structured Parzen Estimator optimizer
+func NewTPE(config *Config) (*TPE, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if len(config.Parameters) == 0 {
+		return nil, fmt.Errorf("at least one parameter must be provided")
+	}
+
+	rng := config.newRand()
+	return &TPE{
+		parameters:    config.Parameters,
+		maxIterations: config.MaxIterations,
+		parallelism:   config.Parallelism,
+		log:           config.Logger,
+		store:         config.Store,
+		rng:           rng,
+		sampler:       &RandomSearch{rng: rng},
+		startupTrials: DefaultTPEStartupTrials,
+		candidates:    DefaultTPECandidates,
+		gamma:         DefaultTPEGamma,
+	}, nil
+}
+
+// SetParameters sets the parameters to be optimized
+func (t *TPE) SetParameters(params []core.Parameter) error {
+	if len(params) == 0 {
+		return fmt.Errorf("at least one parameter must be provided")
+	}
+	t.parameters = params
+	return nil
+}
+
+// SetMaxIterations sets the maximum number of iterations
+func (t *TPE) SetMaxIterations(iterations int) {
+	t.maxIterations = iterations
+}
+
+// SetParallelism sets the number of parallel evaluations
+func (t *TPE) SetParallelism(n int) {
+	t.parallelism = n
+}
+
+// SetStartupTrials sets the number of random trials evaluated before modelling
+func (t *TPE) SetStartupTrials(n int) {
+	t.startupTrials = n
+}
+
+// SetCandidates sets the number of candidates sampled for each proposed value
+func (t *TPE) SetCandidates(n int) {
+	t.candidates = n
+}
+
+// SetGamma sets the fraction of the best trials considered good, between 0 and 1
+func (t *TPE) SetGamma(gamma float64) {
+	t.gamma = gamma
+}
+
+// Optimize runs the TPE optimization process.
+// Parameter sets are proposed in batches of the configured parallelism, and the
+// results of a batch are recorded in proposal order so seeded runs are reproducible.
+func (t *TPE) Optimize(
+	ctx context.Context,
+	evaluator core.Evaluator,
+	targetMetric core.MetricName,
+	maximize bool,
+) ([]*core.OptimizerResult, error) {
+	if evaluator == nil {
+		return nil, fmt.Errorf("evaluator cannot be nil")
+	}
+	evaluator = withStore(evaluator, t.store)
+
+	t.logf("Starting TPE optimization with %d iterations", t.maxIterations)
+
+	batchSize := max(t.parallelism, 1)
+	results := make([]*core.OptimizerResult, 0, t.maxIterations)
+	trials := make([]tpeTrial, 0, t.maxIterations)
+
+	for len(results) < t.maxIterations {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		batch := make([]core.ParameterSet, min(batchSize, t.maxIterations-len(results)))
+		for i := range batch {
+			batch[i] = t.suggest(trials)
+		}
+
+		batchResults, err := evaluateAll(ctx, evaluator, batch, len(batch))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, result := range batchResults {
+			score := result.Metrics[string(targetMetric)]
+			if !maximize {
+				score = -score
+			}
+
+			results = append(results, result)
+			trials = append(trials, tpeTrial{params: result.Parameters, score: score})
+		}
+
+		t.logf("Completed evaluation %d/%d", len(results), t.maxIterations)
+	}
+
+	// Sort results by the target metric
+	sorter := ResultSorter{
+		Results:    results,
+		MetricName: string(targetMetric),
+		Maximize:   maximize,
+	}
+	sort.Stable(sorter)
+
+	t.logf("TPE optimization completed with %d results", len(results))
+	return results, nil
+}
+
+// suggest proposes the next parameter set from the trials evaluated so far
+func (t *TPE) suggest(trials []tpeTrial) core.ParameterSet {
+	params := make(core.ParameterSet)
+	if len(trials) < max(t.startupTrials, 2) {
+		for _, param := range t.parameters {
+			params[param.Name] = t.randomValue(param)
+		}
+		return params
+	}
+
+	// Split trials in good and bad by score
+	sorted := make([]tpeTrial, len(trials))
+	copy(sorted, trials)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].score > sorted[j].score
+	})
+
+	split := int(math.Ceil(t.gamma * float64(len(sorted))))
+	split = min(max(split, 1), len(sorted)-1)
+	good, bad := sorted[:split], sorted[split:]
+
+	for _, param := range t.parameters {
+		params[param.Name] = t.suggestValue(param, good, bad)
+	}
+
+	return params
+}
+
+// suggestValue samples candidates from the density of good values of a parameter
+// and returns the one most likely to be good rather than bad
+func (t *TPE) suggestValue(param core.Parameter, good, bad []tpeTrial) any {
+	switch param.Type {
+	case core.TypeInt, core.TypeFloat:
+		low, high, ok := numericBounds(param)
+		if !ok {
+			return t.sampler.generateRandomValue(param)
+		}
+		step := numericStep(param)
+
+		goodDensity := newParzenEstimator(numericValues(param.Name, good), low, high)
+		badDensity := newParzenEstimator(numericValues(param.Name, bad), low, high)
+
+		best, bestRatio := low, math.Inf(-1)
+		for i := 0; i < t.candidates; i++ {
+			candidate := goodDensity.sample(t.rng)
+			if step > 0 {
+				candidate = snapToStep(candidate, low, high, step)
+			} else if param.Type == core.TypeInt {
+				candidate = math.Round(candidate)
+			}
+
+			ratio := math.Log(goodDensity.pdf(candidate)) - math.Log(badDensity.pdf(candidate))
+			if ratio > bestRatio {
+				best, bestRatio = candidate, ratio
+			}
+		}
+
+		if param.Type == core.TypeInt {
+			return int(best)
+		}
+		return best
+
+	case core.TypeBool, core.TypeString, core.TypeCategorical:
+		options := param.Options
+		if param.Type == core.TypeBool {
+			options = []any{false, true}
+		}
+		if len(options) == 0 {
+			return param.Default
+		}
+
+		goodWeights := categoricalWeights(param.Name, options, good)
+		badWeights := categoricalWeights(param.Name, options, bad)
+
+		best, bestRatio := 0, math.Inf(-1)
+		for i := 0; i < t.candidates; i++ {
+			candidate := sampleWeighted(t.rng, goodWeights)
+			ratio := math.Log(goodWeights[candidate]) - math.Log(badWeights[candidate])
+			if ratio > bestRatio {
+				best, bestRatio = candidate, ratio
+			}
+		}
+		return options[best]
+
+	default:
+		return param.Default
+	}
+}
+
+// randomValue draws a random value of a parameter, on the grid of its step when set
+func (t *TPE) randomValue(param core.Parameter) any {
+	value := t.sampler.generateRandomValue(param)
+
+	low, high, ok := numericBounds(param)
+	step := numericStep(param)
+	current, isNumber := toFloat(value)
+	if !ok || step <= 0 || !isNumber {
+		return value
+	}
+	return numericValue(param, snapToStep(current, low, high, step))
+}
+
+// logf logs a message if a log is configured
+func (t *TPE) logf(format string, args ...any) {
+	if t.log != nil {
+		t.log.Infof(format, args...)
+	}
+}
+
+// parzenEstimator is a mixture of gaussian kernels centered on observed values plus
+// a wide prior kernel, truncated to the parameter range. The width of each kernel is
+// the distance to its farthest neighbor, so kernels narrow as observations accumulate.
+type parzenEstimator struct {
+	mus    []float64
+	sigmas []float64
+	low    float64
+	high   float64
+}
+
+// newParzenEstimator creates an estimator for values in the [low, high] range
+func newParzenEstimator(values []float64, low, high float64) *parzenEstimator {
+	width := high - low
+	prior := low + width/2
+
+	mus := append([]float64{prior}, values...)
+	sort.Float64s(mus)
+
+	minSigma := width / math.Min(100, float64(len(mus)))
+	sigmas := make([]float64, len(mus))
+	for i, mu := range mus {
+		left, right := mu-low, high-mu
+		if i > 0 {
+			left = mu - mus[i-1]
+		}
+		if i < len(mus)-1 {
+			right = mus[i+1] - mu
+		}
+		sigmas[i] = math.Min(math.Max(math.Max(left, right), minSigma), width)
+	}
+
+	// The prior kernel always spans the whole range
+	for i, mu := range mus {
+		if mu == prior {
+			sigmas[i] = width
+			break
+		}
+	}
+
+	return &parzenEstimator{
+		mus:    mus,
+		sigmas: sigmas,
+		low:    low,
+		high:   high,
+	}
+}
+
+// sample draws a value from the estimator within the range
+func (p *parzenEstimator) sample(rng *rand.Rand) float64 {
+	index := rng.Intn(len(p.mus))
+	if p.sigmas[index] == 0 {
+		return p.mus[index]
+	}
+
+	value := p.mus[index]
+	for i := 0; i < 100; i++ {
+		value = p.mus[index] + rng.NormFloat64()*p.sigmas[index]
+		if value >= p.low && value <= p.high {
+			return value
+		}
+	}
+	return math.Min(math.Max(value, p.low), p.high)
+}
+
+// pdf returns the density of the estimator at a value
+func (p *parzenEstimator) pdf(value float64) float64 {
+	if p.high == p.low {
+		return 1
+	}
+
+	density := 0.0
+	for i, mu := range p.mus {
+		sigma := p.sigmas[i]
+		z := (value - mu) / sigma
+		mass := normalCDF((p.high-mu)/sigma) - normalCDF((p.low-mu)/sigma)
+		density += math.Exp(-0.5*z*z) / (sigma * math.Sqrt(2*math.Pi) * mass)
+	}
+
+	return density / float64(len(p.mus))
+}
+
+// normalCDF returns the standard normal cumulative distribution at x
+func normalCDF(x float64) float64 {
+	return 0.5 * (1 + math.Erf(x/math.Sqrt2))
+}
+
+// numericBounds returns the range of an int or float parameter
+func numericBounds(param core.Parameter) (low, high float64, ok bool) {
+	switch param.Type {
+	case core.TypeInt:
+		minValue, minOk := param.Min.(int)
+		maxValue, maxOk := param.Max.(int)
+		if !minOk || !maxOk || minValue > maxValue {
+			return 0, 0, false
+		}
+		return float64(minValue), float64(maxValue), true
+	case core.TypeFloat:
+		minValue, minOk := param.Min.(float64)
+		maxValue, maxOk := param.Max.(float64)
+		if !minOk || !maxOk || minValue > maxValue {
+			return 0, 0, false
+		}
+		return minValue, maxValue, true
+	default:
+		return 0, 0, false
+	}
+}
+
+// numericValues returns the values of a numeric parameter in the trials
+func numericValues(name string, trials []tpeTrial) []float64 {
+	values := make([]float64, 0, len(trials))
+	for _, trial := range trials {
+		switch value := trial.params[name].(type) {
+		case int:
+			values = append(values, float64(value))
+		case float64:
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// categoricalWeights returns the smoothed frequency of each option in the trials
+func categoricalWeights(name string, options []any, trials []tpeTrial) []float64 {
+	weights := make([]float64, len(options))
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	for _, trial := range trials {
+		for i, option := range options {
+			if trial.params[name] == option {
+				weights[i]++
+				break
+			}
+		}
+	}
+
+	total := float64(len(options) + len(trials))
+	for i := range weights {
+		weights[i] /= total
+	}
+	return weights
+}
+
+// sampleWeighted draws an index with probability proportional to its weight
+func sampleWeighted(rng *rand.Rand, weights []float64) int {
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+
+	target := rng.Float64() * total
+	for i, weight := range weights {
+		target -= weight
+		if target < 0 {
+			return i
+		}
+	}
+	return len(weights) - 1
+}
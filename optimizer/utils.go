@@ -6,116 +6,160 @@ import (
 	"os"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/raykavin/backnrun/core"
 )
 
-// SaveResultsToCSV saves optimization results to a CSV file
-func SaveResultsToCSV(results []*core.OptimizerResult, targetMetric core.MetricName, filePath string) error {
-	// Create file
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
-	}
-	defer file.Close()
-
-	// Create CSV writer
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
-
+// SaveResultsToCSV saves optimization results to a CSV file.
+// With objectives, a Dominates column lists the objectives on which each result is the best.
+func SaveResultsToCSV(
+	results []*core.OptimizerResult,
+	targetMetric core.MetricName,
+	filePath string,
+	objectives ...core.Objective,
+) error {
 	// Sort results by the target metric
 	sorter := ResultSorter{
 		Results:    results,
 		MetricName: string(targetMetric),
-		Maximize:   true,
+		Maximize:   maximizeMetric(targetMetric, objectives),
 	}
 	sort.Sort(sorter)
 
 	// Determine all parameter names and metric names
-	paramNames := make(map[string]bool)
-	metricNames := make(map[string]bool)
+	paramNameSlice := parameterNames(results)
+	metricNameSlice := metricNames(results)
 
-	for _, result := range results {
-		for paramName := range result.Parameters {
-			paramNames[paramName] = true
+	// Create header row
+	header := []string{"Rank", "Duration"}
+	header = append(header, paramNameSlice...)
+	header = append(header, metricNameSlice...)
+	if len(objectives) > 0 {
+		header = append(header, "Dominates")
+	}
+
+	// Create a row for each result
+	rows := make([][]string, 0, len(results))
+	for i, result := range results {
+		row := []string{
+			strconv.Itoa(i + 1),
+			result.Duration.String(),
 		}
-		for metricName := range result.Metrics {
-			metricNames[metricName] = true
+		row = append(row, parameterValues(result.Parameters, paramNameSlice)...)
+		row = append(row, metricValues(result.Metrics, metricNameSlice)...)
+		if len(objectives) > 0 {
+			row = append(row, formatObjectives(DominantObjectives(result, results, objectives...), ";"))
 		}
+		rows = append(rows, row)
 	}
 
-	// Convert to slices and sort for consistent ordering
-	paramNameSlice := make([]string, 0, len(paramNames))
-	for name := range paramNames {
-		paramNameSlice = append(paramNameSlice, name)
-	}
-	sort.Strings(paramNameSlice)
+	return writeCSV(filePath, header, rows)
+}
 
-	metricNameSlice := make([]string, 0, len(metricNames))
-	for name := range metricNames {
-		metricNameSlice = append(metricNameSlice, name)
+// writeCSV writes a header and its rows to a CSV file
+func writeCSV(filePath string, header []string, rows [][]string) error {
+	// Create file
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
 	}
-	sort.Strings(metricNameSlice)
+	defer file.Close()
+
+	// Create CSV writer
+	writer := csv.NewWriter(file)
 
-	// Create header row
-	header := []string{"Rank", "Duration"}
-	header = append(header, paramNameSlice...)
-	header = append(header, metricNameSlice...)
 	if err := writer.Write(header); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
 	}
 
-	// Write each result
-	for i, result := range results {
-		row := []string{
-			strconv.Itoa(i + 1),
-			result.Duration.String(),
+	for _, row := range rows {
+		if err := writer.Write(row); err != nil {
+			return fmt.Errorf("failed to write row: %w", err)
 		}
+	}
 
-		// Add parameter values
-		for _, paramName := range paramNameSlice {
-			value, exists := result.Parameters[paramName]
-			if !exists {
-				row = append(row, "")
-				continue
-			}
+	writer.Flush()
+	return writer.Error()
+}
 
-			// Convert value to string
-			switch v := value.(type) {
-			case int:
-				row = append(row, strconv.Itoa(v))
-			case float64:
-				row = append(row, strconv.FormatFloat(v, 'f', 4, 64))
-			case bool:
-				row = append(row, strconv.FormatBool(v))
-			case string:
-				row = append(row, v)
-			default:
-				row = append(row, fmt.Sprintf("%v", v))
-			}
+// parameterNames returns the sorted names of all parameters found in the results
+func parameterNames(results []*core.OptimizerResult) []string {
+	names := make(map[string]bool)
+	for _, result := range results {
+		for name := range result.Parameters {
+			names[name] = true
 		}
+	}
+	return sortedKeys(names)
+}
 
-		// Add metric values
-		for _, metricName := range metricNameSlice {
-			value, exists := result.Metrics[metricName]
-			if !exists {
-				row = append(row, "")
-				continue
-			}
-			row = append(row, strconv.FormatFloat(value, 'f', 4, 64))
+// metricNames returns the sorted names of all metrics found in the results
+func metricNames(results []*core.OptimizerResult) []string {
+	names := make(map[string]bool)
+	for _, result := range results {
+		for name := range result.Metrics {
+			names[name] = true
 		}
+	}
+	return sortedKeys(names)
+}
 
-		if err := writer.Write(row); err != nil {
-			return fmt.Errorf("failed to write row: %w", err)
+// sortedKeys returns the keys of a set in a consistent order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parameterValues formats the values of the named parameters, empty when missing
+func parameterValues(params core.ParameterSet, names []string) []string {
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		value, exists := params[name]
+		if !exists {
+			values = append(values, "")
+			continue
+		}
+
+		// Convert value to string
+		switch v := value.(type) {
+		case int:
+			values = append(values, strconv.Itoa(v))
+		case float64:
+			values = append(values, strconv.FormatFloat(v, 'f', 4, 64))
+		case bool:
+			values = append(values, strconv.FormatBool(v))
+		case string:
+			values = append(values, v)
+		default:
+			values = append(values, fmt.Sprintf("%v", v))
 		}
 	}
+	return values
+}
 
-	return nil
+// metricValues formats the values of the named metrics, empty when missing
+func metricValues(metrics map[string]float64, names []string) []string {
+	values := make([]string, 0, len(names))
+	for _, name := range names {
+		value, exists := metrics[name]
+		if !exists {
+			values = append(values, "")
+			continue
+		}
+		values = append(values, strconv.FormatFloat(value, 'f', 4, 64))
+	}
+	return values
 }
 
-// PrintResults prints optimization results to the console
-func PrintResults(results []*core.OptimizerResult, targetMetric core.MetricName, topN int) {
+// PrintResults prints optimization results to the console.
+// With objectives, each result shows the objectives on which it is the best.
+func PrintResults(results []*core.OptimizerResult, targetMetric core.MetricName, topN int, objectives ...core.Objective) {
 	if len(results) == 0 {
 		fmt.Println("No results to display")
 		return
@@ -125,11 +169,12 @@ func PrintResults(results []*core.OptimizerResult, targetMetric core.MetricName,
 	sorter := ResultSorter{
 		Results:    results,
 		MetricName: string(targetMetric),
-		Maximize:   true,
+		Maximize:   maximizeMetric(targetMetric, objectives),
 	}
 	sort.Sort(sorter)
 
 	// Limit to top N results
+	all := results
 	if topN > 0 && topN < len(results) {
 		results = results[:topN]
 	}
@@ -139,6 +184,14 @@ func PrintResults(results []*core.OptimizerResult, targetMetric core.MetricName,
 	for i, result := range results {
 		fmt.Printf("Rank #%d (Duration: %s)\n", i+1, result.Duration.Round(time.Millisecond))
 
+		if len(objectives) > 0 {
+			dominant := formatObjectives(DominantObjectives(result, all, objectives...), ", ")
+			if dominant == "" {
+				dominant = "-"
+			}
+			fmt.Printf("Dominates: %s\n", dominant)
+		}
+
 		fmt.Println("Parameters:")
 		for name, value := range result.Parameters {
 			fmt.Printf("  %s: %v\n", name, value)
@@ -161,6 +214,25 @@ func PrintResults(results []*core.OptimizerResult, targetMetric core.MetricName,
 	}
 }
 
+// maximizeMetric returns the direction of the metric among the objectives, maximizing by default
+func maximizeMetric(metric core.MetricName, objectives []core.Objective) bool {
+	for _, objective := range objectives {
+		if objective.Metric == metric {
+			return objective.Maximize
+		}
+	}
+	return true
+}
+
+// formatObjectives joins the metric names of objectives
+func formatObjectives(objectives []core.Objective, separator string) string {
+	names := make([]string, 0, len(objectives))
+	for _, objective := range objectives {
+		names = append(names, objective.String())
+	}
+	return strings.Join(names, separator)
+}
+
 // FormatParameterSet formats a parameter set as a string
 func FormatParameterSet(params core.ParameterSet) string {
 	result := "{"
@@ -0,0 +1,353 @@
+package optimizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/exchange"
+)
+
+// Walk-forward errors
+var (
+	ErrInvalidWindow = errors.New("in-sample and out-of-sample windows must be positive")
+	ErrNoWindows     = errors.New("data feed is too short for a single walk-forward window")
+	ErrNoResults     = errors.New("optimizer returned no results")
+)
+
+// WalkForwardConfig holds configuration for the walk-forward analysis
+type WalkForwardConfig struct {
+	// Length of the in-sample window used to fit parameters
+	InSample time.Duration
+	// Length of the out-of-sample window following each in-sample window
+	OutOfSample time.Duration
+	// Distance between consecutive windows, defaults to the out-of-sample length
+	Step time.Duration
+	// Whether in-sample windows grow from the start of the feed instead of rolling
+	Anchored bool
+	// Target metric to optimize
+	TargetMetric core.MetricName
+	// Whether to maximize (true) or minimize (false) the target metric
+	Maximize bool
+	// Logger instance
+	Logger core.Logger
+}
+
+// NewWalkForwardConfig creates a default rolling walk-forward configuration
+func NewWalkForwardConfig(inSample, outOfSample time.Duration) *WalkForwardConfig {
+	return &WalkForwardConfig{
+		InSample:     inSample,
+		OutOfSample:  outOfSample,
+		TargetMetric: core.MetricProfit,
+		Maximize:     true,
+	}
+}
+
+// WithStep sets the distance between consecutive windows
+func (c *WalkForwardConfig) WithStep(step time.Duration) *WalkForwardConfig {
+	c.Step = step
+	return c
+}
+
+// WithAnchored makes every in-sample window start at the beginning of the feed
+func (c *WalkForwardConfig) WithAnchored(anchored bool) *WalkForwardConfig {
+	c.Anchored = anchored
+	return c
+}
+
+// WithTargetMetric sets the target metric to optimize
+func (c *WalkForwardConfig) WithTargetMetric(metric core.MetricName, maximize bool) *WalkForwardConfig {
+	c.TargetMetric = metric
+	c.Maximize = maximize
+	return c
+}
+
+// WithLogger sets the logger
+func (c *WalkForwardConfig) WithLogger(logger core.Logger) *WalkForwardConfig {
+	c.Logger = logger
+	return c
+}
+
+// WalkForwardWindow holds the outcome of a single in-sample/out-of-sample window
+type WalkForwardWindow struct {
+	Index            int
+	InSampleStart    time.Time
+	InSampleEnd      time.Time
+	OutOfSampleStart time.Time
+	OutOfSampleEnd   time.Time
+	// Best in-sample result, whose parameters are used out-of-sample
+	InSample *core.OptimizerResult
+	// Result of the best parameters on the out-of-sample window
+	OutOfSample *core.OptimizerResult
+	// Ratio between the out-of-sample and in-sample profit per unit of time
+	Efficiency float64
+}
+
+// WalkForwardResult holds the results of a walk-forward analysis
+type WalkForwardResult struct {
+	Windows []*WalkForwardWindow
+	// Out-of-sample metrics of all windows stitched together
+	OutOfSample map[string]float64
+	// Ratio between the stitched out-of-sample and in-sample profit per unit of time
+	Efficiency float64
+}
+
+// WalkForward optimizes parameters on rolling or anchored in-sample windows
+// and validates them on the out-of-sample window that follows each of them
+type WalkForward struct {
+	optimizer core.Optimizer
+	dataFeed  *exchange.CSVFeed
+	evaluator func(dataFeed *exchange.CSVFeed, start time.Time) core.Evaluator
+	warmup    func(params core.ParameterSet) (time.Duration, error)
+	config    *WalkForwardConfig
+}
+
+// NewWalkForward creates a walk-forward analysis running the optimizer on slices
+// of the evaluator data feed
+func NewWalkForward(
+	optimizer core.Optimizer,
+	evaluator *BacktestStrategyEvaluator,
+	config *WalkForwardConfig,
+) (*WalkForward, error) {
+	if optimizer == nil {
+		return nil, fmt.Errorf("optimizer cannot be nil")
+	}
+
+	if evaluator == nil || evaluator.DataFeed() == nil {
+		return nil, fmt.Errorf("evaluator with a data feed must be provided")
+	}
+
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+
+	if config.InSample <= 0 || config.OutOfSample <= 0 || config.Step < 0 {
+		return nil, ErrInvalidWindow
+	}
+
+	return &WalkForward{
+		optimizer: optimizer,
+		dataFeed:  evaluator.DataFeed(),
+		evaluator: func(dataFeed *exchange.CSVFeed, start time.Time) core.Evaluator {
+			return evaluator.ForWindow(dataFeed, start)
+		},
+		warmup: evaluator.WarmupDuration,
+		config: config,
+	}, nil
+}
+
+// Run executes the walk-forward analysis over the whole data feed
+func (w *WalkForward) Run(ctx context.Context) (*WalkForwardResult, error) {
+	start, last := w.dataFeed.Period()
+	windows := w.windows(start, last.Add(time.Nanosecond))
+	if len(windows) == 0 {
+		return nil, ErrNoWindows
+	}
+
+	w.logf("Starting walk-forward analysis with %d windows", len(windows))
+
+	for _, window := range windows {
+		if err := w.runWindow(ctx, window); err != nil {
+			return nil, fmt.Errorf("window %d: %w", window.Index+1, err)
+		}
+
+		w.logf("Window %d/%d: in-sample %s %.4f, out-of-sample %s %.4f, efficiency %.2f",
+			window.Index+1, len(windows),
+			w.config.TargetMetric, window.InSample.Metrics[string(w.config.TargetMetric)],
+			w.config.TargetMetric, window.OutOfSample.Metrics[string(w.config.TargetMetric)],
+			window.Efficiency)
+	}
+
+	inSample := make([]*core.OptimizerResult, 0, len(windows))
+	outOfSample := make([]*core.OptimizerResult, 0, len(windows))
+	var inSampleTime, outOfSampleTime time.Duration
+	for _, window := range windows {
+		inSample = append(inSample, window.InSample)
+		outOfSample = append(outOfSample, window.OutOfSample)
+		inSampleTime += window.InSampleEnd.Sub(window.InSampleStart)
+		outOfSampleTime += window.OutOfSampleEnd.Sub(window.OutOfSampleStart)
+	}
+
+	return &WalkForwardResult{
+		Windows:     windows,
+		OutOfSample: stitchMetrics(outOfSample),
+		Efficiency: efficiency(
+			stitchMetrics(inSample)[string(core.MetricProfit)], inSampleTime,
+			stitchMetrics(outOfSample)[string(core.MetricProfit)], outOfSampleTime,
+		),
+	}, nil
+}
+
+// runWindow optimizes a window in-sample and evaluates the best parameters out-of-sample
+func (w *WalkForward) runWindow(ctx context.Context, window *WalkForwardWindow) error {
+	inSampleFeed := w.dataFeed.Slice(window.InSampleStart, window.InSampleEnd)
+	results, err := w.optimizer.Optimize(ctx, w.evaluator(inSampleFeed, time.Time{}), w.config.TargetMetric, w.config.Maximize)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		return ErrNoResults
+	}
+	window.InSample = results[0]
+
+	// The candles before the out-of-sample window warm up the strategy, only the window is scored
+	warmup, err := w.warmup(window.InSample.Parameters)
+	if err != nil {
+		return err
+	}
+
+	outOfSampleFeed := w.dataFeed.Slice(window.OutOfSampleStart.Add(-warmup), window.OutOfSampleEnd)
+	window.OutOfSample, err = w.evaluator(outOfSampleFeed, window.OutOfSampleStart).Evaluate(ctx, window.InSample.Parameters)
+	if err != nil {
+		return err
+	}
+
+	window.Efficiency = efficiency(
+		window.InSample.Metrics[string(core.MetricProfit)], window.InSampleEnd.Sub(window.InSampleStart),
+		window.OutOfSample.Metrics[string(core.MetricProfit)], window.OutOfSampleEnd.Sub(window.OutOfSampleStart),
+	)
+
+	return nil
+}
+
+// windows splits the [start, end) interval in consecutive walk-forward windows,
+// the last out-of-sample window is truncated at the end of the interval
+func (w *WalkForward) windows(start, end time.Time) []*WalkForwardWindow {
+	step := w.config.Step
+	if step == 0 {
+		step = w.config.OutOfSample
+	}
+
+	var windows []*WalkForwardWindow
+	for i := 0; ; i++ {
+		offset := time.Duration(i) * step
+		inSampleStart := start.Add(offset)
+		if w.config.Anchored {
+			inSampleStart = start
+		}
+
+		inSampleEnd := start.Add(offset + w.config.InSample)
+		if !inSampleEnd.Before(end) {
+			break
+		}
+
+		outOfSampleEnd := inSampleEnd.Add(w.config.OutOfSample)
+		if outOfSampleEnd.After(end) {
+			outOfSampleEnd = end
+		}
+
+		windows = append(windows, &WalkForwardWindow{
+			Index:            i,
+			InSampleStart:    inSampleStart,
+			InSampleEnd:      inSampleEnd,
+			OutOfSampleStart: inSampleEnd,
+			OutOfSampleEnd:   outOfSampleEnd,
+		})
+	}
+
+	return windows
+}
+
+// logf logs a message if a log is configured
+func (w *WalkForward) logf(format string, args ...any) {
+	if w.config.Logger != nil {
+		w.config.Logger.Infof(format, args...)
+	}
+}
+
+// efficiency returns the ratio between the out-of-sample and in-sample profit rates,
+// zero when the in-sample profit is not positive
+func efficiency(inSample float64, inSampleTime time.Duration, outOfSample float64, outOfSampleTime time.Duration) float64 {
+	if inSample <= 0 || inSampleTime <= 0 || outOfSampleTime <= 0 {
+		return 0
+	}
+
+	return (outOfSample / outOfSampleTime.Hours()) / (inSample / inSampleTime.Hours())
+}
+
+// stitchMetrics combines results of consecutive windows as a single run.
+// Profits and trade counts are summed and the drawdown is the largest window drawdown.
+// Other metrics are averaged weighted by trades. Windows only report their metrics, so
+// non-additive metrics are approximations: a drawdown spanning two windows is not seen,
+// and ratios such as the Sharpe ratio are not computed from the stitched equity.
+func stitchMetrics(results []*core.OptimizerResult) map[string]float64 {
+	stitched := make(map[string]float64)
+	names := metricNames(results)
+
+	totalTrades := 0.0
+	for _, result := range results {
+		totalTrades += result.Metrics[string(core.MetricTradeCount)]
+	}
+
+	for _, name := range names {
+		additive := name == string(core.MetricProfit) || name == string(core.MetricTradeCount) ||
+			strings.HasSuffix(name, "_profit") || strings.HasSuffix(name, "_trades")
+
+		for _, result := range results {
+			value := result.Metrics[name]
+			switch {
+			case additive:
+				stitched[name] += value
+			case name == string(core.MetricDrawdown):
+				stitched[name] = math.Max(stitched[name], value)
+			case totalTrades > 0:
+				stitched[name] += value * result.Metrics[string(core.MetricTradeCount)] / totalTrades
+			default:
+				stitched[name] += value / float64(len(results))
+			}
+		}
+	}
+
+	return stitched
+}
+
+// SaveWalkForwardToCSV saves the walk-forward windows to a CSV file,
+// with in-sample and out-of-sample metrics prefixed by "is_" and "oos_"
+func SaveWalkForwardToCSV(result *WalkForwardResult, filePath string) error {
+	inSample := make([]*core.OptimizerResult, 0, len(result.Windows))
+	outOfSample := make([]*core.OptimizerResult, 0, len(result.Windows))
+	for _, window := range result.Windows {
+		inSample = append(inSample, window.InSample)
+		outOfSample = append(outOfSample, window.OutOfSample)
+	}
+
+	paramNameSlice := parameterNames(inSample)
+	inSampleMetrics := metricNames(inSample)
+	outOfSampleMetrics := metricNames(outOfSample)
+
+	// Create header row
+	header := []string{"Window", "InSampleStart", "InSampleEnd", "OutOfSampleStart", "OutOfSampleEnd"}
+	header = append(header, paramNameSlice...)
+	for _, name := range inSampleMetrics {
+		header = append(header, "is_"+name)
+	}
+	for _, name := range outOfSampleMetrics {
+		header = append(header, "oos_"+name)
+	}
+	header = append(header, "Efficiency")
+
+	// Create a row for each window
+	rows := make([][]string, 0, len(result.Windows))
+	for _, window := range result.Windows {
+		row := []string{
+			strconv.Itoa(window.Index + 1),
+			window.InSampleStart.Format(time.RFC3339),
+			window.InSampleEnd.Format(time.RFC3339),
+			window.OutOfSampleStart.Format(time.RFC3339),
+			window.OutOfSampleEnd.Format(time.RFC3339),
+		}
+		row = append(row, parameterValues(window.InSample.Parameters, paramNameSlice)...)
+		row = append(row, metricValues(window.InSample.Metrics, inSampleMetrics)...)
+		row = append(row, metricValues(window.OutOfSample.Metrics, outOfSampleMetrics)...)
+		row = append(row, strconv.FormatFloat(window.Efficiency, 'f', 4, 64))
+		rows = append(rows, row)
+	}
+
+	return writeCSV(filePath, header, rows)
+}
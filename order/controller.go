@@ -3,6 +3,7 @@ package order
 import (
 	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
@@ -35,6 +36,30 @@ type Controller struct {
 	finish         chan bool
 	status         Status
 	position       map[string]*Position
+	risk           *RiskManager
+	clock          time.Time
+	wallClock      func() time.Time
+	synchronous    bool
+	pending        []orderEvent
+	open           map[int64]core.Order
+}
+
+// orderEvent is an order update waiting to be published to the feed
+type orderEvent struct {
+	order core.Order
+	isNew bool
+}
+
+// orderUpdate is an order changed on the exchange, with its previously stored state
+type orderUpdate struct {
+	previous *core.Order
+	order    core.Order
+}
+
+// liquidator is implemented by exchanges closing positions on their own, such as the paper wallet in futures mode
+type liquidator interface {
+	// Liquidations returns the liquidation orders filled since the last call
+	Liquidations() []core.Order
 }
 
 // NewController creates a new order controller
@@ -52,11 +77,13 @@ func NewController(
 		exchange:       exchange,
 		orderFeed:      orderFeed,
 		tickerInterval: time.Second,
+		wallClock:      time.Now,
 		log:            log,
 		lastPrice:      make(map[string]float64),
 		Results:        make(map[string]*TradeSummary),
 		finish:         make(chan bool),
 		position:       make(map[string]*Position),
+		open:           make(map[int64]core.Order),
 	}
 }
 
@@ -65,9 +92,30 @@ func (c *Controller) SetNotifier(notifier core.Notifier) {
 	c.notifier = notifier
 }
 
+// SetRiskManager configures a risk manager validating every new order before it reaches the exchange
+func (c *Controller) SetRiskManager(risk *RiskManager) {
+	c.risk = risk
+}
+
+// RiskManager returns the configured risk manager, nil when orders are not validated
+func (c *Controller) RiskManager() *RiskManager {
+	return c.risk
+}
+
+// SetSynchronous disables the background ticker so pending orders are only checked when
+// UpdateOrders is called, and delivers feed events right after each operation instead of
+// in new goroutines. Pending orders are tracked in memory rather than queried from storage
+// on every update, so it must be enabled before any order is created.
+// It is used by backtests to make every run reproducible.
+func (c *Controller) SetSynchronous(enabled bool) {
+	c.synchronous = enabled
+}
+
 // OnCandle updates the last known price for a trading pair
 func (c *Controller) OnCandle(candle core.Candle) {
 	c.lastPrice[candle.Pair] = candle.Close
+	c.updateClock(candle.Time)
+	c.updateClock(candle.UpdatedAt)
 }
 
 // Status returns the current controller status
@@ -79,6 +127,11 @@ func (c *Controller) Status() Status {
 func (c *Controller) Start(ctx context.Context) {
 	if c.status != StatusRunning {
 		c.status = StatusRunning
+		if c.synchronous {
+			c.log.Info("Bot started.")
+			return
+		}
+
 		go func() {
 			ticker := time.NewTicker(c.tickerInterval)
 			for {
@@ -101,11 +154,51 @@ func (c *Controller) Stop(ctx context.Context) {
 	if c.status == StatusRunning {
 		c.status = StatusStopped
 		c.updateOrders(ctx)
-		c.finish <- true
+		if !c.synchronous {
+			c.finish <- true
+		}
 		c.log.Info("Bot stopped")
 	}
 }
 
+// UpdateOrders checks pending orders against the exchange and publishes their changes
+func (c *Controller) UpdateOrders(ctx context.Context) {
+	c.updateOrders(ctx)
+}
+
+// Restore rebuilds positions and trade summaries from the orders kept in storage,
+// then reconciles the orders still open against the exchange.
+// It is meant to be called once on startup, before Start, so a restarted bot keeps
+// the average price of open positions and the profit attribution of previous trades.
+func (c *Controller) Restore(ctx context.Context) error {
+	c.mu.Lock()
+
+	// Replay the executed orders in the sequence they were filled, whatever their final status:
+	// canceled, expired or rejected orders may have been partially executed
+	orders, err := c.storage.QueryOrders(ctx, core.OrderQuery{OrderBy: core.OrderFieldUpdatedAt})
+	if err != nil {
+		c.mu.Unlock()
+		return err
+	}
+
+	c.position = make(map[string]*Position)
+	c.Results = make(map[string]*TradeSummary)
+	executed := 0
+	for i := range orders {
+		if orders[i].GetExecutedQuantity() > 0 {
+			c.applyTrade(nil, orders[i])
+			executed++
+		}
+	}
+
+	c.log.Infof("Restored %d executed orders and %d open positions", executed, len(c.position))
+	c.mu.Unlock()
+
+	// Orders filled or canceled while the bot was offline are processed as regular updates
+	c.updateOrders(ctx)
+	return nil
+}
+
 // Account retrieves the current trading account information
 func (c *Controller) Account(ctx context.Context) (core.Account, error) {
 	return c.exchange.Account(ctx)
@@ -116,6 +209,21 @@ func (c *Controller) Position(ctx context.Context, pair string) (asset, quote fl
 	return c.exchange.Position(ctx, pair)
 }
 
+// strategyPosition returns the quantity of the position of a strategy in a pair, negative for shorts
+func (c *Controller) strategyPosition(strategy, pair string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	position, ok := c.position[resultKey(strategy, pair)]
+	if !ok {
+		return 0
+	}
+	if position.Side == core.SideTypeSell {
+		return -position.Quantity
+	}
+	return position.Quantity
+}
+
 // LastQuote retrieves the most recent price for a trading pair
 func (c *Controller) LastQuote(pair string) (float64, error) {
 	return c.exchange.LastQuote(c.ctx, pair)
@@ -130,6 +238,38 @@ func (c *Controller) PositionValue(ctx context.Context, pair string) (float64, e
 	return asset * c.lastPrice[pair], nil
 }
 
+// AssetsInfo retrieves the trading rules of a pair, such as step size and minimum quantity
+func (c *Controller) AssetsInfo(pair string) (core.AssetInfo, error) {
+	return c.exchange.AssetsInfo(pair)
+}
+
+// TradeSummary returns a copy of the trade summary of orders placed on a pair outside of named strategies
+func (c *Controller) TradeSummary(pair string) (*TradeSummary, bool) {
+	return c.strategySummary("", pair)
+}
+
+// strategySummary returns a copy of the trade summary of a strategy in a pair
+func (c *Controller) strategySummary(strategy, pair string) (*TradeSummary, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	summary, ok := c.Results[resultKey(strategy, pair)]
+	if !ok {
+		return nil, false
+	}
+	return summary.clone(), true
+}
+
+// Trades returns the trades kept in the trade journal, ordered by closing time
+func (c *Controller) Trades(ctx context.Context, filters ...core.TradeFilter) ([]*core.Trade, error) {
+	return c.storage.Trades(ctx, filters...)
+}
+
+// QueryTrades returns the trades of the trade journal matching a structured query, ordered by closing time
+func (c *Controller) QueryTrades(ctx context.Context, query core.TradeQuery) ([]*core.Trade, error) {
+	return c.storage.QueryTrades(ctx, query)
+}
+
 // Order retrieves information about a specific order
 func (c *Controller) Order(ctx context.Context, pair string, id int64) (core.Order, error) {
 	return c.exchange.Order(ctx, pair, id)
@@ -138,10 +278,41 @@ func (c *Controller) Order(ctx context.Context, pair string, id int64) (core.Ord
 // CreateOrderOCO creates a One-Cancels-the-Other order pair
 func (c *Controller) CreateOrderOCO(ctx context.Context, side core.SideType, pair string, size, price, stop,
 	stopLimit float64) ([]core.Order, error) {
+	return c.createOrderOCO(ctx, "", side, pair, size, price, stop, stopLimit)
+}
+
+// CreateOrderLimit creates a limit order
+func (c *Controller) CreateOrderLimit(ctx context.Context, side core.SideType, pair string, size, limit float64) (core.Order, error) {
+	return c.createOrderLimit(ctx, "", side, pair, size, limit)
+}
+
+// CreateOrderMarketQuote creates a market order with a specified quote amount
+func (c *Controller) CreateOrderMarketQuote(ctx context.Context, side core.SideType, pair string, amount float64) (core.Order, error) {
+	return c.createOrderMarketQuote(ctx, "", side, pair, amount)
+}
+
+// CreateOrderMarket creates a market order with a specified size
+func (c *Controller) CreateOrderMarket(ctx context.Context, side core.SideType, pair string, size float64) (core.Order, error) {
+	return c.createOrderMarket(ctx, "", side, pair, size)
+}
+
+// CreateOrderStop creates a stop loss order
+func (c *Controller) CreateOrderStop(ctx context.Context, pair string, size float64, limit float64) (core.Order, error) {
+	return c.createOrderStop(ctx, "", pair, size, limit)
+}
+
+// createOrderOCO creates a One-Cancels-the-Other order pair on behalf of a strategy
+func (c *Controller) createOrderOCO(ctx context.Context, strategy string, side core.SideType, pair string,
+	size, price, stop, stopLimit float64) ([]core.Order, error) {
+	defer c.flush()
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.log.Infof("Creating OCO order for %s", pair)
+	if err := c.checkRisk(ctx, side, pair, size*price, 2); err != nil {
+		return nil, err
+	}
+
 	orders, err := c.exchange.CreateOrderOCO(ctx, side, pair, size, price, stop, stopLimit)
 	if err != nil {
 		c.notifyError(err)
@@ -149,107 +320,143 @@ func (c *Controller) CreateOrderOCO(ctx context.Context, side core.SideType, pai
 	}
 
 	for i := range orders {
+		orders[i].Strategy = strategy
 		err := c.storage.CreateOrder(ctx, &orders[i])
 		if err != nil {
 			c.notifyError(err)
 			return nil, err
 		}
-		go c.orderFeed.Publish(orders[i], true)
+		c.track(orders[i])
+		c.publish(orders[i], true)
 	}
 
 	return orders, nil
 }
 
-// CreateOrderLimit creates a limit order
-func (c *Controller) CreateOrderLimit(ctx context.Context, side core.SideType, pair string, size, limit float64) (core.Order, error) {
+// createOrderLimit creates a limit order on behalf of a strategy
+func (c *Controller) createOrderLimit(ctx context.Context, strategy string, side core.SideType, pair string,
+	size, limit float64) (core.Order, error) {
+	defer c.flush()
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.log.Infof("Creating LIMIT %s order for %s", side, pair)
+	if err := c.checkRisk(ctx, side, pair, size*limit, 1); err != nil {
+		return core.Order{}, err
+	}
+
 	order, err := c.exchange.CreateOrderLimit(ctx, side, pair, size, limit)
 	if err != nil {
 		c.notifyError(err)
 		return core.Order{}, err
 	}
+	order.Strategy = strategy
 
 	err = c.storage.CreateOrder(ctx, &order)
 	if err != nil {
 		c.notifyError(err)
 		return core.Order{}, err
 	}
-	go c.orderFeed.Publish(order, true)
+	c.track(order)
+	c.publish(order, true)
 	c.log.Infof("[ORDER CREATED] %s", order)
 	return order, nil
 }
 
-// CreateOrderMarketQuote creates a market order with a specified quote amount
-func (c *Controller) CreateOrderMarketQuote(ctx context.Context, side core.SideType, pair string, amount float64) (core.Order, error) {
+// createOrderMarketQuote creates a market order with a specified quote amount on behalf of a strategy
+func (c *Controller) createOrderMarketQuote(ctx context.Context, strategy string, side core.SideType, pair string,
+	amount float64) (core.Order, error) {
+	defer c.flush()
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.log.Infof("Creating MARKET %s order for %s", side, pair)
+	if err := c.checkRisk(ctx, side, pair, amount, 0); err != nil {
+		return core.Order{}, err
+	}
+
 	order, err := c.exchange.CreateOrderMarketQuote(ctx, side, pair, amount)
 	if err != nil {
 		c.notifyError(err)
 		return core.Order{}, err
 	}
+	order.Strategy = strategy
 
 	err = c.storage.CreateOrder(ctx, &order)
 	if err != nil {
 		c.notifyError(err)
 		return core.Order{}, err
 	}
+	c.track(order)
 
 	// calculate profit
-	c.processTrade(&order)
-	go c.orderFeed.Publish(order, true)
+	c.processTrade(nil, &order)
+	c.publish(order, true)
 	c.log.Infof("[ORDER CREATED] %s", order)
 	return order, err
 }
 
-// CreateOrderMarket creates a market order with a specified size
-func (c *Controller) CreateOrderMarket(ctx context.Context, side core.SideType, pair string, size float64) (core.Order, error) {
+// createOrderMarket creates a market order with a specified size on behalf of a strategy
+func (c *Controller) createOrderMarket(ctx context.Context, strategy string, side core.SideType, pair string,
+	size float64) (core.Order, error) {
+	defer c.flush()
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.log.Infof("Creating MARKET %s order for %s", side, pair)
+	if c.risk != nil {
+		if err := c.checkRisk(ctx, side, pair, size*c.referencePrice(ctx, pair), 0); err != nil {
+			return core.Order{}, err
+		}
+	}
+
 	order, err := c.exchange.CreateOrderMarket(ctx, side, pair, size)
 	if err != nil {
 		c.notifyError(err)
 		return core.Order{}, err
 	}
+	order.Strategy = strategy
 
 	err = c.storage.CreateOrder(ctx, &order)
 	if err != nil {
 		c.notifyError(err)
 		return core.Order{}, err
 	}
+	c.track(order)
 
 	// calculate profit
-	c.processTrade(&order)
-	go c.orderFeed.Publish(order, true)
+	c.processTrade(nil, &order)
+	c.publish(order, true)
 	c.log.Infof("[ORDER CREATED] %s", order)
 	return order, err
 }
 
-// CreateOrderStop creates a stop loss order
-func (c *Controller) CreateOrderStop(ctx context.Context, pair string, size float64, limit float64) (core.Order, error) {
+// createOrderStop creates a stop loss order on behalf of a strategy
+func (c *Controller) createOrderStop(ctx context.Context, strategy string, pair string,
+	size float64, limit float64) (core.Order, error) {
+	defer c.flush()
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	c.log.Infof("Creating STOP order for %s", pair)
+	if err := c.checkRisk(ctx, core.SideTypeSell, pair, size*limit, 1); err != nil {
+		return core.Order{}, err
+	}
+
 	order, err := c.exchange.CreateOrderStop(ctx, pair, size, limit)
 	if err != nil {
 		c.notifyError(err)
 		return core.Order{}, err
 	}
+	order.Strategy = strategy
 
 	err = c.storage.CreateOrder(ctx, &order)
 	if err != nil {
 		c.notifyError(err)
 		return core.Order{}, err
 	}
-	go c.orderFeed.Publish(order, true)
+	c.track(order)
+	c.publish(order, true)
 	c.log.Infof("[ORDER CREATED] %s", order)
 	return order, nil
 }
@@ -265,34 +472,39 @@ func (c *Controller) Cancel(ctx context.Context, order core.Order) error {
 		return err
 	}
 
+	// Keep the executions already processed, later ones are processed by the next update
+	if stored, err := c.storedOrder(ctx, order); err != nil {
+		c.notifyError(err)
+	} else if stored != nil {
+		order = *stored
+	}
+
 	order.Status = core.OrderStatusTypePendingCancel
 	err = c.storage.UpdateOrder(ctx, &order)
 	if err != nil {
 		c.notifyError(err)
 		return err
 	}
+	c.track(order)
 	c.log.Infof("[ORDER CANCELED] %s", order)
 	return nil
 }
 
 // updateOrders checks for status changes in pending orders
 func (c *Controller) updateOrders(ctx context.Context) {
+	defer c.flush()
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
 	// Get pending orders
-	orders, err := c.storage.Orders(ctx, core.WithStatusIn(
-		core.OrderStatusTypeNew,
-		core.OrderStatusTypePartiallyFilled,
-		core.OrderStatusTypePendingCancel,
-	))
+	orders, err := c.pendingOrders(ctx)
 	if err != nil {
 		c.notifyError(err)
 		return
 	}
 
 	// For each pending order, check for updates
-	var updatedOrders []core.Order
+	var updatedOrders []orderUpdate
 	for _, order := range orders {
 		excOrder, err := c.exchange.Order(ctx, order.Pair, order.ExchangeID)
 		if err != nil {
@@ -300,74 +512,360 @@ func (c *Controller) updateOrders(ctx context.Context) {
 			continue
 		}
 
-		// No status change
-		if excOrder.Status == order.Status {
+		// No status change or new execution
+		if excOrder.Status == order.Status && excOrder.GetExecutedQuantity() == order.GetExecutedQuantity() {
 			continue
 		}
 
 		excOrder.ID = order.ID
+		excOrder.Strategy = order.Strategy
 		err = c.storage.UpdateOrder(ctx, &excOrder)
 		if err != nil {
 			c.notifyError(err)
 			continue
 		}
+		c.track(excOrder)
 
 		c.log.Infof("[ORDER %s] %s", excOrder.Status, excOrder)
-		updatedOrders = append(updatedOrders, excOrder)
+		updatedOrders = append(updatedOrders, orderUpdate{previous: order, order: excOrder})
+	}
+
+	for _, update := range updatedOrders {
+		c.processTrade(update.previous, &update.order)
+		c.publish(update.order, false)
+	}
+
+	c.processLiquidations(ctx)
+}
+
+// processLiquidations closes the positions of the pairs liquidated by the exchange. Each strategy
+// holding the pair gets its share of the liquidation order, so the loss reaches its trade results,
+// the trade journal and the risk manager.
+// Note: This function assumes the mutex is already locked by the caller
+func (c *Controller) processLiquidations(ctx context.Context) {
+	exch, ok := c.exchange.(liquidator)
+	if !ok {
+		return
+	}
+
+	for _, liquidation := range exch.Liquidations() {
+		c.log.Warnf("[ORDER LIQUIDATION] %s", liquidation)
+
+		keys := make([]string, 0)
+		for key, position := range c.position {
+			if position.Pair == liquidation.Pair && position.Side != liquidation.Side {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			position := c.position[key]
+			share := position.Quantity / liquidation.Quantity
+
+			order := liquidation
+			order.Strategy = position.Strategy
+			order.Quantity = position.Quantity
+			order.ExecutedQuantity = position.Quantity
+			order.Fee = liquidation.Fee * share
+
+			if err := c.storage.CreateOrder(ctx, &order); err != nil {
+				c.notifyError(err)
+				continue
+			}
+			c.processTrade(nil, &order)
+			c.publish(order, true)
+		}
+	}
+}
+
+// pendingOrders returns the orders waiting for a status change, oldest first
+func (c *Controller) pendingOrders(ctx context.Context) ([]*core.Order, error) {
+	if !c.synchronous {
+		return c.storage.QueryOrders(ctx, core.OrderQuery{
+			Status: []core.OrderStatusType{
+				core.OrderStatusTypeNew,
+				core.OrderStatusTypePartiallyFilled,
+				core.OrderStatusTypePendingCancel,
+			},
+			OrderBy: core.OrderFieldID,
+		})
 	}
 
-	for _, processOrder := range updatedOrders {
-		c.processTrade(&processOrder)
-		c.orderFeed.Publish(processOrder, false)
+	orders := make([]*core.Order, 0, len(c.open))
+	for id := range c.open {
+		order := c.open[id]
+		orders = append(orders, &order)
 	}
+	sort.Slice(orders, func(i, j int) bool {
+		return orders[i].ID < orders[j].ID
+	})
+
+	return orders, nil
 }
 
-// processTrade updates the trade summary and position data when an order is filled
-func (c *Controller) processTrade(order *core.Order) {
-	if order.Status != core.OrderStatusTypeFilled {
+// storedOrder returns the last stored state of a pending order, or nil when it is not pending
+func (c *Controller) storedOrder(ctx context.Context, order core.Order) (*core.Order, error) {
+	if c.synchronous {
+		stored, ok := c.open[order.ID]
+		if !ok {
+			return nil, nil
+		}
+		return &stored, nil
+	}
+
+	orders, err := c.storage.QueryOrders(ctx, core.OrderQuery{
+		Status: []core.OrderStatusType{core.OrderStatusTypeNew, core.OrderStatusTypePartiallyFilled},
+		Pair:   order.Pair,
+		Filters: []core.OrderFilter{func(o core.Order) bool {
+			return o.ID == order.ID
+		}},
+	})
+	if err != nil || len(orders) == 0 {
+		return nil, err
+	}
+	return orders[0], nil
+}
+
+// track keeps the in-memory set of pending orders used in synchronous mode
+func (c *Controller) track(order core.Order) {
+	if !c.synchronous {
 		return
 	}
 
+	switch order.Status {
+	case core.OrderStatusTypeNew, core.OrderStatusTypePartiallyFilled, core.OrderStatusTypePendingCancel:
+		c.open[order.ID] = order
+	default:
+		delete(c.open, order.ID)
+	}
+}
+
+// publish sends an order event to the feed. In synchronous mode the event is queued
+// until flush, so consumers calling back into the controller don't deadlock on its lock.
+func (c *Controller) publish(order core.Order, isNew bool) {
+	switch {
+	case c.synchronous:
+		c.pending = append(c.pending, orderEvent{order: order, isNew: isNew})
+	case isNew:
+		go c.orderFeed.Publish(order, isNew)
+	default:
+		c.orderFeed.Publish(order, isNew)
+	}
+}
+
+// flush publishes the queued order events, it must be called without holding the lock
+func (c *Controller) flush() {
+	c.mu.Lock()
+	events := c.pending
+	c.pending = nil
+	c.mu.Unlock()
+
+	for _, event := range events {
+		c.orderFeed.Publish(event.order, event.isNew)
+	}
+}
+
+// processTrade updates the trade summary and position data with the quantity an order executed
+// since its previous state, which is nil for new orders
+func (c *Controller) processTrade(previous, order *core.Order) {
+	result := c.applyTrade(previous, order)
+	if result != nil {
+		c.saveTrade(result)
+		c.notifyTradeResult(resultKey(order.Strategy, order.Pair), result)
+	}
+}
+
+// saveTrade persists a trade result in the trade journal
+func (c *Controller) saveTrade(result *TradeResult) {
+	trade := core.Trade{
+		Strategy:      result.Strategy,
+		Pair:          result.Pair,
+		Side:          result.Side,
+		EntryOrderID:  result.EntryOrderID,
+		ExitOrderID:   result.ExitOrderID,
+		EntryPrice:    result.EntryPrice,
+		ExitPrice:     result.ExitPrice,
+		Quantity:      result.Quantity,
+		Profit:        result.ProfitValue,
+		ProfitPercent: result.ProfitPercent,
+		Fee:           result.Fee,
+		Duration:      result.Duration,
+		OpenedAt:      result.CreatedAt.Add(-result.Duration),
+		ClosedAt:      result.CreatedAt,
+	}
+
+	if err := c.storage.CreateTrade(c.ctx, &trade); err != nil {
+		c.notifyError(err)
+	}
+}
+
+// applyTrade registers the quantity an order executed since its previous state in the trade summary
+// and position, returning the trade result when the execution closes or reduces a position
+func (c *Controller) applyTrade(previous, order *core.Order) *TradeResult {
+	fill := executedFill(previous, order)
+	if fill == nil {
+		return nil
+	}
+
 	// Initialize results map if needed
-	if _, ok := c.Results[order.Pair]; !ok {
-		c.Results[order.Pair] = &TradeSummary{Pair: order.Pair}
+	key := resultKey(order.Strategy, order.Pair)
+	if _, ok := c.Results[key]; !ok {
+		c.Results[key] = &TradeSummary{Pair: order.Pair, Strategy: order.Strategy}
 	}
 
-	// Register order volume
-	c.Results[order.Pair].Volume += order.Price * order.Quantity
+	// Register order volume, slippage and fees
+	c.Results[key].Volume += fill.Price * fill.Quantity
+	c.Results[key].Slippage += fill.Slippage
+	c.Results[key].Fees += feeValue(fill, fill.Price)
 
 	// Update position size / avg price
-	c.updatePosition(order)
+	c.updateClock(order.UpdatedAt)
+	result := c.updatePosition(fill)
+	order.Profit, order.ProfitValue = fill.Profit, fill.ProfitValue
+	return result
+}
+
+// executedFill returns the execution of an order since its previous state, or nil when nothing was executed.
+// Its quantity, fee and slippage are the increments, and its price the average price of the increment.
+func executedFill(previous, order *core.Order) *core.Order {
+	var executed float64
+	if previous != nil {
+		executed = previous.GetExecutedQuantity()
+	}
+
+	fill := *order
+	fill.Quantity = order.GetExecutedQuantity() - executed
+	if fill.Quantity <= 0 {
+		return nil
+	}
+
+	if previous != nil {
+		fill.Fee -= previous.Fee
+		fill.Slippage -= previous.Slippage
+		if executed > 0 && order.Price != previous.Price {
+			fill.Price = (order.Price*order.GetExecutedQuantity() - previous.Price*executed) / fill.Quantity
+		}
+	}
+	return &fill
 }
 
 // updatePosition updates the current position based on a new order
-func (c *Controller) updatePosition(o *core.Order) {
-	// Get filled orders before the current order
-	position, ok := c.position[o.Pair]
+func (c *Controller) updatePosition(o *core.Order) *TradeResult {
+	// Positions are tracked per strategy, so strategies sharing a pair do not net each other out
+	key := resultKey(o.Strategy, o.Pair)
+	position, ok := c.position[key]
 	if !ok {
-		c.position[o.Pair] = &Position{
-			AvgPrice:  o.Price,
-			Quantity:  o.Quantity,
-			CreatedAt: o.CreatedAt,
-			Side:      o.Side,
-		}
-		return
+		c.position[key] = newPosition(o)
+		return nil
 	}
 
 	result, closed := position.Update(o)
 	if closed {
-		delete(c.position, o.Pair)
+		delete(c.position, key)
 	}
 
 	if result != nil {
-		c.recordTradeResult(o.Pair, result)
-		c.notifyTradeResult(o.Pair, result)
+		c.recordTradeResult(key, result)
+		if c.risk != nil {
+			c.risk.onTradeResult(result)
+		}
 	}
+
+	return result
+}
+
+// checkRisk validates a new order against the risk manager, notifying rejections.
+// value is the order value in quote currency and newOrders the number of orders added to the book.
+func (c *Controller) checkRisk(ctx context.Context, side core.SideType, pair string, value float64, newOrders int) error {
+	if c.risk == nil {
+		return nil
+	}
+
+	openOrders := 0
+	if c.risk.Limits().MaxOpenOrders > 0 {
+		orders, err := c.storage.QueryOrders(ctx, core.OrderQuery{
+			Status: []core.OrderStatusType{
+				core.OrderStatusTypeNew,
+				core.OrderStatusTypePartiallyFilled,
+			},
+		})
+		if err != nil {
+			c.notifyError(err)
+			return err
+		}
+		openOrders = len(orders)
+	}
+
+	// exposure is signed, short positions have negative values
+	var exposure, total float64
+	for _, position := range c.position {
+		price, ok := c.lastPrice[position.Pair]
+		if !ok {
+			price = position.AvgPrice
+		}
+
+		value := position.Quantity * price
+		total += value
+		if position.Pair == pair {
+			if position.Side == core.SideTypeSell {
+				value = -value
+			}
+			exposure += value
+		}
+	}
+
+	err := c.risk.check(riskCheck{
+		pair:       pair,
+		side:       side,
+		value:      value,
+		exposure:   exposure,
+		total:      total,
+		openOrders: openOrders,
+		newOrders:  newOrders,
+		now:        c.now(),
+	})
+	if err != nil {
+		c.notifyError(err)
+		return err
+	}
+
+	return nil
+}
+
+// referencePrice returns the last known price of a pair, used to value market orders
+func (c *Controller) referencePrice(ctx context.Context, pair string) float64 {
+	if price, ok := c.lastPrice[pair]; ok {
+		return price
+	}
+
+	price, err := c.exchange.LastQuote(ctx, pair)
+	if err != nil {
+		return 0
+	}
+	return price
+}
+
+// updateClock advances the controller clock, driven by candles and orders so backtests use market time
+func (c *Controller) updateClock(t time.Time) {
+	if t.After(c.clock) {
+		c.clock = t
+	}
+}
+
+// now returns the market time in synchronous mode, once a candle or an order is received,
+// and the wall clock otherwise: live candles only move the market time when they close
+func (c *Controller) now() time.Time {
+	if !c.synchronous || c.clock.IsZero() {
+		return c.wallClock()
+	}
+	return c.clock
 }
 
 // recordTradeResult updates the trade summary with a new trade result
-func (c *Controller) recordTradeResult(pair string, result *TradeResult) {
-	summary := c.Results[pair]
+func (c *Controller) recordTradeResult(key string, result *TradeResult) {
+	summary := c.Results[key]
+	summary.Trades = append(summary.Trades, *result)
 
 	if result.ProfitPercent >= 0 {
 		if result.Side == core.SideTypeBuy {
@@ -389,13 +887,36 @@ func (c *Controller) recordTradeResult(pair string, result *TradeResult) {
 }
 
 // notifyTradeResult sends a notification about a completed trade
-func (c *Controller) notifyTradeResult(pair string, result *TradeResult) {
-	_, quote := exchange.SplitAssetQuote(pair)
+func (c *Controller) notifyTradeResult(key string, result *TradeResult) {
+	_, quote := exchange.SplitAssetQuote(result.Pair)
+
+	c.notify(fmt.Sprintf("[PROFIT] %f %s (%f %%), NET %f %s\n",
+		result.ProfitValue, quote, result.ProfitPercent*100, result.NetProfit(), quote), true)
+
+	c.notify(c.Results[key].String())
+}
+
+// StrategyResults returns copies of the trade summaries attributed to the given strategy, one per pair
+func (c *Controller) StrategyResults(strategy string) []*TradeSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-	c.notify(fmt.Sprintf("[PROFIT] %f %s (%f %%)\n",
-		result.ProfitValue, quote, result.ProfitPercent*100), true)
+	summaries := make([]*TradeSummary, 0)
+	for _, summary := range c.Results {
+		if summary.Strategy == strategy {
+			summaries = append(summaries, summary.clone())
+		}
+	}
+	return summaries
+}
 
-	c.notify(c.Results[pair].String())
+// resultKey builds the key used to index positions and results.
+// Orders placed outside of a named strategy are keyed by pair only.
+func resultKey(strategy, pair string) string {
+	if strategy == "" {
+		return pair
+	}
+	return strategy + "/" + pair
 }
 
 // notify sends a message through the logging system and notifier
@@ -2,6 +2,7 @@ package order
 
 import (
 	"context"
+	"sync"
 	"testing"
 	"time"
 
@@ -232,3 +233,587 @@ func TestController_Position(t *testing.T) {
 	assert.Equal(t, 1.0, asset)
 	assert.Equal(t, 1500.0, quote)
 }
+
+func TestController_StrategyBroker(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(), exchange.WithPaperAsset("USDT", 3000))
+	controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+
+	trend := controller.StrategyBroker("trend")
+	reversion := controller.StrategyBroker("reversion")
+
+	wallet.OnCandle(core.Candle{Time: time.Now(), Pair: "BTCUSDT", Close: 1000})
+	order, err := trend.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+	assert.Equal(t, "trend", order.Strategy)
+
+	_, err = reversion.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	// each strategy keeps its own position in the same pair
+	require.Equal(t, 1.0, controller.position["trend/BTCUSDT"].Quantity)
+	require.Equal(t, 1.0, controller.position["reversion/BTCUSDT"].Quantity)
+	require.Nil(t, controller.position["BTCUSDT"])
+
+	// each broker sees its own position, not the wallet balance
+	asset, quote, err := trend.Position(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, asset)
+	assert.Equal(t, 1000.0, quote)
+
+	asset, _, err = controller.Position(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, asset)
+
+	wallet.OnCandle(core.Candle{Time: time.Now(), Pair: "BTCUSDT", Close: 1200})
+	_, err = trend.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	require.Nil(t, controller.position["trend/BTCUSDT"])
+	require.Equal(t, 1.0, controller.position["reversion/BTCUSDT"].Quantity)
+
+	asset, _, err = trend.Position(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	assert.Zero(t, asset)
+
+	results := controller.StrategyResults("trend")
+	require.Len(t, results, 1)
+	assert.Equal(t, "BTCUSDT", results[0].Pair)
+	assert.Equal(t, []float64{200.0}, results[0].WinLong)
+	assert.Empty(t, controller.Results["reversion/BTCUSDT"].Win())
+
+	orders, err := storage.Orders(ctx, func(o core.Order) bool { return o.Strategy == "trend" })
+	require.NoError(t, err)
+	assert.Len(t, orders, 2)
+}
+
+func TestController_ConcurrentResults(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(), exchange.WithPaperAsset("USDT", 100000))
+	controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+	broker := controller.StrategyBroker("trend")
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", High: 1000, Close: 1000})
+
+	// trades recorded while the summaries are read, as by strategies in live mode
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 20; i++ {
+			_, err := broker.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+			assert.NoError(t, err)
+			_, err = broker.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 1)
+			assert.NoError(t, err)
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		if summary, ok := broker.TradeSummary("BTCUSDT"); ok {
+			_ = summary.Profit()
+		}
+		for _, summary := range controller.StrategyResults("trend") {
+			_ = summary.Profit()
+		}
+	}
+	wg.Wait()
+
+	summary, ok := broker.TradeSummary("BTCUSDT")
+	require.True(t, ok)
+	require.Len(t, summary.Trades, 20)
+
+	// the summary is a copy
+	summary.Trades = nil
+	summary, _ = broker.TradeSummary("BTCUSDT")
+	require.Len(t, summary.Trades, 20)
+}
+
+func TestController_Restore(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(), exchange.WithPaperAsset("USDT", 3000))
+	controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+
+	wallet.OnCandle(core.Candle{Time: time.Now(), Pair: "BTCUSDT", High: 1000, Close: 1000})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	wallet.OnCandle(core.Candle{Time: time.Now(), Pair: "BTCUSDT", High: 2000, Close: 2000})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	wallet.OnCandle(core.Candle{Time: time.Now(), Pair: "BTCUSDT", High: 3000, Close: 3000})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	// limit order filled while the bot is offline
+	_, err = controller.CreateOrderLimit(ctx, core.SideTypeSell, "BTCUSDT", 1, 4000)
+	require.NoError(t, err)
+	wallet.OnCandle(core.Candle{Time: time.Now(), Pair: "BTCUSDT", High: 4000, Close: 4000})
+
+	// new controller sharing the same storage, as after a restart
+	restarted := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+	require.NoError(t, restarted.Restore(ctx))
+
+	require.Nil(t, restarted.position["BTCUSDT"])
+	require.Equal(t, []float64{1500.0, 2500.0}, restarted.Results["BTCUSDT"].WinLong)
+
+	orders, err := storage.Orders(ctx, core.WithStatus(core.OrderStatusTypeNew))
+	require.NoError(t, err)
+	require.Empty(t, orders)
+}
+
+func TestController_RestorePartialExecution(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(), exchange.WithPaperAsset("USDT", 3000))
+
+	// limit order expired after executing half of its quantity
+	now := time.Now()
+	require.NoError(t, storage.CreateOrder(ctx, &core.Order{
+		ExchangeID:       1,
+		Pair:             "BTCUSDT",
+		Side:             core.SideTypeBuy,
+		Type:             core.OrderTypeLimit,
+		Status:           core.OrderStatusTypeExpired,
+		Price:            1000,
+		Quantity:         1,
+		ExecutedQuantity: 0.5,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}))
+
+	// order canceled before any execution
+	require.NoError(t, storage.CreateOrder(ctx, &core.Order{
+		ExchangeID: 2,
+		Pair:       "BTCUSDT",
+		Side:       core.SideTypeBuy,
+		Type:       core.OrderTypeLimit,
+		Status:     core.OrderStatusTypeCanceled,
+		Price:      900,
+		Quantity:   1,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}))
+
+	controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+	require.NoError(t, controller.Restore(ctx))
+
+	position := controller.position["BTCUSDT"]
+	require.NotNil(t, position)
+	assert.Equal(t, 0.5, position.Quantity)
+	assert.Equal(t, 1000.0, position.AvgPrice)
+	assert.Equal(t, 500.0, controller.Results["BTCUSDT"].Volume)
+}
+
+func TestController_Synchronous(t *testing.T) {
+	ctx := context.Background()
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(), exchange.WithPaperAsset("USDT", 3000))
+	feed := NewOrderFeed()
+	feed.SetSynchronous(true)
+	controller := NewController(ctx, wallet, storage, getLog(), feed)
+	controller.SetSynchronous(true)
+
+	var events []core.OrderStatusType
+	feed.Subscribe("BTCUSDT", func(order core.Order) {
+		events = append(events, order.Status)
+
+		// consumers may call back into the controller
+		_, _, err := controller.Position(ctx, order.Pair)
+		require.NoError(t, err)
+	}, false)
+
+	controller.Start(ctx)
+	defer controller.Stop(ctx)
+
+	wallet.OnCandle(core.Candle{Time: time.Now(), Pair: "BTCUSDT", High: 1500, Close: 1500})
+	_, err = controller.CreateOrderLimit(ctx, core.SideTypeBuy, "BTCUSDT", 1, 1000)
+	require.NoError(t, err)
+	require.Equal(t, []core.OrderStatusType{core.OrderStatusTypeNew}, events)
+
+	// the fill is only seen when orders are updated
+	wallet.OnCandle(core.Candle{Time: time.Now(), Pair: "BTCUSDT", Low: 990, Close: 1000})
+	require.Len(t, events, 1)
+
+	controller.UpdateOrders(ctx)
+	require.Equal(t, []core.OrderStatusType{core.OrderStatusTypeNew, core.OrderStatusTypeFilled}, events)
+	require.Equal(t, 1.0, controller.position["BTCUSDT"].Quantity)
+}
+
+func TestController_Trades(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(), exchange.WithPaperAsset("USDT", 3000))
+	controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	wallet.OnCandle(core.Candle{Time: start, Pair: "BTCUSDT", High: 1000, Close: 1000})
+	first, err := controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	wallet.OnCandle(core.Candle{Time: start.Add(time.Hour), Pair: "BTCUSDT", High: 2000, Close: 2000})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	wallet.OnCandle(core.Candle{Time: start.Add(2 * time.Hour), Pair: "BTCUSDT", High: 3000, Close: 3000})
+	exit, err := controller.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	wallet.OnCandle(core.Candle{Time: start.Add(3 * time.Hour), Pair: "BTCUSDT", High: 750, Close: 750})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	trades, err := controller.Trades(ctx)
+	require.NoError(t, err)
+	require.Len(t, trades, 2)
+
+	trade := trades[0]
+	assert.NotZero(t, trade.ID)
+	assert.Equal(t, "BTCUSDT", trade.Pair)
+	assert.Equal(t, core.SideTypeBuy, trade.Side)
+	assert.Equal(t, first.ID, trade.EntryOrderID)
+	assert.Equal(t, exit.ID, trade.ExitOrderID)
+	assert.Equal(t, 1500.0, trade.EntryPrice)
+	assert.Equal(t, 3000.0, trade.ExitPrice)
+	assert.Equal(t, 1.0, trade.Quantity)
+	assert.Equal(t, 1500.0, trade.Profit)
+	assert.Equal(t, 2*time.Hour, trade.Duration)
+	assert.True(t, trade.OpenedAt.Equal(start))
+	assert.Equal(t, -750.0, trades[1].Profit)
+
+	trades, err = controller.Trades(ctx, core.WithTradeClosedBetween(start, start.Add(2*time.Hour)))
+	require.NoError(t, err)
+	require.Len(t, trades, 1)
+	assert.Equal(t, exit.ID, trades[0].ExitOrderID)
+
+	trades, err = controller.Trades(ctx, core.WithTradePair("ETHUSDT"))
+	require.NoError(t, err)
+	require.Empty(t, trades)
+
+	// trades do not show up as orders
+	orders, err := storage.Orders(ctx)
+	require.NoError(t, err)
+	require.Len(t, orders, 4)
+}
+
+func TestController_Slippage(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(),
+		exchange.WithPaperAsset("USDT", 3000), exchange.WithPaperSlippage(exchange.NewFixedSlippage(100)))
+	controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", High: 1000, Close: 1000})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", High: 2000, Close: 2000})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	summary, ok := controller.TradeSummary("BTCUSDT")
+	require.True(t, ok)
+	assert.InDelta(t, 30.0, summary.Slippage, 1e-9)
+	assert.InDelta(t, 970.0, summary.Profit(), 1e-9)
+
+	// slippage is stored with the orders
+	orders, err := storage.Orders(ctx)
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	assert.InDelta(t, 10.0, orders[0].Slippage, 1e-9)
+
+	// stop exits are booked at the price after slippage
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", High: 1000, Low: 1000, Close: 1000})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+	_, err = controller.CreateOrderStop(ctx, "BTCUSDT", 1, 900)
+	require.NoError(t, err)
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", High: 950, Low: 850, Close: 900})
+	controller.UpdateOrders(ctx)
+	assert.Empty(t, controller.position)
+
+	// summaries are copies, taken again after the new trades
+	summary, ok = controller.TradeSummary("BTCUSDT")
+	require.True(t, ok)
+	require.Len(t, summary.Trades, 2)
+	assert.InDelta(t, 891.0, summary.Trades[1].ExitPrice, 1e-9)
+	assert.InDelta(t, -119.0, summary.Trades[1].ProfitValue, 1e-9)
+	assert.InDelta(t, 49.0, summary.Slippage, 1e-9)
+
+	// the trade results match the wallet balance
+	_, quote, err := wallet.Position(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	assert.InDelta(t, 3000+summary.Profit(), quote, 1e-9)
+}
+
+func TestController_Fees(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(),
+		exchange.WithPaperAsset("USDT", 3000), exchange.WithPaperFee(0.001, 0.001))
+	controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", High: 1000, Close: 1000})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+
+	// the position holds the quantity received after fees
+	position := controller.position[resultKey("", "BTCUSDT")]
+	require.NotNil(t, position)
+	assert.InDelta(t, 0.999, position.Quantity, 1e-12)
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", High: 2000, Close: 2000})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 0.999)
+	require.NoError(t, err)
+	assert.Empty(t, controller.position)
+
+	summary, ok := controller.TradeSummary("BTCUSDT")
+	require.True(t, ok)
+	require.Len(t, summary.Trades, 1)
+	assert.InDelta(t, 999.0, summary.Profit(), 1e-9)
+	assert.InDelta(t, 2.998, summary.Fees, 1e-9)
+	assert.InDelta(t, 996.002, summary.NetProfit(), 1e-9)
+
+	// the net profit matches the wallet balance
+	_, quote, err := wallet.Position(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	assert.InDelta(t, 3000+summary.NetProfit(), quote, 1e-9)
+
+	trades, err := controller.Trades(ctx)
+	require.NoError(t, err)
+	require.Len(t, trades, 1)
+	assert.InDelta(t, 2.998, trades[0].Fee, 1e-9)
+
+	orders, err := storage.Orders(ctx)
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	assert.Equal(t, "BTC", orders[0].FeeAsset)
+	assert.Equal(t, "USDT", orders[1].FeeAsset)
+
+	// the fees row only counts closed trades, so it reconciles with the net profit
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+	summary, ok = controller.TradeSummary("BTCUSDT")
+	require.True(t, ok)
+	assert.InDelta(t, 4.998, summary.Fees, 1e-9)
+	assert.Contains(t, summary.String(), "2.9980 USDT")
+	assert.NotContains(t, summary.String(), "4.9980 USDT")
+}
+
+func TestController_PartialFills(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(),
+		exchange.WithPaperAsset("USDT", 2000), exchange.WithPaperParticipation(0.1))
+	controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+	key := resultKey("", "BTCUSDT")
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 100, Volume: 40})
+	order, err := controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 10)
+	require.NoError(t, err)
+	require.Equal(t, core.OrderStatusTypePartiallyFilled, order.Status)
+	assert.Equal(t, 4.0, controller.position[key].Quantity)
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 110, Volume: 40})
+	controller.UpdateOrders(ctx)
+	assert.Equal(t, 8.0, controller.position[key].Quantity)
+	assert.InDelta(t, 105.0, controller.position[key].AvgPrice, 1e-9)
+
+	// no new execution, nothing changes
+	controller.UpdateOrders(ctx)
+	assert.Equal(t, 8.0, controller.position[key].Quantity)
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 120, Volume: 40})
+	controller.UpdateOrders(ctx)
+	assert.Equal(t, 10.0, controller.position[key].Quantity)
+	assert.InDelta(t, 108.0, controller.position[key].AvgPrice, 1e-9)
+
+	orders, err := storage.Orders(ctx)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, core.OrderStatusTypeFilled, orders[0].Status)
+	assert.Equal(t, 10.0, orders[0].ExecutedQuantity)
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 130, Volume: 1000})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 10)
+	require.NoError(t, err)
+	assert.Empty(t, controller.position)
+
+	summary, ok := controller.TradeSummary("BTCUSDT")
+	require.True(t, ok)
+	assert.InDelta(t, 220.0, summary.Profit(), 1e-9)
+	assert.InDelta(t, 2380.0, summary.Volume, 1e-9)
+
+	t.Run("cancel after a partial fill", func(t *testing.T) {
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 110, Volume: 40})
+		order, err := controller.CreateOrderLimit(ctx, core.SideTypeBuy, "BTCUSDT", 10, 100)
+		require.NoError(t, err)
+
+		wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 95, Volume: 40})
+		controller.UpdateOrders(ctx)
+		assert.Equal(t, 4.0, controller.position[key].Quantity)
+
+		require.NoError(t, controller.Cancel(ctx, order))
+		controller.UpdateOrders(ctx)
+		assert.Equal(t, 4.0, controller.position[key].Quantity)
+
+		orders, err := storage.QueryOrders(ctx, core.OrderQuery{Status: []core.OrderStatusType{core.OrderStatusTypeCanceled}})
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		assert.Equal(t, 4.0, orders[0].ExecutedQuantity)
+	})
+}
+
+func TestController_Intrabar(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(),
+		exchange.WithPaperAsset("USDT", 1000), exchange.WithPaperIntrabar(exchange.IntrabarWorstCase))
+	controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", High: 1000, Low: 1000, Close: 1000})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+	_, err = controller.CreateOrderStop(ctx, "BTCUSDT", 1, 950)
+	require.NoError(t, err)
+
+	// the candle gaps below the stop, which fills at the open
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Open: 800, High: 820, Low: 780, Close: 800})
+	controller.UpdateOrders(ctx)
+	assert.Empty(t, controller.position)
+
+	summary, ok := controller.TradeSummary("BTCUSDT")
+	require.True(t, ok)
+	require.Len(t, summary.Trades, 1)
+	assert.Equal(t, 800.0, summary.Trades[0].ExitPrice)
+	assert.InDelta(t, -200.0, summary.Profit(), 1e-9)
+
+	// the trade result matches the wallet balance
+	_, quote, err := wallet.Position(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	assert.InDelta(t, 1000+summary.Profit(), quote, 1e-9)
+}
+
+func TestController_Latency(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(),
+		exchange.WithPaperAsset("USDT", 1000), exchange.WithPaperLatency(exchange.NextOpenLatency))
+	controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+	key := resultKey("", "BTCUSDT")
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 100})
+	order, err := controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.NoError(t, err)
+	require.Equal(t, core.OrderStatusTypeNew, order.Status)
+	assert.Empty(t, controller.position)
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Open: 102, Close: 105})
+	controller.UpdateOrders(ctx)
+	assert.Equal(t, 1.0, controller.position[key].Quantity)
+	assert.Equal(t, 102.0, controller.position[key].AvgPrice)
+
+	orders, err := storage.Orders(ctx)
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, core.OrderStatusTypeFilled, orders[0].Status)
+	assert.Equal(t, 102.0, orders[0].Price)
+}
+
+func TestController_Futures(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(),
+		exchange.WithPaperAsset("USDT", 100), exchange.WithPaperFutures(0.005),
+		exchange.WithPaperLeverage("BTCUSDT", 10, exchange.MarginTypeIsolated))
+	controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 100})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 10)
+	require.NoError(t, err)
+
+	// reverse the short into a long
+	wallet.OnCandle(core.Candle{Pair: "BTCUSDT", Close: 95})
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 20)
+	require.NoError(t, err)
+
+	key := resultKey("", "BTCUSDT")
+	assert.Equal(t, core.SideTypeBuy, controller.position[key].Side)
+	assert.Equal(t, 10.0, controller.position[key].Quantity)
+	assert.Equal(t, 95.0, controller.position[key].AvgPrice)
+
+	summary, ok := controller.TradeSummary("BTCUSDT")
+	require.True(t, ok)
+	require.Len(t, summary.WinShort, 1)
+	assert.InDelta(t, 50.0, summary.Profit(), 1e-9)
+
+	_, quote, err := wallet.Position(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	assert.InDelta(t, 150.0, quote, 1e-9)
+}
+
+func TestController_Liquidation(t *testing.T) {
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(),
+		exchange.WithPaperAsset("USDT", 1000), exchange.WithPaperFutures(0.005),
+		exchange.WithPaperLeverage("BTCUSDT", 10, exchange.MarginTypeIsolated))
+	feed := NewOrderFeed()
+	feed.SetSynchronous(true)
+	controller := NewController(ctx, wallet, storage, getLog(), feed)
+	controller.SetSynchronous(true)
+	controller.SetRiskManager(NewRiskManager(RiskLimits{MaxDailyLoss: 100}))
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	candle := core.Candle{Pair: "BTCUSDT", Time: start, High: 100, Low: 100, Close: 100}
+	wallet.OnCandle(candle)
+	controller.OnCandle(candle)
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 50)
+	require.NoError(t, err)
+
+	// the candle reaches the liquidation price of the long
+	price := wallet.LiquidationPrice("BTCUSDT")
+	candle = core.Candle{Pair: "BTCUSDT", Time: start.Add(time.Hour), Open: 95, High: 96, Low: 85, Close: 95}
+	wallet.OnCandle(candle)
+	controller.OnCandle(candle)
+	controller.UpdateOrders(ctx)
+	assert.Empty(t, controller.position)
+
+	summary, ok := controller.TradeSummary("BTCUSDT")
+	require.True(t, ok)
+	require.Len(t, summary.LoseLong, 1)
+	assert.InDelta(t, (price-100)*50, summary.Profit(), 1e-9)
+	assert.InDelta(t, -500.0, summary.NetProfit(), 1e-9)
+
+	// the net loss matches the wallet balance
+	_, quote, err := wallet.Position(ctx, "BTCUSDT")
+	require.NoError(t, err)
+	assert.InDelta(t, 1000+summary.NetProfit(), quote, 1e-9)
+
+	trades, err := controller.Trades(ctx)
+	require.NoError(t, err)
+	require.Len(t, trades, 1)
+	assert.InDelta(t, price, trades[0].ExitPrice, 1e-9)
+
+	// the loss counts towards the daily loss limit
+	assert.InDelta(t, summary.Profit(), controller.RiskManager().DailyPnL(), 1e-9)
+	_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+	require.ErrorIs(t, err, ErrMaxDailyLoss)
+}
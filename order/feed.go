@@ -26,6 +26,7 @@ type Feed struct {
 	mu                    sync.RWMutex
 	OrderFeeds            map[string]*DataFeed
 	SubscriptionsBySymbol map[string][]Subscription
+	synchronous           bool
 }
 
 // NewOrderFeed creates a new order feed manager
@@ -36,6 +37,14 @@ func NewOrderFeed() *Feed {
 	}
 }
 
+// SetSynchronous makes Publish call the consumers directly, in the caller goroutine,
+// instead of dispatching orders through buffered channels
+func (f *Feed) SetSynchronous(enabled bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.synchronous = enabled
+}
+
 // Subscribe registers a consumer to receive order updates for a specific pair
 func (f *Feed) Subscribe(pair string, consumer FeedConsumer, onlyNewOrder bool) {
 	f.mu.Lock()
@@ -60,6 +69,15 @@ func (f *Feed) Subscribe(pair string, consumer FeedConsumer, onlyNewOrder bool)
 // Note: The isNew parameter is currently unused but kept for API compatibility
 func (f *Feed) Publish(order core.Order, isNew bool) {
 	f.mu.RLock()
+	if f.synchronous {
+		subscriptions := f.SubscriptionsBySymbol[order.Pair]
+		f.mu.RUnlock()
+
+		for _, subscription := range subscriptions {
+			subscription.consumer(order)
+		}
+		return
+	}
 	defer f.mu.RUnlock()
 
 	if feed, ok := f.OrderFeeds[order.Pair]; ok {
@@ -78,6 +96,11 @@ func (f *Feed) Start() {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
 
+	// Synchronous feeds deliver orders on Publish
+	if f.synchronous {
+		return
+	}
+
 	for pair, feed := range f.OrderFeeds {
 		go f.processOrdersForPair(pair, feed)
 	}
@@ -24,3 +24,19 @@ func TestFeed_Subscribe(t *testing.T) {
 	feed.Publish(core.Order{Pair: pair}, false)
 	require.True(t, <-called)
 }
+
+func TestFeed_Synchronous(t *testing.T) {
+	feed, pair := NewOrderFeed(), "blaus"
+	feed.SetSynchronous(true)
+
+	var received []int64
+	feed.Subscribe(pair, func(order core.Order) {
+		received = append(received, order.ID)
+	}, false)
+
+	feed.Start()
+	feed.Publish(core.Order{ID: 1, Pair: pair}, true)
+	feed.Publish(core.Order{ID: 2, Pair: pair}, false)
+	feed.Publish(core.Order{ID: 3, Pair: "other"}, false)
+	require.Equal(t, []int64{1, 2}, received)
+}
@@ -5,66 +5,113 @@ import (
 	"time"
 
 	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/exchange"
 )
 
 // TradeResult contains the outcome of a completed trade
 type TradeResult struct {
 	Pair          string
+	Strategy      string
 	ProfitPercent float64
 	ProfitValue   float64
 	Side          core.SideType
+	EntryPrice    float64
+	ExitPrice     float64
+	Quantity      float64
+	EntryOrderID  int64
+	ExitOrderID   int64
+	Fee           float64 // Entry and exit fees of the closed quantity, in quote currency
 	Duration      time.Duration
 	CreatedAt     time.Time
 }
 
+// NetProfit returns the profit value after fees
+func (r TradeResult) NetProfit() float64 {
+	return r.ProfitValue - r.Fee
+}
+
 // Position represents a current trading position
 type Position struct {
-	Side      core.SideType
-	CreatedAt time.Time
-	AvgPrice  float64
-	Quantity  float64
+	Pair         string
+	Strategy     string
+	Side         core.SideType
+	CreatedAt    time.Time
+	AvgPrice     float64
+	Quantity     float64
+	Fee          float64 // Entry fees of the open quantity, in quote currency
+	EntryOrderID int64
+}
+
+// newPosition opens a position from a filled order
+func newPosition(order *core.Order) *Position {
+	return &Position{
+		Pair:         order.Pair,
+		Strategy:     order.Strategy,
+		AvgPrice:     order.Price,
+		Quantity:     filledQuantity(order),
+		Fee:          feeValue(order, order.Price),
+		CreatedAt:    order.CreatedAt,
+		Side:         order.Side,
+		EntryOrderID: order.ID,
+	}
 }
 
 // Update modifies the position based on a new order
 // Returns a trade result if the order closes or partially closes the position
 func (p *Position) Update(order *core.Order) (result *TradeResult, finished bool) {
-	// Get the effective price considering stop orders
+	// Stop orders are booked at their executed price, or at the stop price when the exchange doesn't report one
 	price := order.Price
-	if order.Type == core.OrderTypeStopLoss || order.Type == core.OrderTypeStopLossLimit {
+	if price == 0 && order.Stop != nil && (order.Type == core.OrderTypeStopLoss || order.Type == core.OrderTypeStopLossLimit) {
 		price = *order.Stop
 	}
 
+	quantity := filledQuantity(order)
+	orderFee := feeValue(order, price)
+
 	// If the order is on the same side as the position, increase position size
 	if p.Side == order.Side {
 		// Calculate new average price
-		p.AvgPrice = calculateWeightedAverage(p.AvgPrice, p.Quantity, price, order.Quantity)
-		p.Quantity += order.Quantity
+		p.AvgPrice = calculateWeightedAverage(p.AvgPrice, p.Quantity, price, quantity)
+		p.Quantity += quantity
+		p.Fee += orderFee
 		return nil, false
 	}
 
 	// Order is closing or reducing the position
+	entryOrderID := p.EntryOrderID
+	side, avgPrice, openedAt := p.Side, p.AvgPrice, p.CreatedAt
 	var tradeResult *TradeResult
 	var isPositionClosed bool
 
-	if p.Quantity == order.Quantity {
+	// Split the entry and order fees by the closed share
+	closingQuantity := math.Min(p.Quantity, quantity)
+	entryFee := p.Fee * closingQuantity / p.Quantity
+	fee := entryFee + orderFee*closingQuantity/quantity
+
+	if p.Quantity == quantity {
 		// Position fully closed
 		isPositionClosed = true
-	} else if p.Quantity > order.Quantity {
+	} else if p.Quantity > quantity {
 		// Position partially closed
-		p.Quantity -= order.Quantity
+		p.Quantity -= quantity
+		p.Fee -= entryFee
 	} else {
 		// Position reversed
-		remainingQuantity := order.Quantity - p.Quantity
+		remainingQuantity := quantity - p.Quantity
 		p.Quantity = remainingQuantity
+		p.Fee = orderFee * remainingQuantity / quantity
 		p.Side = order.Side
 		p.CreatedAt = order.CreatedAt
 		p.AvgPrice = price
+		p.EntryOrderID = order.ID
 	}
 
-	// Calculate profit for the closed portion
-	closedQuantity := math.Min(p.Quantity, order.Quantity)
-	profitPercent := (price - p.AvgPrice) / p.AvgPrice
-	profitValue := (price - p.AvgPrice) * closedQuantity
+	// Calculate profit for the closed portion, shorts profit when the price falls
+	profitPercent := (price - avgPrice) / avgPrice
+	if side == core.SideTypeSell {
+		profitPercent = -profitPercent
+	}
+	profitValue := profitPercent * avgPrice * closingQuantity
 
 	// Update order with profit information
 	order.Profit = profitPercent
@@ -74,15 +121,42 @@ func (p *Position) Update(order *core.Order) (result *TradeResult, finished bool
 	tradeResult = &TradeResult{
 		CreatedAt:     order.CreatedAt,
 		Pair:          order.Pair,
-		Duration:      order.CreatedAt.Sub(p.CreatedAt),
+		Strategy:      order.Strategy,
+		Duration:      order.CreatedAt.Sub(openedAt),
 		ProfitPercent: profitPercent,
 		ProfitValue:   profitValue,
-		Side:          p.Side,
+		Side:          side,
+		EntryPrice:    avgPrice,
+		ExitPrice:     price,
+		Quantity:      closingQuantity,
+		EntryOrderID:  entryOrderID,
+		ExitOrderID:   order.ID,
+		Fee:           fee,
 	}
 
 	return tradeResult, isPositionClosed
 }
 
+// filledQuantity returns the quantity an order adds to or removes from a position,
+// net of fees charged in the base asset
+func filledQuantity(order *core.Order) float64 {
+	asset, _ := exchange.SplitAssetQuote(order.Pair)
+	if order.FeeAsset == asset {
+		return order.Quantity - order.Fee
+	}
+	return order.Quantity
+}
+
+// feeValue returns the fee of an order in quote currency, valuing base asset fees at the fill price.
+// Fees charged in other assets are taken at face value.
+func feeValue(order *core.Order, price float64) float64 {
+	asset, _ := exchange.SplitAssetQuote(order.Pair)
+	if order.FeeAsset == asset {
+		return order.Fee * price
+	}
+	return order.Fee
+}
+
 // calculateWeightedAverage computes the weighted average of two price-quantity pairs
 func calculateWeightedAverage(price1, quantity1, price2, quantity2 float64) float64 {
 	return (price1*quantity1 + price2*quantity2) / (quantity1 + quantity2)
@@ -0,0 +1,202 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+)
+
+// Risk rules violated by an order, wrapped by RiskError
+var (
+	ErrKillSwitch       = errors.New("kill switch enabled")
+	ErrMaxPositionValue = errors.New("max position value exceeded")
+	ErrMaxTotalExposure = errors.New("max total exposure exceeded")
+	ErrMaxOpenOrders    = errors.New("max open orders exceeded")
+	ErrMaxDailyLoss     = errors.New("max daily loss reached")
+	ErrMaxOrdersMinute  = errors.New("max orders per minute exceeded")
+)
+
+// RiskError is returned when an order is rejected by the risk manager
+type RiskError struct {
+	Err   error
+	Pair  string
+	Side  core.SideType
+	Value float64
+	Limit float64
+}
+
+// Error implements the error interface
+func (e *RiskError) Error() string {
+	return fmt.Sprintf("risk: %s %s rejected: %v (%.4f > %.4f)", e.Side, e.Pair, e.Err, e.Value, e.Limit)
+}
+
+// Unwrap returns the violated rule, to be used with errors.Is
+func (e *RiskError) Unwrap() error {
+	return e.Err
+}
+
+// RiskLimits configures the pre-trade checks, a zero value disables the limit
+type RiskLimits struct {
+	// MaxPositionValue is the max value in quote currency of the position in a single pair
+	MaxPositionValue float64
+	// MaxTotalExposure is the max value in quote currency of all open positions
+	MaxTotalExposure float64
+	// MaxOpenOrders is the max number of orders waiting to be filled
+	MaxOpenOrders int
+	// MaxDailyLoss is the max realized loss in quote currency in the current day (UTC),
+	// once reached only orders reducing a position are accepted
+	MaxDailyLoss float64
+	// MaxOrdersPerMinute is the max number of orders created in a minute
+	MaxOrdersPerMinute int
+}
+
+// RiskManager validates orders against risk limits before they reach the exchange.
+// Limits and the kill switch can be changed at runtime.
+type RiskManager struct {
+	mu         sync.Mutex
+	limits     RiskLimits
+	killed     bool
+	orderTimes []time.Time
+	day        time.Time
+	dailyPnL   float64
+}
+
+// riskCheck describes an order being validated
+type riskCheck struct {
+	pair       string
+	side       core.SideType
+	value      float64 // order value in quote currency
+	exposure   float64 // signed position value of the pair before the order
+	total      float64 // value of all open positions before the order
+	openOrders int     // orders waiting to be filled
+	newOrders  int     // orders added to the book by the request
+	now        time.Time
+}
+
+// NewRiskManager creates a risk manager with the given limits
+func NewRiskManager(limits RiskLimits) *RiskManager {
+	return &RiskManager{limits: limits}
+}
+
+// Limits returns the current limits
+func (r *RiskManager) Limits() RiskLimits {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.limits
+}
+
+// SetLimits replaces the current limits
+func (r *RiskManager) SetLimits(limits RiskLimits) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.limits = limits
+}
+
+// Kill enables the kill switch, rejecting every new order
+func (r *RiskManager) Kill() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.killed = true
+}
+
+// Resume disables the kill switch
+func (r *RiskManager) Resume() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.killed = false
+}
+
+// Killed reports whether the kill switch is enabled
+func (r *RiskManager) Killed() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.killed
+}
+
+// DailyPnL returns the realized profit or loss of the current day
+func (r *RiskManager) DailyPnL() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.dailyPnL
+}
+
+// check validates an order and registers it for the order rate limit when accepted
+func (r *RiskManager) check(c riskCheck) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	reject := func(err error, value, limit float64) error {
+		return &RiskError{Err: err, Pair: c.pair, Side: c.side, Value: value, Limit: limit}
+	}
+
+	if r.killed {
+		return reject(ErrKillSwitch, c.value, 0)
+	}
+
+	// Orders per minute
+	window := c.now.Add(-time.Minute)
+	recent := r.orderTimes[:0]
+	for _, t := range r.orderTimes {
+		if t.After(window) {
+			recent = append(recent, t)
+		}
+	}
+	r.orderTimes = recent
+	if r.limits.MaxOrdersPerMinute > 0 && len(r.orderTimes)+1 > r.limits.MaxOrdersPerMinute {
+		return reject(ErrMaxOrdersMinute, float64(len(r.orderTimes)+1), float64(r.limits.MaxOrdersPerMinute))
+	}
+
+	// Open orders
+	if r.limits.MaxOpenOrders > 0 && c.openOrders+c.newOrders > r.limits.MaxOpenOrders {
+		return reject(ErrMaxOpenOrders, float64(c.openOrders+c.newOrders), float64(r.limits.MaxOpenOrders))
+	}
+
+	// Orders reducing a position are not limited by exposure or losses
+	delta := c.value
+	if c.side == core.SideTypeSell {
+		delta = -c.value
+	}
+	exposure := c.exposure + delta
+	if math.Abs(exposure) > math.Abs(c.exposure) {
+		r.resetDay(c.now)
+		if r.limits.MaxDailyLoss > 0 && -r.dailyPnL >= r.limits.MaxDailyLoss {
+			return reject(ErrMaxDailyLoss, -r.dailyPnL, r.limits.MaxDailyLoss)
+		}
+
+		if r.limits.MaxPositionValue > 0 && math.Abs(exposure) > r.limits.MaxPositionValue {
+			return reject(ErrMaxPositionValue, math.Abs(exposure), r.limits.MaxPositionValue)
+		}
+
+		total := c.total + math.Abs(exposure) - math.Abs(c.exposure)
+		if r.limits.MaxTotalExposure > 0 && total > r.limits.MaxTotalExposure {
+			return reject(ErrMaxTotalExposure, total, r.limits.MaxTotalExposure)
+		}
+	}
+
+	r.orderTimes = append(r.orderTimes, c.now)
+	return nil
+}
+
+// onTradeResult registers the realized profit or loss of a trade
+func (r *RiskManager) onTradeResult(result *TradeResult) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.resetDay(result.CreatedAt)
+	if !result.CreatedAt.Before(r.day) {
+		r.dailyPnL += result.ProfitValue
+	}
+}
+
+// resetDay starts a new day of realized results when the given time is past the current day
+func (r *RiskManager) resetDay(now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	if day.After(r.day) {
+		r.day = day
+		r.dailyPnL = 0
+	}
+}
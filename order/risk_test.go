@@ -0,0 +1,195 @@
+package order
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/exchange"
+	"github.com/raykavin/backnrun/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type errorNotifier struct {
+	errors []error
+}
+
+func (n *errorNotifier) Notify(string)      {}
+func (n *errorNotifier) OnOrder(core.Order) {}
+func (n *errorNotifier) OnError(err error)  { n.errors = append(n.errors, err) }
+
+// newRiskController creates a backtest controller, whose risk checks use the candle time
+func newRiskController(t *testing.T, limits RiskLimits) (*Controller, *exchange.PaperWallet, *errorNotifier) {
+	t.Helper()
+
+	storage, err := storage.FromMemory()
+	require.NoError(t, err)
+	ctx := context.Background()
+	wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(), exchange.WithPaperAsset("USDT", 100000))
+	feed := NewOrderFeed()
+	feed.SetSynchronous(true)
+	controller := NewController(ctx, wallet, storage, getLog(), feed)
+	controller.SetSynchronous(true)
+	notifier := &errorNotifier{}
+	controller.SetNotifier(notifier)
+	controller.SetRiskManager(NewRiskManager(limits))
+
+	candle := core.Candle{Time: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), Pair: "BTCUSDT", Close: 1000, High: 1000}
+	wallet.OnCandle(candle)
+	controller.OnCandle(candle)
+
+	return controller, wallet, notifier
+}
+
+func TestRiskManager(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("max position value", func(t *testing.T) {
+		controller, _, notifier := newRiskController(t, RiskLimits{MaxPositionValue: 2500})
+
+		_, err := controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 2)
+		require.NoError(t, err)
+
+		_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+		require.ErrorIs(t, err, ErrMaxPositionValue)
+
+		var riskErr *RiskError
+		require.True(t, errors.As(err, &riskErr))
+		assert.Equal(t, "BTCUSDT", riskErr.Pair)
+		assert.Equal(t, 3000.0, riskErr.Value)
+		require.Len(t, notifier.errors, 1)
+
+		// reducing the position is always allowed
+		_, err = controller.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("max total exposure", func(t *testing.T) {
+		controller, wallet, _ := newRiskController(t, RiskLimits{MaxTotalExposure: 3000})
+		wallet.OnCandle(core.Candle{Time: time.Now(), Pair: "ETHUSDT", Close: 100, High: 100})
+
+		_, err := controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 2)
+		require.NoError(t, err)
+
+		_, err = controller.CreateOrderLimit(ctx, core.SideTypeBuy, "ETHUSDT", 15, 100)
+		require.ErrorIs(t, err, ErrMaxTotalExposure)
+
+		_, err = controller.CreateOrderLimit(ctx, core.SideTypeBuy, "ETHUSDT", 5, 100)
+		require.NoError(t, err)
+	})
+
+	t.Run("max open orders", func(t *testing.T) {
+		controller, _, _ := newRiskController(t, RiskLimits{MaxOpenOrders: 2})
+
+		_, err := controller.CreateOrderLimit(ctx, core.SideTypeBuy, "BTCUSDT", 1, 900)
+		require.NoError(t, err)
+
+		_, err = controller.CreateOrderLimit(ctx, core.SideTypeBuy, "BTCUSDT", 1, 800)
+		require.NoError(t, err)
+
+		_, err = controller.CreateOrderLimit(ctx, core.SideTypeBuy, "BTCUSDT", 1, 700)
+		require.ErrorIs(t, err, ErrMaxOpenOrders)
+	})
+
+	t.Run("max daily loss", func(t *testing.T) {
+		controller, wallet, _ := newRiskController(t, RiskLimits{MaxDailyLoss: 100})
+
+		_, err := controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+
+		candle := core.Candle{Time: time.Date(2024, 1, 1, 11, 0, 0, 0, time.UTC), Pair: "BTCUSDT", Close: 800, High: 800}
+		wallet.OnCandle(candle)
+		controller.OnCandle(candle)
+
+		_, err = controller.CreateOrderMarket(ctx, core.SideTypeSell, "BTCUSDT", 1)
+		require.NoError(t, err)
+		require.Equal(t, -200.0, controller.RiskManager().DailyPnL())
+
+		_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+		require.ErrorIs(t, err, ErrMaxDailyLoss)
+
+		// a new day resets the realized loss
+		candle = core.Candle{Time: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Pair: "BTCUSDT", Close: 800, High: 800}
+		wallet.OnCandle(candle)
+		controller.OnCandle(candle)
+
+		_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("max orders per minute", func(t *testing.T) {
+		controller, wallet, _ := newRiskController(t, RiskLimits{MaxOrdersPerMinute: 2})
+
+		for i := 0; i < 2; i++ {
+			_, err := controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 0.1)
+			require.NoError(t, err)
+		}
+
+		_, err := controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 0.1)
+		require.ErrorIs(t, err, ErrMaxOrdersMinute)
+
+		candle := core.Candle{Time: time.Date(2024, 1, 1, 10, 1, 0, 0, time.UTC), Pair: "BTCUSDT", Close: 1000, High: 1000}
+		wallet.OnCandle(candle)
+		controller.OnCandle(candle)
+
+		_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 0.1)
+		require.NoError(t, err)
+	})
+
+	t.Run("max orders per minute in live mode", func(t *testing.T) {
+		storage, err := storage.FromMemory()
+		require.NoError(t, err)
+		wallet := exchange.NewPaperWallet(ctx, "USDT", getLog(), exchange.WithPaperAsset("USDT", 100000))
+		controller := NewController(ctx, wallet, storage, getLog(), NewOrderFeed())
+		controller.SetRiskManager(NewRiskManager(RiskLimits{MaxOrdersPerMinute: 2}))
+
+		now := time.Date(2024, 1, 1, 10, 0, 30, 0, time.UTC)
+		controller.wallClock = func() time.Time { return now }
+
+		// the open candle of a 1h bot, never closed during the test
+		candle := core.Candle{Time: time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), Pair: "BTCUSDT", Close: 1000, High: 1000}
+		wallet.OnCandle(candle)
+		controller.OnCandle(candle)
+
+		for i := 0; i < 2; i++ {
+			_, err := controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 0.1)
+			require.NoError(t, err)
+		}
+
+		_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 0.1)
+		require.ErrorIs(t, err, ErrMaxOrdersMinute)
+
+		now = now.Add(61 * time.Second)
+		_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 0.1)
+		require.NoError(t, err)
+	})
+
+	t.Run("kill switch", func(t *testing.T) {
+		controller, _, _ := newRiskController(t, RiskLimits{})
+		controller.RiskManager().Kill()
+
+		_, err := controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+		require.ErrorIs(t, err, ErrKillSwitch)
+
+		_, err = controller.CreateOrderOCO(ctx, core.SideTypeSell, "BTCUSDT", 1, 1200, 900, 900)
+		require.ErrorIs(t, err, ErrKillSwitch)
+
+		controller.RiskManager().Resume()
+		_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+	})
+
+	t.Run("limits adjusted at runtime", func(t *testing.T) {
+		controller, _, _ := newRiskController(t, RiskLimits{MaxPositionValue: 500})
+
+		_, err := controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+		require.ErrorIs(t, err, ErrMaxPositionValue)
+
+		controller.RiskManager().SetLimits(RiskLimits{MaxPositionValue: 1000})
+		_, err = controller.CreateOrderMarket(ctx, core.SideTypeBuy, "BTCUSDT", 1)
+		require.NoError(t, err)
+	})
+}
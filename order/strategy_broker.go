@@ -0,0 +1,93 @@
+package order
+
+import (
+	"context"
+
+	"github.com/raykavin/backnrun/core"
+)
+
+// StrategyBroker is a core.Broker bound to a named strategy.
+// Every order created through it is tagged with the strategy name, so positions and
+// trade results are attributed to that strategy while sharing the same controller.
+type StrategyBroker struct {
+	controller *Controller
+	strategy   string
+}
+
+// StrategyBroker returns a broker that places orders on behalf of the given strategy
+func (c *Controller) StrategyBroker(strategy string) *StrategyBroker {
+	return &StrategyBroker{
+		controller: c,
+		strategy:   strategy,
+	}
+}
+
+// Strategy returns the name of the strategy bound to the broker
+func (b *StrategyBroker) Strategy() string {
+	return b.strategy
+}
+
+// Account retrieves the current trading account information
+func (b *StrategyBroker) Account(ctx context.Context) (core.Account, error) {
+	return b.controller.Account(ctx)
+}
+
+// Position retrieves the position of the strategy in a trading pair, negative for shorts,
+// and the quote balance of the account, shared by all strategies
+func (b *StrategyBroker) Position(ctx context.Context, pair string) (asset, quote float64, err error) {
+	_, quote, err = b.controller.Position(ctx, pair)
+	if err != nil {
+		return 0, 0, err
+	}
+	return b.controller.strategyPosition(b.strategy, pair), quote, nil
+}
+
+// AssetsInfo retrieves the trading rules of a pair, such as step size and minimum quantity
+func (b *StrategyBroker) AssetsInfo(pair string) (core.AssetInfo, error) {
+	return b.controller.AssetsInfo(pair)
+}
+
+// TradeSummary returns a copy of the trade summary of the strategy on a pair
+func (b *StrategyBroker) TradeSummary(pair string) (*TradeSummary, bool) {
+	return b.controller.strategySummary(b.strategy, pair)
+}
+
+// Order retrieves information about a specific order
+func (b *StrategyBroker) Order(ctx context.Context, pair string, id int64) (core.Order, error) {
+	return b.controller.Order(ctx, pair, id)
+}
+
+// CreateOrderOCO creates a One-Cancels-the-Other order pair
+func (b *StrategyBroker) CreateOrderOCO(ctx context.Context, side core.SideType, pair string, size, price, stop,
+	stopLimit float64) ([]core.Order, error) {
+	return b.controller.createOrderOCO(ctx, b.strategy, side, pair, size, price, stop, stopLimit)
+}
+
+// CreateOrderLimit creates a limit order
+func (b *StrategyBroker) CreateOrderLimit(ctx context.Context, side core.SideType, pair string, size,
+	limit float64) (core.Order, error) {
+	return b.controller.createOrderLimit(ctx, b.strategy, side, pair, size, limit)
+}
+
+// CreateOrderMarket creates a market order with a specified size
+func (b *StrategyBroker) CreateOrderMarket(ctx context.Context, side core.SideType, pair string,
+	size float64) (core.Order, error) {
+	return b.controller.createOrderMarket(ctx, b.strategy, side, pair, size)
+}
+
+// CreateOrderMarketQuote creates a market order with a specified quote amount
+func (b *StrategyBroker) CreateOrderMarketQuote(ctx context.Context, side core.SideType, pair string,
+	quote float64) (core.Order, error) {
+	return b.controller.createOrderMarketQuote(ctx, b.strategy, side, pair, quote)
+}
+
+// CreateOrderStop creates a stop loss order
+func (b *StrategyBroker) CreateOrderStop(ctx context.Context, pair string, quantity float64,
+	limit float64) (core.Order, error) {
+	return b.controller.createOrderStop(ctx, b.strategy, pair, quantity, limit)
+}
+
+// Cancel cancels an existing order
+func (b *StrategyBroker) Cancel(ctx context.Context, order core.Order) error {
+	return b.controller.Cancel(ctx, order)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"math"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 
@@ -11,9 +12,11 @@ import (
 	"github.com/raykavin/backnrun/exchange"
 )
 
-// TradeSummary collects statistics about trading performance
+// TradeSummary collects statistics about trading performance.
+// Trades are classified and measured by their profit before fees, NetProfit deducts the fees of the closed trades.
 type TradeSummary struct {
 	Pair             string
+	Strategy         string
 	WinLong          []float64
 	WinLongPercent   []float64
 	WinShort         []float64
@@ -23,6 +26,24 @@ type TradeSummary struct {
 	LoseShort        []float64
 	LoseShortPercent []float64
 	Volume           float64
+	Slippage         float64 // Slippage cost of the fills in quote currency
+	Fees             float64 // Fees of all the fills in quote currency, including open positions
+	Trades           []TradeResult
+}
+
+// clone returns a copy of the summary sharing no slices with it
+func (s *TradeSummary) clone() *TradeSummary {
+	summary := *s
+	summary.WinLong = slices.Clone(s.WinLong)
+	summary.WinLongPercent = slices.Clone(s.WinLongPercent)
+	summary.WinShort = slices.Clone(s.WinShort)
+	summary.WinShortPercent = slices.Clone(s.WinShortPercent)
+	summary.LoseLong = slices.Clone(s.LoseLong)
+	summary.LoseLongPercent = slices.Clone(s.LoseLongPercent)
+	summary.LoseShort = slices.Clone(s.LoseShort)
+	summary.LoseShortPercent = slices.Clone(s.LoseShortPercent)
+	summary.Trades = slices.Clone(s.Trades)
+	return &summary
 }
 
 // Win returns all winning trades (both long and short)
@@ -45,12 +66,26 @@ func (s TradeSummary) LosePercent() []float64 {
 	return append(s.LoseLongPercent, s.LoseShortPercent...)
 }
 
-// Profit calculates the total profit across all trades
+// Profit calculates the total profit across all trades, before fees
 func (s TradeSummary) Profit() float64 {
 	allTrades := append(s.Win(), s.Lose()...)
 	return sumSlice(allTrades)
 }
 
+// TradeFees returns the fees of the closed trades
+func (s TradeSummary) TradeFees() float64 {
+	fees := 0.0
+	for _, trade := range s.Trades {
+		fees += trade.Fee
+	}
+	return fees
+}
+
+// NetProfit calculates the total profit across all trades after their fees
+func (s TradeSummary) NetProfit() float64 {
+	return s.Profit() - s.TradeFees()
+}
+
 // SQN (System Quality Number) calculates the quality of the trading system
 // SQN = sqrt(n) * (average profit / standard deviation)
 func (s TradeSummary) SQN() float64 {
@@ -136,6 +171,11 @@ func (s TradeSummary) String() string {
 
 	data := [][]string{
 		{"Coin", s.Pair},
+	}
+	if s.Strategy != "" {
+		data = append(data, []string{"Strategy", s.Strategy})
+	}
+	data = append(data, [][]string{
 		{"Trades", strconv.Itoa(len(s.Lose()) + len(s.Win()))},
 		{"Win", strconv.Itoa(len(s.Win()))},
 		{"Loss", strconv.Itoa(len(s.Lose()))},
@@ -143,8 +183,11 @@ func (s TradeSummary) String() string {
 		{"Payoff", fmt.Sprintf("%.1f", s.Payoff()*100)},
 		{"Pr.Fact", fmt.Sprintf("%.1f", s.ProfitFactor()*100)},
 		{"Profit", fmt.Sprintf("%.4f %s", s.Profit(), quote)},
+		{"Fees", fmt.Sprintf("%.4f %s", s.TradeFees(), quote)},
+		{"Net Profit", fmt.Sprintf("%.4f %s", s.NetProfit(), quote)},
 		{"Volume", fmt.Sprintf("%.4f %s", s.Volume, quote)},
-	}
+		{"Slippage", fmt.Sprintf("%.4f %s", s.Slippage, quote)},
+	}...)
 
 	table.AppendBulk(data)
 	table.SetColumnAlignment([]int{tablewriter.ALIGN_LEFT, tablewriter.ALIGN_RIGHT})
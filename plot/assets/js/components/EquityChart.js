@@ -63,6 +63,9 @@ export function createEquityChart(data, graphContainer, mainChart, colors, creat
   // Add equity to legend
   addLegendItem(equityLegend, `Equity (${data.quote})`, colors.EQUITY);
 
+  // Overlay the buy-and-hold benchmark if available
+  addBenchmarkSeries(data, equityChart, equityLegend, colors);
+
   // Handle drawdown if available
   addDrawdownVisualization(data, equityChart, equitySeries, equityLegend, colors);
 
@@ -119,6 +122,39 @@ function addDrawdownVisualization(data, chart, series, legend, colors) {
   addLegendItem(legend, `Max Drawdown: ${data.max_drawdown.value}%`, colors.DOWN);
 }
 
+/**
+ * Add buy-and-hold benchmark series
+ * @param {Object} data - Chart data
+ * @param {Object} chart - Chart instance
+ * @param {HTMLElement} legend - Legend element
+ * @param {Object} colors - Theme colors
+ */
+function addBenchmarkSeries(data, chart, legend, colors) {
+  if (!data.benchmark_values || data.benchmark_values.length === 0) return;
+
+  // Format benchmark data
+  const benchmarkData = data.benchmark_values.map(item => ({
+    time: new Date(item.time).getTime() / 1000,
+    value: item.value
+  }));
+
+  // Add benchmark series
+  const benchmarkSeries = chart.addLineSeries({
+    color: colors.BENCHMARK,
+    lineWidth: 2,
+    lineStyle: 2, // Dashed
+    priceFormat: {
+      type: 'price',
+      precision: 2,
+      minMove: 0.01,
+    },
+  });
+  benchmarkSeries.setData(benchmarkData);
+
+  // Add to legend
+  addLegendItem(legend, `Buy & Hold (${data.quote})`, colors.BENCHMARK);
+}
+
 /**
  * Add asset value series
  * @param {Object} data - Chart data
@@ -10,6 +10,7 @@ export const THEME_COLORS = {
     DOWN: '#ef5350',
     EQUITY: 'rgba(38, 166, 154, 1)',
     ASSET: 'rgba(239, 83, 80, 1)',
+    BENCHMARK: 'rgba(120, 123, 134, 1)',
     DEFAULT_INDICATOR: '#2196F3',
     GRID: 'rgba(197, 203, 206, 0.5)',
     BORDER: 'rgba(197, 203, 206, 1)',
@@ -21,6 +22,7 @@ export const THEME_COLORS = {
     DOWN: '#ff6b6b',
     EQUITY: 'rgba(78, 204, 163, 1)',
     ASSET: 'rgba(255, 107, 107, 1)',
+    BENCHMARK: 'rgba(178, 181, 190, 1)',
     DEFAULT_INDICATOR: '#64b5f6',
     GRID: 'rgba(120, 123, 134, 0.3)',
     BORDER: 'rgba(120, 123, 134, 1)',
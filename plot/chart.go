@@ -38,6 +38,7 @@ type Chart struct {
 	log                core.Logger
 	wsManager          *WebSocketManager
 	simulationInterval time.Duration
+	benchmark          bool
 }
 
 // Option defines a function type for configuring a Chart instance
@@ -64,6 +65,13 @@ func WithPaperWallet(paperWallet *exchange.PaperWallet) Option {
 	}
 }
 
+// WithBenchmark overlays the paper wallet buy-and-hold benchmark on the equity chart
+func WithBenchmark() Option {
+	return func(chart *Chart) {
+		chart.benchmark = true
+	}
+}
+
 // WithDebug enables debug mode (disables minification)
 func WithDebug() Option {
 	return func(chart *Chart) {
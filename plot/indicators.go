@@ -94,3 +94,19 @@ func (c *Chart) equityValuesByPair(pair string) ([]AssetValue, []AssetValue) {
 
 	return assetValues, equityValues
 }
+
+// benchmarkValues returns the buy-and-hold benchmark values of the paper wallet
+func (c *Chart) benchmarkValues() []AssetValue {
+	benchmarkValues := make([]AssetValue, 0)
+
+	if c.paperWallet != nil && c.benchmark {
+		for _, point := range c.paperWallet.BenchmarkCurve() {
+			benchmarkValues = append(benchmarkValues, AssetValue{
+				Time:  point.Time,
+				Value: point.Value,
+			})
+		}
+	}
+
+	return benchmarkValues
+}
@@ -146,14 +146,15 @@ func (m *WebSocketManager) sendInitialData(conn *websocket.Conn, pair string) {
 	m.Lock()
 	// Prepare response
 	response := map[string]any{
-		"candles":       m.chart.candlesByPair(pair),
-		"indicators":    m.chart.indicatorsByPair(pair),
-		"shapes":        m.chart.shapesByPair(pair),
-		"asset_values":  assetValues,
-		"equity_values": equityValues,
-		"quote":         quote,
-		"asset":         asset,
-		"pair":          pair,
+		"candles":          m.chart.candlesByPair(pair),
+		"indicators":       m.chart.indicatorsByPair(pair),
+		"shapes":           m.chart.shapesByPair(pair),
+		"asset_values":     assetValues,
+		"equity_values":    equityValues,
+		"benchmark_values": m.chart.benchmarkValues(),
+		"quote":            quote,
+		"asset":            asset,
+		"pair":             pair,
 	}
 	m.Unlock()
 
@@ -0,0 +1,95 @@
+package report
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// Report page template embedded in the binary
+var (
+	//go:embed assets
+	staticFiles embed.FS
+
+	htmlTemplate = template.Must(template.New("report.html").Funcs(template.FuncMap{
+		"number":   formatNumber,
+		"percent":  formatPercent,
+		"time":     formatTime,
+		"duration": formatDuration,
+		"path":     seriesPath,
+	}).ParseFS(staticFiles, "assets/report.html"))
+)
+
+// Size of the series charts, in SVG units
+const (
+	chartWidth  = 1000
+	chartHeight = 240
+)
+
+// WriteHTML writes the report as a standalone HTML page, with charts drawn as inline SVG
+func (r *Report) WriteHTML(w io.Writer) error {
+	if err := htmlTemplate.Execute(w, r); err != nil {
+		return fmt.Errorf("failed to render report: %w", err)
+	}
+	return nil
+}
+
+// seriesPath returns the SVG path drawing the series scaled to the chart size
+func seriesPath(points []Point) string {
+	if len(points) == 0 {
+		return ""
+	}
+
+	start, end := points[0].Time, points[len(points)-1].Time
+	low, high := math.Inf(1), math.Inf(-1)
+	for _, point := range points {
+		low = math.Min(low, point.Value)
+		high = math.Max(high, point.Value)
+	}
+
+	span := float64(end.Sub(start))
+	valueSpan := high - low
+
+	var path strings.Builder
+	for i, point := range points {
+		x, y := 0.0, chartHeight/2.0
+		if span > 0 {
+			x = float64(point.Time.Sub(start)) / span * chartWidth
+		}
+		if valueSpan > 0 {
+			y = (high - point.Value) / valueSpan * chartHeight
+		}
+
+		command := "L"
+		if i == 0 {
+			command = "M"
+		}
+		fmt.Fprintf(&path, "%s%.1f %.1f ", command, x, y)
+	}
+
+	return strings.TrimSpace(path.String())
+}
+
+// formatNumber formats a value with two decimals
+func formatNumber(value float64) string {
+	return fmt.Sprintf("%.2f", value)
+}
+
+// formatPercent formats a fraction as a percentage
+func formatPercent(value float64) string {
+	return fmt.Sprintf("%.2f%%", value*100)
+}
+
+// formatTime formats a time for display
+func formatTime(value time.Time) string {
+	return value.Format("2006-01-02 15:04")
+}
+
+// formatDuration formats a duration rounded to minutes
+func formatDuration(value time.Duration) string {
+	return value.Round(time.Minute).String()
+}
@@ -0,0 +1,167 @@
+// Package report provides machine-readable and standalone HTML reports of finished backtests.
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/metric"
+)
+
+// Report holds the results of a finished backtest
+type Report struct {
+	GeneratedAt time.Time                `json:"generated_at"`
+	Settings    Settings                 `json:"settings"`
+	Strategies  []Strategy               `json:"strategies"`
+	Pairs       []PairSummary            `json:"pairs"`
+	Trades      []Trade                  `json:"trades"`
+	Wallet      *Wallet                  `json:"wallet,omitempty"`
+	Performance *metric.EquityMetrics    `json:"performance,omitempty"`
+	Benchmark   *metric.BenchmarkMetrics `json:"benchmark,omitempty"`
+	Confidence  []ConfidenceIntervals    `json:"confidence_intervals"`
+	Equity      []Point                  `json:"equity"`
+	Drawdown    []Point                  `json:"drawdown"`
+}
+
+// Settings holds the bot settings relevant to the results
+type Settings struct {
+	Pairs    []string `json:"pairs"`
+	Backtest bool     `json:"backtest"`
+}
+
+// Strategy describes a strategy run by the bot
+type Strategy struct {
+	Name       string            `json:"name"`
+	Timeframe  string            `json:"timeframe"`
+	Pairs      []string          `json:"pairs"`
+	Parameters core.ParameterSet `json:"parameters,omitempty"`
+}
+
+// PairSummary holds the trade statistics of a pair, per strategy
+type PairSummary struct {
+	Strategy     string  `json:"strategy,omitempty"`
+	Pair         string  `json:"pair"`
+	Trades       int     `json:"trades"`
+	Wins         int     `json:"wins"`
+	Losses       int     `json:"losses"`
+	WinRate      float64 `json:"win_rate"`
+	Payoff       float64 `json:"payoff"`
+	ProfitFactor float64 `json:"profit_factor"`
+	SQN          float64 `json:"sqn"`
+	Profit       float64 `json:"profit"`
+	Fees         float64 `json:"fees"`
+	NetProfit    float64 `json:"net_profit"`
+	Volume       float64 `json:"volume"`
+	Slippage     float64 `json:"slippage"`
+}
+
+// Trade is a closed or reduced position
+type Trade struct {
+	Strategy      string        `json:"strategy,omitempty"`
+	Pair          string        `json:"pair"`
+	Side          core.SideType `json:"side"`
+	EntryPrice    float64       `json:"entry_price"`
+	ExitPrice     float64       `json:"exit_price"`
+	Quantity      float64       `json:"quantity"`
+	Profit        float64       `json:"profit"`
+	ProfitPercent float64       `json:"profit_percent"`
+	Fee           float64       `json:"fee"`
+	NetProfit     float64       `json:"net_profit"`
+	OpenedAt      time.Time     `json:"opened_at"`
+	ClosedAt      time.Time     `json:"closed_at"`
+	Duration      time.Duration `json:"duration"`
+}
+
+// ConfidenceIntervals holds the bootstrap intervals of the trade returns of a pair
+type ConfidenceIntervals struct {
+	Strategy     string                   `json:"strategy,omitempty"`
+	Pair         string                   `json:"pair"`
+	Confidence   float64                  `json:"confidence"`
+	Return       metric.BootstrapInterval `json:"return"`
+	Payoff       metric.BootstrapInterval `json:"payoff"`
+	ProfitFactor metric.BootstrapInterval `json:"profit_factor"`
+}
+
+// Wallet holds the paper wallet results
+type Wallet struct {
+	InitialValue float64 `json:"initial_value"`
+	FinalValue   float64 `json:"final_value"`
+	Profit       float64 `json:"profit"`
+	MaxDrawdown  float64 `json:"max_drawdown"`
+}
+
+// Point is a value of a series at a point in time
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+// Drawdown returns the decline of each equity value from its running peak, as a negative fraction
+func Drawdown(equity []Point) []Point {
+	drawdown := make([]Point, len(equity))
+	peak := 0.0
+	for i, point := range equity {
+		if i == 0 || point.Value > peak {
+			peak = point.Value
+		}
+
+		drawdown[i] = Point{Time: point.Time}
+		if peak > 0 {
+			drawdown[i].Value = point.Value/peak - 1
+		}
+	}
+	return drawdown
+}
+
+// WriteJSON writes the report as indented JSON
+func (r *Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return fmt.Errorf("failed to encode report: %w", err)
+	}
+	return nil
+}
+
+// SaveJSON writes the report as JSON to a file
+func (r *Report) SaveJSON(filePath string) error {
+	return saveFile(filePath, r.WriteJSON)
+}
+
+// SaveHTML writes the report as a standalone HTML page to a file
+func (r *Report) SaveHTML(filePath string) error {
+	return saveFile(filePath, r.WriteHTML)
+}
+
+// LoadJSON reads a report saved as JSON
+func LoadJSON(filePath string) (*Report, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open report: %w", err)
+	}
+	defer file.Close()
+
+	var report Report
+	if err := json.NewDecoder(file).Decode(&report); err != nil {
+		return nil, fmt.Errorf("failed to decode report: %w", err)
+	}
+	return &report, nil
+}
+
+// saveFile creates a file and writes its content
+func saveFile(filePath string, write func(io.Writer) error) error {
+	file, err := os.Create(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to create report file: %w", err)
+	}
+
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
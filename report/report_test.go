@@ -0,0 +1,99 @@
+package report
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/metric"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReport() *Report {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	equity := []Point{
+		{Time: start, Value: 1000},
+		{Time: start.Add(time.Hour), Value: 1100},
+		{Time: start.Add(2 * time.Hour), Value: 990},
+		{Time: start.Add(3 * time.Hour), Value: 1210},
+	}
+
+	return &Report{
+		GeneratedAt: start.Add(4 * time.Hour),
+		Settings:    Settings{Pairs: []string{"BTCUSDT"}, Backtest: true},
+		Strategies: []Strategy{{
+			Name:       "CrossEMA",
+			Timeframe:  "1h",
+			Pairs:      []string{"BTCUSDT"},
+			Parameters: core.ParameterSet{"emaLength": 9.0},
+		}},
+		Pairs: []PairSummary{{Pair: "BTCUSDT", Trades: 1, Wins: 1, WinRate: 1, Profit: 210, Volume: 2000}},
+		Trades: []Trade{{
+			Pair:          "BTCUSDT",
+			Side:          core.SideTypeBuy,
+			EntryPrice:    100,
+			ExitPrice:     121,
+			Quantity:      10,
+			Profit:        210,
+			ProfitPercent: 0.21,
+			OpenedAt:      start,
+			ClosedAt:      start.Add(3 * time.Hour),
+			Duration:      3 * time.Hour,
+		}},
+		Wallet:      &Wallet{InitialValue: 1000, FinalValue: 1210, Profit: 210, MaxDrawdown: 0.1},
+		Performance: &metric.EquityMetrics{SharpeRatio: 1.5},
+		Confidence: []ConfidenceIntervals{{
+			Pair:       "BTCUSDT",
+			Confidence: 0.95,
+			Return:     metric.BootstrapInterval{Lower: 0.21, Upper: 0.21, Mean: 0.21},
+		}},
+		Equity:   equity,
+		Drawdown: Drawdown(equity),
+	}
+}
+
+func TestDrawdown(t *testing.T) {
+	drawdown := Drawdown(testReport().Equity)
+
+	require.Len(t, drawdown, 4)
+	assert.Equal(t, 0.0, drawdown[0].Value)
+	assert.Equal(t, 0.0, drawdown[1].Value)
+	assert.InDelta(t, -0.1, drawdown[2].Value, 1e-9)
+	assert.Equal(t, 0.0, drawdown[3].Value)
+}
+
+func TestReport_JSON(t *testing.T) {
+	expected := testReport()
+	filePath := filepath.Join(t.TempDir(), "report.json")
+
+	require.NoError(t, expected.SaveJSON(filePath))
+	loaded, err := LoadJSON(filePath)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected.Settings, loaded.Settings)
+	assert.Equal(t, expected.Strategies, loaded.Strategies)
+	assert.Equal(t, expected.Pairs, loaded.Pairs)
+	assert.Equal(t, expected.Trades, loaded.Trades)
+	assert.Equal(t, expected.Wallet, loaded.Wallet)
+	assert.Equal(t, expected.Confidence, loaded.Confidence)
+	assert.Equal(t, expected.Equity, loaded.Equity)
+	assert.Equal(t, expected.Drawdown, loaded.Drawdown)
+	assert.Nil(t, loaded.Benchmark)
+}
+
+func TestReport_HTML(t *testing.T) {
+	var buffer bytes.Buffer
+	require.NoError(t, testReport().WriteHTML(&buffer))
+
+	html := buffer.String()
+	assert.Contains(t, html, "<title>Backtest Report</title>")
+	assert.Contains(t, html, "CrossEMA")
+	assert.Contains(t, html, "emaLength=9")
+	assert.Contains(t, html, `d="M0.0 229.1 L333.3 120.0 L666.7 240.0 L1000.0 0.0"`)
+	assert.Contains(t, html, "21.00%")
+	assert.NotContains(t, html, "Benchmark (Buy")
+	assert.NotContains(t, html, "<script")
+}
@@ -0,0 +1,127 @@
+package sizing
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/indicator"
+)
+
+// FixedQuote sizes every order with the same amount of quote currency
+type FixedQuote struct {
+	Amount float64
+}
+
+// NewFixedQuote creates a sizer spending a fixed quote amount per order
+func NewFixedQuote(amount float64) *FixedQuote {
+	return &FixedQuote{Amount: amount}
+}
+
+// Size returns the quantity worth the fixed quote amount at the last close price
+func (s *FixedQuote) Size(ctx context.Context, side core.SideType, broker core.Broker, df *core.Dataframe) (float64, error) {
+	snapshot, err := TakeSnapshot(ctx, broker, df)
+	if err != nil {
+		return 0, err
+	}
+
+	return finalize(broker, df.Pair, side, snapshot, s.Amount/snapshot.Price)
+}
+
+// FixedFractional sizes every order as a fraction of the equity
+type FixedFractional struct {
+	Fraction float64
+}
+
+// NewFixedFractional creates a sizer allocating a fraction of the equity per order, eg: 0.1 for 10%
+func NewFixedFractional(fraction float64) *FixedFractional {
+	return &FixedFractional{Fraction: fraction}
+}
+
+// Size returns the quantity worth the configured fraction of the equity
+func (s *FixedFractional) Size(ctx context.Context, side core.SideType, broker core.Broker, df *core.Dataframe) (float64, error) {
+	snapshot, err := TakeSnapshot(ctx, broker, df)
+	if err != nil {
+		return 0, err
+	}
+
+	return finalize(broker, df.Pair, side, snapshot, snapshot.Equity*s.Fraction/snapshot.Price)
+}
+
+// StopFunc returns the distance, in price, between the entry and the stop of the next trade
+type StopFunc func(df *core.Dataframe) (float64, error)
+
+// StopDistance uses a fixed price distance between entry and stop
+func StopDistance(distance float64) StopFunc {
+	return func(_ *core.Dataframe) (float64, error) {
+		return distance, nil
+	}
+}
+
+// StopPercent places the stop at a percentage of the last close price, eg: 0.02 for 2%
+func StopPercent(percent float64) StopFunc {
+	return func(df *core.Dataframe) (float64, error) {
+		if len(df.Close) == 0 {
+			return 0, fmt.Errorf("%w: empty dataframe", ErrInsufficientData)
+		}
+		return df.Close.Last(0) * percent, nil
+	}
+}
+
+// StopATR places the stop at a multiple of the Average True Range
+func StopATR(period int, multiplier float64) StopFunc {
+	return func(df *core.Dataframe) (float64, error) {
+		atr, err := lastATR(df, period)
+		if err != nil {
+			return 0, err
+		}
+		return atr * multiplier, nil
+	}
+}
+
+// FixedRisk sizes orders so that hitting the stop loses a fixed fraction of the equity
+type FixedRisk struct {
+	Risk float64
+	Stop StopFunc
+}
+
+// NewFixedRisk creates a sizer risking a fraction of the equity per trade, eg: 0.01 for 1%
+func NewFixedRisk(risk float64, stop StopFunc) *FixedRisk {
+	return &FixedRisk{Risk: risk, Stop: stop}
+}
+
+// Size returns the quantity losing the configured risk when price moves by the stop distance
+func (s *FixedRisk) Size(ctx context.Context, side core.SideType, broker core.Broker, df *core.Dataframe) (float64, error) {
+	distance, err := s.Stop(df)
+	if err != nil {
+		return 0, err
+	}
+
+	return s.SizeWithStop(ctx, side, broker, df, distance)
+}
+
+// SizeWithStop returns the quantity losing the configured risk for the given stop distance
+func (s *FixedRisk) SizeWithStop(ctx context.Context, side core.SideType, broker core.Broker, df *core.Dataframe,
+	distance float64) (float64, error) {
+	if distance <= 0 || math.IsNaN(distance) {
+		return 0, fmt.Errorf("%w: %f", ErrInvalidStopDistance, distance)
+	}
+
+	snapshot, err := TakeSnapshot(ctx, broker, df)
+	if err != nil {
+		return 0, err
+	}
+
+	return finalize(broker, df.Pair, side, snapshot, snapshot.Equity*s.Risk/distance)
+}
+
+// lastATR returns the last Average True Range value of the dataframe
+func lastATR(df *core.Dataframe, period int) (float64, error) {
+	if len(df.Close) <= period {
+		return 0, fmt.Errorf("%w: %d candles for ATR(%d)", ErrInsufficientData, len(df.Close), period)
+	}
+
+	atr := indicator.ATR(df.High, df.Low, df.Close, period)
+	return atr[len(atr)-1], nil
+}
@@ -0,0 +1,95 @@
+package sizing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/order"
+)
+
+// Kelly sizes orders with a fraction of the Kelly criterion computed from the running trade summary.
+// Until MinTrades trades are closed, the Fallback sizer is used.
+type Kelly struct {
+	// Fraction of the Kelly allocation, eg: 0.5 for half Kelly
+	Fraction float64
+	// MaxFraction caps the allocation as a fraction of the equity, zero means no cap
+	MaxFraction float64
+	// MinTrades is the number of closed trades required before using the Kelly allocation
+	MinTrades int
+	// Fallback sizes orders while there are not enough trades
+	Fallback Sizer
+	// Summary returns the trade summary of a pair, defaults to the broker summary when
+	// the broker implements SummaryProvider
+	Summary func(pair string) (*order.TradeSummary, bool)
+}
+
+// NewKelly creates a fractional Kelly sizer, using the fallback sizer until minTrades trades are closed
+func NewKelly(fraction float64, minTrades int, fallback Sizer) *Kelly {
+	return &Kelly{
+		Fraction:  fraction,
+		MinTrades: minTrades,
+		Fallback:  fallback,
+	}
+}
+
+// KellyFraction returns the optimal fraction of the equity to allocate: W - (1 - W) / R,
+// where W is the win rate and R the payoff. A negative edge returns zero.
+func KellyFraction(summary *order.TradeSummary) float64 {
+	trades := len(summary.Win()) + len(summary.Lose())
+	if trades == 0 {
+		return 0
+	}
+
+	payoff := summary.Payoff()
+	if payoff == 0 {
+		// no losses yet or no wins yet
+		if len(summary.Lose()) == 0 {
+			return 1
+		}
+		return 0
+	}
+
+	winRate := float64(len(summary.Win())) / float64(trades)
+	fraction := winRate - (1-winRate)/payoff
+	if fraction < 0 {
+		return 0
+	}
+	return fraction
+}
+
+// Size returns the quantity worth the fractional Kelly allocation of the equity
+func (s *Kelly) Size(ctx context.Context, side core.SideType, broker core.Broker, df *core.Dataframe) (float64, error) {
+	summary, ok := s.summary(broker, df.Pair)
+	if !ok || len(summary.Win())+len(summary.Lose()) < s.MinTrades {
+		if s.Fallback == nil {
+			return 0, fmt.Errorf("%w: not enough trades for kelly sizing", ErrInsufficientData)
+		}
+		return s.Fallback.Size(ctx, side, broker, df)
+	}
+
+	fraction := KellyFraction(summary) * s.Fraction
+	if s.MaxFraction > 0 && fraction > s.MaxFraction {
+		fraction = s.MaxFraction
+	}
+
+	snapshot, err := TakeSnapshot(ctx, broker, df)
+	if err != nil {
+		return 0, err
+	}
+
+	return finalize(broker, df.Pair, side, snapshot, snapshot.Equity*fraction/snapshot.Price)
+}
+
+// summary returns the trade summary of the pair
+func (s *Kelly) summary(broker core.Broker, pair string) (*order.TradeSummary, bool) {
+	if s.Summary != nil {
+		return s.Summary(pair)
+	}
+
+	if provider, ok := broker.(SummaryProvider); ok {
+		return provider.TradeSummary(pair)
+	}
+
+	return nil, false
+}
@@ -0,0 +1,127 @@
+// Package sizing provides position sizing models that strategies can use to
+// calculate order quantities from the account, the current dataframe and past results.
+package sizing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/order"
+)
+
+// Errors returned by sizers
+var (
+	ErrInvalidPrice        = errors.New("invalid price")
+	ErrInsufficientData    = errors.New("insufficient data")
+	ErrBelowMinQuantity    = errors.New("quantity below minimum")
+	ErrInvalidStopDistance = errors.New("invalid stop distance")
+)
+
+// Sizer calculates the quantity, in base asset, of the next order of a side on the dataframe pair
+type Sizer interface {
+	Size(ctx context.Context, side core.SideType, broker core.Broker, df *core.Dataframe) (float64, error)
+}
+
+// SizerFunc is an adapter to use ordinary functions as sizers
+type SizerFunc func(ctx context.Context, side core.SideType, broker core.Broker, df *core.Dataframe) (float64, error)
+
+// Size calls f(ctx, side, broker, df)
+func (f SizerFunc) Size(ctx context.Context, side core.SideType, broker core.Broker, df *core.Dataframe) (float64, error) {
+	return f(ctx, side, broker, df)
+}
+
+// AssetInfoProvider is implemented by brokers exposing the trading rules of a pair,
+// such as order.Controller and order.StrategyBroker
+type AssetInfoProvider interface {
+	AssetsInfo(pair string) (core.AssetInfo, error)
+}
+
+// SummaryProvider is implemented by brokers exposing the running trade summary of a pair,
+// such as order.Controller and order.StrategyBroker
+type SummaryProvider interface {
+	TradeSummary(pair string) (*order.TradeSummary, bool)
+}
+
+// Snapshot holds the account values used by the sizing models
+type Snapshot struct {
+	Price  float64 // last close price of the pair
+	Asset  float64 // base asset balance
+	Quote  float64 // quote asset balance
+	Equity float64 // quote balance plus the value of the base asset
+}
+
+// TakeSnapshot reads the balances of the dataframe pair and values them at the last close price
+func TakeSnapshot(ctx context.Context, broker core.Broker, df *core.Dataframe) (Snapshot, error) {
+	if len(df.Close) == 0 {
+		return Snapshot{}, fmt.Errorf("%w: empty dataframe", ErrInsufficientData)
+	}
+
+	price := df.Close.Last(0)
+	if price <= 0 {
+		return Snapshot{}, fmt.Errorf("%w: %f", ErrInvalidPrice, price)
+	}
+
+	asset, quote, err := broker.Position(ctx, df.Pair)
+	if err != nil {
+		return Snapshot{}, err
+	}
+
+	return Snapshot{
+		Price:  price,
+		Asset:  asset,
+		Quote:  quote,
+		Equity: quote + asset*price,
+	}, nil
+}
+
+// Round adjusts a quantity to the step size of the pair, returning ErrBelowMinQuantity
+// when the rounded quantity is lower than the minimum quantity accepted by the exchange
+func Round(quantity float64, info core.AssetInfo) (float64, error) {
+	if info.StepSize > 0 {
+		// the tolerance keeps exact multiples from being floored down by floating point errors
+		quantity = math.Floor(quantity/info.StepSize+1e-9) * info.StepSize
+		if info.BaseAssetPrecision > 0 {
+			scale := math.Pow10(info.BaseAssetPrecision)
+			quantity = math.Round(quantity*scale) / scale
+		}
+	}
+
+	if info.MaxQuantity > 0 {
+		quantity = math.Min(quantity, info.MaxQuantity)
+	}
+
+	if quantity <= 0 || quantity < info.MinQuantity {
+		return 0, fmt.Errorf("%w: %f < %f", ErrBelowMinQuantity, quantity, info.MinQuantity)
+	}
+
+	return quantity, nil
+}
+
+// finalize caps the quantity to the asset held for sells reducing a long, or to what the quote
+// balance can buy or margin otherwise, such as for sells opening a short, and rounds it to the
+// pair rules when the broker exposes them
+func finalize(broker core.Broker, pair string, side core.SideType, snapshot Snapshot, quantity float64) (float64, error) {
+	if side == core.SideTypeSell && snapshot.Asset > 0 {
+		quantity = math.Min(quantity, snapshot.Asset)
+	} else {
+		quantity = math.Min(quantity, snapshot.Quote/snapshot.Price)
+	}
+	if math.IsNaN(quantity) || quantity <= 0 {
+		return 0, fmt.Errorf("%w: %f", ErrBelowMinQuantity, quantity)
+	}
+
+	provider, ok := broker.(AssetInfoProvider)
+	if !ok {
+		return quantity, nil
+	}
+
+	info, err := provider.AssetsInfo(pair)
+	if err != nil {
+		return 0, err
+	}
+
+	return Round(quantity, info)
+}
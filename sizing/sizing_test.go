@@ -0,0 +1,184 @@
+package sizing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/raykavin/backnrun/order"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBroker struct {
+	core.Broker
+	asset, quote float64
+	info         core.AssetInfo
+	summary      *order.TradeSummary
+}
+
+func (b fakeBroker) Position(_ context.Context, _ string) (float64, float64, error) {
+	return b.asset, b.quote, nil
+}
+
+func (b fakeBroker) AssetsInfo(_ string) (core.AssetInfo, error) {
+	return b.info, nil
+}
+
+func (b fakeBroker) TradeSummary(_ string) (*order.TradeSummary, bool) {
+	return b.summary, b.summary != nil
+}
+
+func newBroker() fakeBroker {
+	return fakeBroker{
+		asset: 1,
+		quote: 9000,
+		info: core.AssetInfo{
+			BaseAsset:          "BTC",
+			QuoteAsset:         "USDT",
+			MinQuantity:        0.01,
+			StepSize:           0.001,
+			BaseAssetPrecision: 3,
+		},
+	}
+}
+
+// dataframe with a constant true range of 20 and last close at 1000
+func newDataframe() *core.Dataframe {
+	df := &core.Dataframe{Pair: "BTCUSDT"}
+	for i := 0; i < 30; i++ {
+		df.Close = append(df.Close, 1000)
+		df.Open = append(df.Open, 1000)
+		df.High = append(df.High, 1010)
+		df.Low = append(df.Low, 990)
+	}
+	return df
+}
+
+func TestFixedQuote(t *testing.T) {
+	quantity, err := NewFixedQuote(1234).Size(context.Background(), core.SideTypeBuy, newBroker(), newDataframe())
+	require.NoError(t, err)
+	assert.Equal(t, 1.234, quantity)
+
+	// capped by the quote balance
+	quantity, err = NewFixedQuote(20000).Size(context.Background(), core.SideTypeBuy, newBroker(), newDataframe())
+	require.NoError(t, err)
+	assert.Equal(t, 9.0, quantity)
+
+	// sells are capped by the asset held, not by the quote balance
+	quantity, err = NewFixedQuote(500).Size(context.Background(), core.SideTypeSell, newBroker(), newDataframe())
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, quantity)
+
+	quantity, err = NewFixedQuote(20000).Size(context.Background(), core.SideTypeSell, newBroker(), newDataframe())
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, quantity)
+
+	broker := newBroker()
+	broker.quote = 0
+	quantity, err = NewFixedQuote(500).Size(context.Background(), core.SideTypeSell, broker, newDataframe())
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, quantity)
+
+	broker.asset = 0
+	_, err = NewFixedQuote(500).Size(context.Background(), core.SideTypeSell, broker, newDataframe())
+	require.ErrorIs(t, err, ErrBelowMinQuantity)
+
+	// without a long to reduce, sells open a short capped by the quote balance
+	broker = newBroker()
+	broker.asset = 0
+	quantity, err = NewFixedQuote(500).Size(context.Background(), core.SideTypeSell, broker, newDataframe())
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, quantity)
+
+	broker.asset = -2
+	quantity, err = NewFixedQuote(20000).Size(context.Background(), core.SideTypeSell, broker, newDataframe())
+	require.NoError(t, err)
+	assert.Equal(t, 9.0, quantity)
+}
+
+func TestFixedFractional(t *testing.T) {
+	// equity = 9000 + 1 * 1000
+	quantity, err := NewFixedFractional(0.25).Size(context.Background(), core.SideTypeBuy, newBroker(), newDataframe())
+	require.NoError(t, err)
+	assert.Equal(t, 2.5, quantity)
+}
+
+func TestFixedRisk(t *testing.T) {
+	t.Run("stop distance", func(t *testing.T) {
+		quantity, err := NewFixedRisk(0.01, StopDistance(50)).Size(context.Background(), core.SideTypeBuy, newBroker(), newDataframe())
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, quantity)
+	})
+
+	t.Run("stop percent", func(t *testing.T) {
+		quantity, err := NewFixedRisk(0.01, StopPercent(0.04)).Size(context.Background(), core.SideTypeBuy, newBroker(), newDataframe())
+		require.NoError(t, err)
+		assert.Equal(t, 2.5, quantity)
+	})
+
+	t.Run("stop ATR", func(t *testing.T) {
+		quantity, err := NewFixedRisk(0.01, StopATR(14, 2)).Size(context.Background(), core.SideTypeBuy, newBroker(), newDataframe())
+		require.NoError(t, err)
+		assert.Equal(t, 2.5, quantity)
+	})
+
+	t.Run("invalid stop", func(t *testing.T) {
+		_, err := NewFixedRisk(0.01, StopDistance(0)).Size(context.Background(), core.SideTypeBuy, newBroker(), newDataframe())
+		require.ErrorIs(t, err, ErrInvalidStopDistance)
+	})
+}
+
+func TestVolatilityTarget(t *testing.T) {
+	quantity, err := NewVolatilityTarget(0.01, 14).Size(context.Background(), core.SideTypeBuy, newBroker(), newDataframe())
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, quantity)
+
+	_, err = NewVolatilityTarget(0.01, 50).Size(context.Background(), core.SideTypeBuy, newBroker(), newDataframe())
+	require.ErrorIs(t, err, ErrInsufficientData)
+}
+
+func TestKelly(t *testing.T) {
+	broker := newBroker()
+
+	t.Run("fallback without trades", func(t *testing.T) {
+		quantity, err := NewKelly(0.5, 4, NewFixedQuote(100)).Size(context.Background(), core.SideTypeBuy, broker, newDataframe())
+		require.NoError(t, err)
+		assert.Equal(t, 0.1, quantity)
+	})
+
+	// win rate 60%, payoff 2: kelly = 0.6 - 0.4 / 2 = 0.4
+	broker.summary = &order.TradeSummary{
+		Pair:            "BTCUSDT",
+		WinLong:         []float64{20, 20, 20},
+		WinLongPercent:  []float64{0.02, 0.02, 0.02},
+		LoseLong:        []float64{-10, -10},
+		LoseLongPercent: []float64{-0.01, -0.01},
+	}
+	require.InDelta(t, 0.4, KellyFraction(broker.summary), 1e-9)
+
+	t.Run("half kelly", func(t *testing.T) {
+		quantity, err := NewKelly(0.5, 4, nil).Size(context.Background(), core.SideTypeBuy, broker, newDataframe())
+		require.NoError(t, err)
+		assert.Equal(t, 2.0, quantity)
+	})
+
+	t.Run("max fraction", func(t *testing.T) {
+		kelly := NewKelly(1, 4, nil)
+		kelly.MaxFraction = 0.1
+		quantity, err := kelly.Size(context.Background(), core.SideTypeBuy, broker, newDataframe())
+		require.NoError(t, err)
+		assert.Equal(t, 1.0, quantity)
+	})
+}
+
+func TestRound(t *testing.T) {
+	info := newBroker().info
+
+	quantity, err := Round(1.23456, info)
+	require.NoError(t, err)
+	assert.Equal(t, 1.234, quantity)
+
+	_, err = Round(0.0099, info)
+	require.ErrorIs(t, err, ErrBelowMinQuantity)
+}
@@ -0,0 +1,40 @@
+package sizing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/raykavin/backnrun/core"
+)
+
+// VolatilityTarget sizes orders so that a move of one ATR changes the equity by a target fraction.
+// Positions get smaller as volatility rises and larger as it falls.
+type VolatilityTarget struct {
+	Target float64
+	Period int
+}
+
+// NewVolatilityTarget creates an ATR based sizer, eg: target 0.01 with period 14
+// makes a 14 period ATR move worth 1% of the equity
+func NewVolatilityTarget(target float64, period int) *VolatilityTarget {
+	return &VolatilityTarget{Target: target, Period: period}
+}
+
+// Size returns the quantity whose ATR move is worth the target fraction of the equity
+func (s *VolatilityTarget) Size(ctx context.Context, side core.SideType, broker core.Broker, df *core.Dataframe) (float64, error) {
+	atr, err := lastATR(df, s.Period)
+	if err != nil {
+		return 0, err
+	}
+
+	if atr <= 0 {
+		return 0, fmt.Errorf("%w: ATR %f", ErrInsufficientData, atr)
+	}
+
+	snapshot, err := TakeSnapshot(ctx, broker, df)
+	if err != nil {
+		return 0, err
+	}
+
+	return finalize(broker, df.Pair, side, snapshot, snapshot.Equity*s.Target/atr)
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log"
 	"strconv"
+	"strings"
 	"sync/atomic"
+	"time"
 
 	"github.com/raykavin/backnrun/core"
 	"github.com/tidwall/buntdb"
@@ -15,12 +17,31 @@ import (
 const (
 	// DefaultIndexName is the default index used for order retrieval
 	DefaultIndexName = "update_index"
+
+	// StatusIndexName is the index used to query orders by status
+	StatusIndexName = "status_index"
+
+	// PairIndexName is the index used to query orders by pair
+	PairIndexName = "pair_index"
+
+	// TradeIndexName is the index used for trade retrieval
+	TradeIndexName = "trade_index"
+
+	// orderKeyPrefix prefixes the keys of orders, followed by the order ID
+	orderKeyPrefix = "order:"
+
+	// tradeKeyPrefix prefixes the keys of trades, followed by the trade ID
+	tradeKeyPrefix = "trade:"
+
+	// candleKeyPrefix prefixes the keys of candles, followed by pair, timeframe and time
+	candleKeyPrefix = "candle:"
 )
 
 // BuntStorage implements the core.Storage interface using BuntDB
 type BuntStorage struct {
-	lastID int64
-	db     *buntdb.DB
+	lastID      int64
+	lastTradeID int64
+	db          *buntdb.DB
 }
 
 // BuntConfig holds configuration options for BuntDB
@@ -63,21 +84,99 @@ func NewBuntStorage(sourceFile string, config BuntConfig) (core.Storage, error)
 		return nil, fmt.Errorf("failed to configure buntdb: %w", err)
 	}
 
+	if err := migrateOrderKeys(db); err != nil {
+		return nil, fmt.Errorf("failed to migrate orders: %w", err)
+	}
+
 	// Create default index for ordering by update timestamp
-	if err := db.CreateIndex(DefaultIndexName, "*", buntdb.IndexJSON("updated_at")); err != nil {
+	if err := db.CreateIndex(DefaultIndexName, orderKeyPrefix+"*", buntdb.IndexJSON("updated_at")); err != nil {
 		return nil, fmt.Errorf("failed to create default index: %w", err)
 	}
 
+	// Create indexes for querying orders by status and pair, ordered by update timestamp
+	if err := db.CreateIndex(StatusIndexName, orderKeyPrefix+"*",
+		buntdb.IndexJSONCaseSensitive("status"), buntdb.IndexJSON("updated_at")); err != nil {
+		return nil, fmt.Errorf("failed to create status index: %w", err)
+	}
+	if err := db.CreateIndex(PairIndexName, orderKeyPrefix+"*",
+		buntdb.IndexJSONCaseSensitive("pair"), buntdb.IndexJSON("updated_at")); err != nil {
+		return nil, fmt.Errorf("failed to create pair index: %w", err)
+	}
+
+	// Create index for ordering trades by closing timestamp
+	if err := db.CreateIndex(TradeIndexName, tradeKeyPrefix+"*", buntdb.IndexJSON("closed_at")); err != nil {
+		return nil, fmt.Errorf("failed to create trade index: %w", err)
+	}
+
 	// Create any additional indexes from the configuration
 	for name, pattern := range config.AdditionalIndexes {
-		if err := db.CreateIndex(name, "*", buntdb.IndexJSON(pattern)); err != nil {
+		if err := db.CreateIndex(name, orderKeyPrefix+"*", buntdb.IndexJSON(pattern)); err != nil {
 			return nil, fmt.Errorf("failed to create index %s: %w", name, err)
 		}
 	}
 
-	return &BuntStorage{
+	storage := &BuntStorage{
 		db: db,
-	}, nil
+	}
+
+	// Continue the IDs of a file storage
+	err = db.View(func(tx *buntdb.Tx) error {
+		if storage.lastID, err = lastKeyID(tx, orderKeyPrefix); err != nil {
+			return err
+		}
+		storage.lastTradeID, err = lastKeyID(tx, tradeKeyPrefix)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read last IDs: %w", err)
+	}
+
+	return storage, nil
+}
+
+// migrateOrderKeys moves orders stored under plain numeric keys to prefixed keys
+func migrateOrderKeys(db *buntdb.DB) error {
+	return db.Update(func(tx *buntdb.Tx) error {
+		var keys []string
+		err := tx.AscendKeys("*", func(key, _ string) bool {
+			if _, err := strconv.ParseInt(key, 10, 64); err == nil {
+				keys = append(keys, key)
+			}
+			return true
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			value, err := tx.Delete(key)
+			if err != nil {
+				return err
+			}
+			if _, _, err := tx.Set(orderKeyPrefix+key, value, nil); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// lastKeyID returns the highest ID stored under a key prefix
+func lastKeyID(tx *buntdb.Tx, prefix string) (int64, error) {
+	var last int64
+	err := tx.AscendKeys(prefix+"*", func(key, _ string) bool {
+		if id, err := strconv.ParseInt(strings.TrimPrefix(key, prefix), 10, 64); err == nil && id > last {
+			last = id
+		}
+		return true
+	})
+	return last, err
+}
+
+// orderKey returns the key of an order
+func orderKey(id int64) string {
+	return orderKeyPrefix + strconv.FormatInt(id, 10)
 }
 
 // getID generates a unique ID for orders
@@ -85,6 +184,11 @@ func (b *BuntStorage) getID() int64 {
 	return atomic.AddInt64(&b.lastID, 1)
 }
 
+// getTradeID generates a unique ID for trades
+func (b *BuntStorage) getTradeID() int64 {
+	return atomic.AddInt64(&b.lastTradeID, 1)
+}
+
 // CreateOrder stores a new order in the database
 func (b *BuntStorage) CreateOrder(_ context.Context, order *core.Order) error {
 	// Use a context-aware version if BuntDB adds context support in future
@@ -98,8 +202,7 @@ func (b *BuntStorage) CreateOrder(_ context.Context, order *core.Order) error {
 			return fmt.Errorf("failed to marshal order: %w", err)
 		}
 
-		key := strconv.FormatInt(order.ID, 10)
-		_, _, err = tx.Set(key, string(content), nil)
+		_, _, err = tx.Set(orderKey(order.ID), string(content), nil)
 		if err != nil {
 			return fmt.Errorf("failed to store order: %w", err)
 		}
@@ -112,7 +215,7 @@ func (b *BuntStorage) CreateOrder(_ context.Context, order *core.Order) error {
 func (b *BuntStorage) UpdateOrder(_ context.Context, order *core.Order) error {
 	// Use a context-aware version if BuntDB adds context support in future
 	return b.db.Update(func(tx *buntdb.Tx) error {
-		id := strconv.FormatInt(order.ID, 10)
+		id := orderKey(order.ID)
 
 		// Check if order exists
 		_, err := tx.Get(id)
@@ -135,48 +238,103 @@ func (b *BuntStorage) UpdateOrder(_ context.Context, order *core.Order) error {
 }
 
 // Orders retrieves orders from the database based on provided filters
-func (b *BuntStorage) Orders(_ context.Context, filters ...core.OrderFilter) ([]*core.Order, error) {
+func (b *BuntStorage) Orders(ctx context.Context, filters ...core.OrderFilter) ([]*core.Order, error) {
+	return b.QueryOrders(ctx, core.OrderQuery{Filters: filters})
+}
+
+// QueryOrders retrieves orders matching a structured query, scanning
+// the pair or status index when the query selects a pair or statuses
+func (b *BuntStorage) QueryOrders(_ context.Context, query core.OrderQuery) ([]*core.Order, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
 	orders := make([]*core.Order, 0)
+	visit := func(order core.Order) {
+		if query.Match(order) {
+			orders = append(orders, &order)
+		}
+	}
 
 	// Use a context-aware version if BuntDB adds context support in future
 	err := b.db.View(func(tx *buntdb.Tx) error {
-		err := tx.Ascend(DefaultIndexName, func(key, value string) bool {
-			var order core.Order
-			if err := json.Unmarshal([]byte(value), &order); err != nil {
-				log.Printf("Failed to unmarshal order %s: %v", key, err)
-				return true // Continue iteration
+		switch {
+		case query.Pair != "":
+			return ascendOrdersEqual(tx, PairIndexName, "pair", query.Pair, visit)
+		case len(query.Status) > 0:
+			for _, status := range query.Status {
+				if err := ascendOrdersEqual(tx, StatusIndexName, "status", string(status), visit); err != nil {
+					return err
+				}
 			}
+			return nil
+		default:
+			return ascendOrders(tx, DefaultIndexName, visit)
+		}
+	})
 
-			// Skip filtering if no filters provided
-			if len(filters) == 0 {
-				orders = append(orders, &order)
-				return true
-			}
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
 
-			// Apply all filters
-			for _, filter := range filters {
-				if !filter(order) {
-					return true // Skip this order and continue iteration
-				}
-			}
+	query.Sort(orders)
+	return query.Page(orders), nil
+}
 
-			// All filters passed, add this order
-			orders = append(orders, &order)
-			return true
-		})
+// ascendOrders visits every order of an index
+func ascendOrders(tx *buntdb.Tx, index string, visit func(core.Order)) error {
+	err := tx.Ascend(index, func(key, value string) bool {
+		var order core.Order
+		if err := json.Unmarshal([]byte(value), &order); err != nil {
+			log.Printf("Failed to unmarshal order %s: %v", key, err)
+			return true // Continue iteration
+		}
 
-		if err != nil {
-			return fmt.Errorf("failed to iterate over orders: %w", err)
+		visit(order)
+		return true
+	})
+
+	if err != nil {
+		return fmt.Errorf("failed to iterate over orders: %w", err)
+	}
+	return nil
+}
+
+// ascendOrdersEqual visits the orders of an index whose leading JSON field equals value
+func ascendOrdersEqual(tx *buntdb.Tx, index, field, value string, visit func(core.Order)) error {
+	pivot, err := json.Marshal(map[string]string{field: value})
+	if err != nil {
+		return fmt.Errorf("failed to marshal pivot: %w", err)
+	}
+
+	// The pivot has no update time, so it sorts before the orders with the same value
+	err = tx.AscendGreaterOrEqual(index, string(pivot), func(key, content string) bool {
+		var order core.Order
+		if err := json.Unmarshal([]byte(content), &order); err != nil {
+			log.Printf("Failed to unmarshal order %s: %v", key, err)
+			return true // Continue iteration
 		}
 
-		return nil
+		if fieldValue(order, field) != value {
+			return false
+		}
+
+		visit(order)
+		return true
 	})
 
 	if err != nil {
-		return nil, fmt.Errorf("failed to query orders: %w", err)
+		return fmt.Errorf("failed to iterate over orders with index %s: %w", index, err)
 	}
+	return nil
+}
 
-	return orders, nil
+// fieldValue returns the value of an indexed order field
+func fieldValue(order core.Order, field string) string {
+	if field == "status" {
+		return string(order.Status)
+	}
+	return order.Pair
 }
 
 // OrdersWithIndex retrieves orders using a specific index
@@ -217,6 +375,124 @@ func (b *BuntStorage) OrdersWithIndex(_ context.Context, indexName string, filte
 	return orders, nil
 }
 
+// CreateTrade stores a new trade in the database
+func (b *BuntStorage) CreateTrade(_ context.Context, trade *core.Trade) error {
+	return b.db.Update(func(tx *buntdb.Tx) error {
+		if trade.ID == 0 {
+			trade.ID = b.getTradeID()
+		}
+
+		content, err := json.Marshal(trade)
+		if err != nil {
+			return fmt.Errorf("failed to marshal trade: %w", err)
+		}
+
+		key := tradeKeyPrefix + strconv.FormatInt(trade.ID, 10)
+		_, _, err = tx.Set(key, string(content), nil)
+		if err != nil {
+			return fmt.Errorf("failed to store trade: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// Trades retrieves trades from the database based on provided filters, ordered by closing time
+func (b *BuntStorage) Trades(ctx context.Context, filters ...core.TradeFilter) ([]*core.Trade, error) {
+	return b.QueryTrades(ctx, core.TradeQuery{Filters: filters})
+}
+
+// QueryTrades retrieves trades matching a structured query, scanning the closing time index
+func (b *BuntStorage) QueryTrades(_ context.Context, query core.TradeQuery) ([]*core.Trade, error) {
+	trades := make([]*core.Trade, 0)
+
+	err := b.db.View(func(tx *buntdb.Tx) error {
+		err := tx.Ascend(TradeIndexName, func(key, value string) bool {
+			var trade core.Trade
+			if err := json.Unmarshal([]byte(value), &trade); err != nil {
+				log.Printf("Failed to unmarshal trade %s: %v", key, err)
+				return true
+			}
+
+			if !query.Match(trade) {
+				return true
+			}
+
+			trades = append(trades, &trade)
+			return true
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to iterate over trades: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trades: %w", err)
+	}
+
+	return trades, nil
+}
+
+// candleKey returns the key of a candle, sorted by time within a pair and timeframe
+func candleKey(pair, timeframe string, t time.Time) string {
+	return fmt.Sprintf("%s%s:%s:%020d", candleKeyPrefix, pair, timeframe, t.UnixNano())
+}
+
+// SaveCandles stores candles in the database, replacing those with the same pair, timeframe and time
+func (b *BuntStorage) SaveCandles(_ context.Context, timeframe string, candles []core.Candle) error {
+	return b.db.Update(func(tx *buntdb.Tx) error {
+		for _, candle := range candles {
+			content, err := json.Marshal(newCandleRecord(timeframe, candle))
+			if err != nil {
+				return fmt.Errorf("failed to marshal candle: %w", err)
+			}
+
+			_, _, err = tx.Set(candleKey(candle.Pair, timeframe, candle.Time), string(content), nil)
+			if err != nil {
+				return fmt.Errorf("failed to store candle: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Candles retrieves the candles of a pair and timeframe within [start, end], ordered by time
+func (b *BuntStorage) Candles(_ context.Context, pair, timeframe string, start, end time.Time) ([]core.Candle, error) {
+	candles := make([]core.Candle, 0)
+
+	err := b.db.View(func(tx *buntdb.Tx) error {
+		first := candleKey(pair, timeframe, start)
+		last := candleKey(pair, timeframe, end.Add(time.Nanosecond))
+
+		err := tx.AscendRange("", first, last, func(key, value string) bool {
+			var record candleRecord
+			if err := json.Unmarshal([]byte(value), &record); err != nil {
+				log.Printf("Failed to unmarshal candle %s: %v", key, err)
+				return true
+			}
+
+			candles = append(candles, record.candle())
+			return true
+		})
+
+		if err != nil {
+			return fmt.Errorf("failed to iterate over candles: %w", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to query candles: %w", err)
+	}
+
+	return candles, nil
+}
+
 // WithTransaction executes operations in a transaction
 // Note: This is a simplified version as BuntDB's transaction model is different from SQL databases
 func (b *BuntStorage) WithTransaction(ctx context.Context, fn func(tx any) error) error {
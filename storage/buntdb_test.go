@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/buntdb"
+)
+
+func TestBuntStorage(t *testing.T) {
+	storage, err := FromMemory()
+	require.NoError(t, err)
+
+	testStorage(t, storage, "")
+}
+
+func TestBuntStorage_File(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "backnrun.db")
+
+	// orders stored under plain numeric keys by previous versions
+	db, err := buntdb.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, db.Update(func(tx *buntdb.Tx) error {
+		_, _, err := tx.Set("7", `{"id":7,"pair":"BTCUSDT","status":"FILLED"}`, nil)
+		return err
+	}))
+	require.NoError(t, db.Close())
+
+	storage, err := FromFile(path)
+	require.NoError(t, err)
+
+	orders, err := storage.QueryOrders(ctx, core.OrderQuery{Pair: "BTCUSDT"})
+	require.NoError(t, err)
+	require.Len(t, orders, 1)
+	assert.Equal(t, int64(7), orders[0].ID)
+
+	// new orders continue the stored IDs
+	order := core.Order{Pair: "ETHUSDT"}
+	require.NoError(t, storage.CreateOrder(ctx, &order))
+	assert.Equal(t, int64(8), order.ID)
+}
@@ -0,0 +1,53 @@
+package storage
+
+import (
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+)
+
+// candleRecord is the stored form of a candle, identified by pair, timeframe and time
+type candleRecord struct {
+	Pair      string    `json:"pair" gorm:"primaryKey"`
+	Timeframe string    `json:"timeframe" gorm:"primaryKey"`
+	Time      time.Time `json:"time" gorm:"primaryKey"`
+	Open      float64   `json:"open"`
+	Close     float64   `json:"close"`
+	Low       float64   `json:"low"`
+	High      float64   `json:"high"`
+	Volume    float64   `json:"volume"`
+}
+
+// TableName returns the table name of stored candles
+func (candleRecord) TableName() string {
+	return "candles"
+}
+
+// newCandleRecord converts a candle to its stored form, with the time in UTC
+func newCandleRecord(timeframe string, candle core.Candle) candleRecord {
+	return candleRecord{
+		Pair:      candle.Pair,
+		Timeframe: timeframe,
+		Time:      candle.Time.UTC(),
+		Open:      candle.Open,
+		Close:     candle.Close,
+		Low:       candle.Low,
+		High:      candle.High,
+		Volume:    candle.Volume,
+	}
+}
+
+// candle converts a stored candle back to a complete candle
+func (r candleRecord) candle() core.Candle {
+	return core.Candle{
+		Pair:      r.Pair,
+		Time:      r.Time.UTC(),
+		UpdatedAt: r.Time.UTC(),
+		Open:      r.Open,
+		Close:     r.Close,
+		Low:       r.Low,
+		High:      r.High,
+		Volume:    r.Volume,
+		Complete:  true,
+	}
+}
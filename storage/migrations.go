@@ -0,0 +1,268 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrUnknownSchemaVersion is returned when the database was migrated by a newer version
+var ErrUnknownSchemaVersion = errors.New("database schema is newer than supported")
+
+// migrationLock identifies the lock serializing the migrations of processes sharing a database
+const (
+	migrationLockID   int64 = 0x6261636b6e72756e // "backnrun"
+	migrationLockName       = "backnrun_schema_migrations"
+)
+
+// schemaMigration records a migration applied to the database
+type schemaMigration struct {
+	Version     int `gorm:"primaryKey;autoIncrement:false"`
+	Description string
+	AppliedAt   time.Time
+}
+
+// TableName returns the table name of applied migrations
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migration is a versioned schema change. Each migration declares the tables as they
+// were at its version, so later changes to the models don't rewrite past migrations.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *gorm.DB) error
+}
+
+// migrations is the ordered list of schema changes, new migrations are appended
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create orders table",
+		up: func(tx *gorm.DB) error {
+			type orders struct {
+				ID         int64 `gorm:"primaryKey;autoIncrement"`
+				ExchangeID int64
+				Pair       string `gorm:"size:64"`
+				Side       string `gorm:"size:16"`
+				Type       string `gorm:"size:32"`
+				Status     string `gorm:"size:32"`
+				Price      float64
+				Quantity   float64
+				Strategy   string `gorm:"size:128"`
+				CreatedAt  time.Time
+				UpdatedAt  time.Time
+				Stop       *float64
+				GroupID    *int64
+			}
+			return tx.Table("orders").AutoMigrate(&orders{})
+		},
+	},
+	{
+		version:     2,
+		description: "create trades table",
+		up: func(tx *gorm.DB) error {
+			type trades struct {
+				ID            int64  `gorm:"primaryKey;autoIncrement"`
+				Strategy      string `gorm:"size:128"`
+				Pair          string `gorm:"size:64;index"`
+				Side          string `gorm:"size:16"`
+				EntryOrderID  int64
+				ExitOrderID   int64
+				EntryPrice    float64
+				ExitPrice     float64
+				Quantity      float64
+				Profit        float64
+				ProfitPercent float64
+				Fee           float64
+				Duration      int64
+				OpenedAt      time.Time
+				ClosedAt      time.Time `gorm:"index"`
+			}
+			return tx.Table("trades").AutoMigrate(&trades{})
+		},
+	},
+	{
+		version:     3,
+		description: "create candles table",
+		up: func(tx *gorm.DB) error {
+			type candles struct {
+				Pair      string    `gorm:"primaryKey;size:64"`
+				Timeframe string    `gorm:"primaryKey;size:16"`
+				Time      time.Time `gorm:"primaryKey"`
+				Open      float64
+				Close     float64
+				Low       float64
+				High      float64
+				Volume    float64
+			}
+			return tx.Table("candles").AutoMigrate(&candles{})
+		},
+	},
+	{
+		version:     4,
+		description: "add namespace to orders and trades",
+		up: func(tx *gorm.DB) error {
+			type orders struct {
+				Namespace string `gorm:"size:64;not null;default:'';index:idx_orders_namespace_status,priority:1"`
+				Status    string `gorm:"size:32;index:idx_orders_namespace_status,priority:2"`
+			}
+			type trades struct {
+				Namespace string    `gorm:"size:64;not null;default:'';index:idx_trades_namespace_closed_at,priority:1"`
+				ClosedAt  time.Time `gorm:"index:idx_trades_namespace_closed_at,priority:2"`
+			}
+			if err := addColumn(tx.Table("orders"), &orders{}, "Namespace", "idx_orders_namespace_status"); err != nil {
+				return err
+			}
+			return addColumn(tx.Table("trades"), &trades{}, "Namespace", "idx_trades_namespace_closed_at")
+		},
+	},
+	{
+		version:     5,
+		description: "add slippage to orders",
+		up: func(tx *gorm.DB) error {
+			type orders struct {
+				Slippage float64
+			}
+			return tx.Table("orders").AutoMigrate(&orders{})
+		},
+	},
+	{
+		version:     6,
+		description: "add fees to orders",
+		up: func(tx *gorm.DB) error {
+			type orders struct {
+				Fee      float64
+				FeeAsset string `gorm:"size:32"`
+			}
+			return tx.Table("orders").AutoMigrate(&orders{})
+		},
+	},
+	{
+		version:     7,
+		description: "add executed quantity to orders",
+		up: func(tx *gorm.DB) error {
+			type orders struct {
+				ExecutedQuantity float64
+			}
+			return tx.Table("orders").AutoMigrate(&orders{})
+		},
+	},
+}
+
+// addColumn adds a column and an index of a model to a table when they are missing
+func addColumn(tx *gorm.DB, model any, column, index string) error {
+	migrator := tx.Migrator()
+	if !migrator.HasColumn(model, column) {
+		if err := migrator.AddColumn(model, column); err != nil {
+			return err
+		}
+	}
+	if !migrator.HasIndex(model, index) {
+		return migrator.CreateIndex(model, index)
+	}
+	return nil
+}
+
+// migrate applies the pending migrations, each in its own transaction. MySQL commits schema
+// changes implicitly, so a failed migration may be left partly applied there; migrations only
+// add missing tables, columns and indexes, and the next start completes them.
+// Processes starting together wait for each other, so only one applies the migrations.
+// Tables created by earlier versions without migrations are updated in place.
+func migrate(db *gorm.DB) error {
+	unlock, err := lockMigrations(db)
+	if err != nil {
+		return fmt.Errorf("failed to lock migrations: %w", err)
+	}
+	defer unlock()
+
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return fmt.Errorf("failed to create migrations table: %w", err)
+	}
+
+	var applied []schemaMigration
+	if err := db.Order("version").Find(&applied).Error; err != nil {
+		return fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+
+	current := 0
+	if len(applied) > 0 {
+		current = applied[len(applied)-1].Version
+	}
+	if current > migrations[len(migrations)-1].version {
+		return fmt.Errorf("%w: version %d", ErrUnknownSchemaVersion, current)
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{
+				Version:     m.version,
+				Description: m.description,
+				AppliedAt:   time.Now().UTC(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %d (%s): %w", m.version, m.description, err)
+		}
+	}
+
+	return nil
+}
+
+// lockMigrations waits for the lock on the migrations of the database, an advisory lock on PostgreSQL
+// and a named lock on MySQL, and returns the function releasing it. SQLite serializes writers itself.
+func lockMigrations(db *gorm.DB) (func(), error) {
+	var lock, unlock string
+	var key any
+	switch db.Dialector.Name() {
+	case "postgres":
+		lock, unlock, key = "SELECT pg_advisory_lock($1)", "SELECT pg_advisory_unlock($1)", migrationLockID
+	case "mysql":
+		lock, unlock, key = "SELECT GET_LOCK(?, -1)", "SELECT RELEASE_LOCK(?)", migrationLockName
+	default:
+		return func() {}, nil
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	// Session locks are held by a connection, which is kept until the lock is released
+	ctx := context.Background()
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired sql.NullInt64
+	if db.Dialector.Name() == "mysql" {
+		err = conn.QueryRowContext(ctx, lock, key).Scan(&acquired)
+		if err == nil && acquired.Int64 != 1 {
+			err = errors.New("lock not acquired")
+		}
+	} else {
+		_, err = conn.ExecContext(ctx, lock, key)
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return func() {
+		_, _ = conn.ExecContext(ctx, unlock, key)
+		conn.Close()
+	}, nil
+}
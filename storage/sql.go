@@ -8,13 +8,20 @@ import (
 
 	"github.com/raykavin/backnrun/core"
 	"github.com/samber/lo"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// candleBatchSize is the number of candles inserted per statement
+const candleBatchSize = 500
+
 // SQLStorage implements the core.Storage interface using a SQL database via GORM
 type SQLStorage struct {
-	db *gorm.DB
+	db        *gorm.DB
+	namespace string
 }
 
 // Config holds the configuration for SQL database connections
@@ -22,6 +29,10 @@ type Config struct {
 	MaxIdleConns    int
 	MaxOpenConns    int
 	ConnMaxLifetime time.Duration
+
+	// Namespace isolates the orders and trades of a bot, so several bots can share
+	// a database. Candles are shared by every namespace.
+	Namespace string
 }
 
 // DefaultConfig returns a default configuration for SQL connections
@@ -39,6 +50,18 @@ func NewFromSQLite(dbPath string, config Config, opts ...gorm.Option) (core.Stor
 	return newFromSQL(dialect, config, opts...)
 }
 
+// NewFromPostgres creates a new PostgreSQL storage instance,
+// e.g. with the DSN "host=localhost user=backnrun dbname=backnrun sslmode=disable"
+func NewFromPostgres(dsn string, config Config, opts ...gorm.Option) (core.Storage, error) {
+	return newFromSQL(postgres.Open(dsn), config, opts...)
+}
+
+// NewFromMySQL creates a new MySQL storage instance. The DSN must parse times,
+// e.g. "backnrun:secret@tcp(localhost:3306)/backnrun?parseTime=true"
+func NewFromMySQL(dsn string, config Config, opts ...gorm.Option) (core.Storage, error) {
+	return newFromSQL(mysql.Open(dsn), config, opts...)
+}
+
 // newFromSQL creates a new SQL storage instance with the specified configuration
 func newFromSQL(dialect gorm.Dialector, config Config, opts ...gorm.Option) (core.Storage, error) {
 	db, err := gorm.Open(dialect, opts...)
@@ -57,20 +80,27 @@ func newFromSQL(dialect gorm.Dialector, config Config, opts ...gorm.Option) (cor
 	sqlDB.SetMaxOpenConns(config.MaxOpenConns)
 	sqlDB.SetConnMaxLifetime(config.ConnMaxLifetime)
 
-	// Auto migrate the order model
-	if err = db.AutoMigrate(&core.Order{}); err != nil {
+	// Apply the pending schema migrations
+	if err = migrate(db); err != nil {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	return &SQLStorage{db: db}, nil
+	return &SQLStorage{db: db, namespace: config.Namespace}, nil
+}
+
+// inNamespace restricts a query to the records of the storage namespace
+func (s *SQLStorage) inNamespace(tx *gorm.DB) *gorm.DB {
+	return tx.Where("namespace = ?", s.namespace)
 }
 
 // CreateOrder creates a new order in the SQL database
 func (s *SQLStorage) CreateOrder(ctx context.Context, order *core.Order) error {
 	tx := s.db.WithContext(ctx)
-	if result := tx.Create(order); result.Error != nil {
+	record := orderRecord{Order: *order, Namespace: s.namespace}
+	if result := tx.Create(&record); result.Error != nil {
 		return fmt.Errorf("failed to create order: %w", result.Error)
 	}
+	*order = record.Order
 	return nil
 }
 
@@ -79,62 +109,198 @@ func (s *SQLStorage) UpdateOrder(ctx context.Context, order *core.Order) error {
 	tx := s.db.WithContext(ctx)
 
 	// Check if the order exists
-	var existing core.Order
-	if result := tx.First(&existing, order.ID); result.Error != nil {
+	var existing orderRecord
+	if result := tx.Scopes(s.inNamespace).First(&existing, order.ID); result.Error != nil {
 		return fmt.Errorf("order not found: %w", result.Error)
 	}
 
 	// Update the order
-	if result := tx.Save(order); result.Error != nil {
+	record := orderRecord{Order: *order, Namespace: s.namespace}
+	if result := tx.Save(&record); result.Error != nil {
 		return fmt.Errorf("failed to update order: %w", result.Error)
 	}
+	*order = record.Order
 
 	return nil
 }
 
 // Orders retrieves orders from the SQL database based on provided filters
 func (s *SQLStorage) Orders(ctx context.Context, filters ...core.OrderFilter) ([]*core.Order, error) {
-	tx := s.db.WithContext(ctx)
+	return s.QueryOrders(ctx, core.OrderQuery{Filters: filters})
+}
+
+// QueryOrders retrieves orders matching a structured query. Its conditions, sorting and paging
+// are translated into SQL, while paging falls back to memory when the query has filter functions.
+func (s *SQLStorage) QueryOrders(ctx context.Context, query core.OrderQuery) ([]*core.Order, error) {
+	if err := query.Validate(); err != nil {
+		return nil, err
+	}
+
+	tx := s.db.WithContext(ctx).Scopes(s.inNamespace)
+
+	// Apply the structured conditions
+	if len(query.Status) > 0 {
+		tx = tx.Where("status IN ?", query.Status)
+	}
+	if query.Pair != "" {
+		tx = tx.Where("pair = ?", query.Pair)
+	}
+	if query.Side != "" {
+		tx = tx.Where("side = ?", query.Side)
+	}
+	if query.GroupID != nil {
+		tx = tx.Where("group_id = ?", *query.GroupID)
+	}
+	tx = whereTimeRange(tx, string(core.OrderFieldCreatedAt), query.CreatedFrom, query.CreatedTo)
+	tx = whereTimeRange(tx, string(core.OrderFieldUpdatedAt), query.UpdatedFrom, query.UpdatedTo)
 
-	// Start building the query
-	query := tx
+	// Sort with the order ID breaking ties
+	direction := ""
+	if query.Descending {
+		direction = " DESC"
+	}
+	tx = tx.Order(string(query.SortField()) + direction)
+	if query.SortField() != core.OrderFieldID {
+		tx = tx.Order(string(core.OrderFieldID) + direction)
+	}
 
-	// Apply filters directly to the query if possible
-	// This is a placeholder for future optimization
-	// For now, we'll still apply filters in memory
+	if len(query.Filters) == 0 {
+		if query.Offset > 0 {
+			tx = tx.Offset(query.Offset)
+		}
+		if query.Limit > 0 {
+			tx = tx.Limit(query.Limit)
+		}
+	}
 
-	var orders []*core.Order
-	if result := query.Find(&orders); result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+	var records []orderRecord
+	if result := tx.Find(&records); result.Error != nil && result.Error != gorm.ErrRecordNotFound {
 		return nil, fmt.Errorf("failed to fetch orders: %w", result.Error)
 	}
+	orders := ordersFromRecords(records)
 
-	// Apply filters in memory
-	if len(filters) > 0 {
+	// Apply filter functions and paging in memory
+	if len(query.Filters) > 0 {
 		orders = lo.Filter(orders, func(order *core.Order, _ int) bool {
-			for _, filter := range filters {
-				if !filter(*order) {
-					return false
-				}
-			}
-			return true
+			return query.MatchFilters(*order)
 		})
+		orders = query.Page(orders)
 	}
 
 	return orders, nil
 }
 
-// OrdersWithQuery allows for more customized querying using GORM's query builder
+// whereTimeRange adds the inclusive bounds of a time column to a query, zero bounds are open
+func whereTimeRange(tx *gorm.DB, column string, from, to time.Time) *gorm.DB {
+	if !from.IsZero() {
+		tx = tx.Where(column+" >= ?", from)
+	}
+	if !to.IsZero() {
+		tx = tx.Where(column+" <= ?", to)
+	}
+	return tx
+}
+
+// OrdersWithQuery allows for more customized querying using GORM's query builder,
+// restricted to the orders of the storage namespace
 func (s *SQLStorage) OrdersWithQuery(ctx context.Context, queryFn func(*gorm.DB) *gorm.DB) ([]*core.Order, error) {
-	tx := s.db.WithContext(ctx)
+	tx := s.db.WithContext(ctx).Scopes(s.inNamespace)
 
-	var orders []*core.Order
-	result := queryFn(tx).Find(&orders)
+	var records []orderRecord
+	result := queryFn(tx).Find(&records)
 
 	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
 		return nil, fmt.Errorf("failed to execute query: %w", result.Error)
 	}
 
-	return orders, nil
+	return ordersFromRecords(records), nil
+}
+
+// CreateTrade creates a new trade in the SQL database
+func (s *SQLStorage) CreateTrade(ctx context.Context, trade *core.Trade) error {
+	tx := s.db.WithContext(ctx)
+	record := tradeRecord{Trade: *trade, Namespace: s.namespace}
+	if result := tx.Create(&record); result.Error != nil {
+		return fmt.Errorf("failed to create trade: %w", result.Error)
+	}
+	*trade = record.Trade
+	return nil
+}
+
+// Trades retrieves trades from the SQL database based on provided filters, ordered by closing time
+func (s *SQLStorage) Trades(ctx context.Context, filters ...core.TradeFilter) ([]*core.Trade, error) {
+	return s.QueryTrades(ctx, core.TradeQuery{Filters: filters})
+}
+
+// QueryTrades retrieves trades matching a structured query, ordered by closing time.
+// Its conditions are translated into SQL, while filter functions are applied in memory.
+func (s *SQLStorage) QueryTrades(ctx context.Context, query core.TradeQuery) ([]*core.Trade, error) {
+	tx := s.db.WithContext(ctx).Scopes(s.inNamespace)
+
+	// Apply the structured conditions
+	if query.Pair != "" {
+		tx = tx.Where("pair = ?", query.Pair)
+	}
+	if query.Strategy != "" {
+		tx = tx.Where("strategy = ?", query.Strategy)
+	}
+	tx = whereTimeRange(tx, "closed_at", query.ClosedFrom, query.ClosedTo)
+
+	var records []tradeRecord
+	if result := tx.Order("closed_at, id").Find(&records); result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to fetch trades: %w", result.Error)
+	}
+
+	trades := make([]*core.Trade, 0, len(records))
+	for i := range records {
+		trades = append(trades, &records[i].Trade)
+	}
+
+	// Apply filter functions in memory
+	if len(query.Filters) > 0 {
+		trades = lo.Filter(trades, func(trade *core.Trade, _ int) bool {
+			return query.MatchFilters(*trade)
+		})
+	}
+
+	return trades, nil
+}
+
+// SaveCandles stores candles in the SQL database, replacing those with the same pair, timeframe and time
+func (s *SQLStorage) SaveCandles(ctx context.Context, timeframe string, candles []core.Candle) error {
+	if len(candles) == 0 {
+		return nil
+	}
+
+	records := make([]candleRecord, 0, len(candles))
+	for _, candle := range candles {
+		records = append(records, newCandleRecord(timeframe, candle))
+	}
+
+	tx := s.db.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true})
+	if result := tx.CreateInBatches(records, candleBatchSize); result.Error != nil {
+		return fmt.Errorf("failed to save candles: %w", result.Error)
+	}
+	return nil
+}
+
+// Candles retrieves the candles of a pair and timeframe within [start, end], ordered by time
+func (s *SQLStorage) Candles(ctx context.Context, pair, timeframe string, start, end time.Time) ([]core.Candle, error) {
+	tx := s.db.WithContext(ctx)
+
+	var records []candleRecord
+	result := tx.Where("pair = ? AND timeframe = ? AND time >= ? AND time <= ?", pair, timeframe, start.UTC(), end.UTC()).
+		Order("time").
+		Find(&records)
+	if result.Error != nil && result.Error != gorm.ErrRecordNotFound {
+		return nil, fmt.Errorf("failed to fetch candles: %w", result.Error)
+	}
+
+	candles := make([]core.Candle, 0, len(records))
+	for _, record := range records {
+		candles = append(candles, record.candle())
+	}
+	return candles, nil
 }
 
 // WithTransaction executes the given function within a database transaction
@@ -0,0 +1,34 @@
+package storage
+
+import "github.com/raykavin/backnrun/core"
+
+// orderRecord is the stored form of an order, scoped by the namespace of the bot
+type orderRecord struct {
+	core.Order
+	Namespace string
+}
+
+// TableName returns the table name of stored orders
+func (orderRecord) TableName() string {
+	return "orders"
+}
+
+// tradeRecord is the stored form of a trade, scoped by the namespace of the bot
+type tradeRecord struct {
+	core.Trade
+	Namespace string
+}
+
+// TableName returns the table name of stored trades
+func (tradeRecord) TableName() string {
+	return "trades"
+}
+
+// ordersFromRecords returns the orders of stored records
+func ordersFromRecords(records []orderRecord) []*core.Order {
+	orders := make([]*core.Order, 0, len(records))
+	for i := range records {
+		orders = append(orders, &records[i].Order)
+	}
+	return orders
+}
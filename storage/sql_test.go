@@ -0,0 +1,186 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Environment variables with the DSN of local databases used by the integration tests
+const (
+	postgresDSNEnv = "BACKNRUN_TEST_POSTGRES_DSN"
+	mysqlDSNEnv    = "BACKNRUN_TEST_MYSQL_DSN"
+)
+
+var quiet = &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)}
+
+// testConfig returns a configuration with a namespace unique to the test run
+func testConfig(t *testing.T) Config {
+	config := DefaultConfig()
+	config.Namespace = fmt.Sprintf("%s-%d", t.Name(), time.Now().UnixNano())
+	return config
+}
+
+// testSQLStorage runs the shared and namespace tests against a SQL database
+func testSQLStorage(t *testing.T, open func(config Config) (core.Storage, error)) {
+	config := testConfig(t)
+	storage, err := open(config)
+	require.NoError(t, err)
+	defer storage.(*SQLStorage).Close()
+
+	testStorage(t, storage, config.Namespace)
+
+	t.Run("namespace", func(t *testing.T) {
+		ctx := context.Background()
+		other, err := open(testConfig(t))
+		require.NoError(t, err)
+		defer other.(*SQLStorage).Close()
+
+		orders, err := other.Orders(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, orders)
+
+		trades, err := other.Trades(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, trades)
+
+		// orders of other namespaces can't be updated
+		orders, err = storage.Orders(ctx)
+		require.NoError(t, err)
+		require.NotEmpty(t, orders)
+		require.Error(t, other.UpdateOrder(ctx, orders[0]))
+	})
+}
+
+func TestSQLStorage_SQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "backnrun.db")
+	testSQLStorage(t, func(config Config) (core.Storage, error) {
+		return NewFromSQLite(path, config, quiet)
+	})
+}
+
+func TestSQLStorage_Postgres(t *testing.T) {
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresDSNEnv)
+	}
+
+	testSQLStorage(t, func(config Config) (core.Storage, error) {
+		return NewFromPostgres(dsn, config, quiet)
+	})
+}
+
+func TestSQLStorage_MySQL(t *testing.T) {
+	dsn := os.Getenv(mysqlDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", mysqlDSNEnv)
+	}
+
+	testSQLStorage(t, func(config Config) (core.Storage, error) {
+		return NewFromMySQL(dsn, config, quiet)
+	})
+}
+
+func TestMigrate_Concurrent(t *testing.T) {
+	databases := []struct {
+		name string
+		env  string
+		open func(dsn string) (core.Storage, error)
+	}{
+		{"postgres", postgresDSNEnv, func(dsn string) (core.Storage, error) {
+			return NewFromPostgres(dsn, DefaultConfig(), quiet)
+		}},
+		{"mysql", mysqlDSNEnv, func(dsn string) (core.Storage, error) {
+			return NewFromMySQL(dsn, DefaultConfig(), quiet)
+		}},
+	}
+
+	for _, database := range databases {
+		t.Run(database.name, func(t *testing.T) {
+			dsn := os.Getenv(database.env)
+			if dsn == "" {
+				t.Skipf("%s is not set", database.env)
+			}
+
+			// bots starting together against a shared database
+			errs := make([]error, 4)
+			var wg sync.WaitGroup
+			for i := range errs {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					storage, err := database.open(dsn)
+					if err == nil {
+						err = storage.(io.Closer).Close()
+					}
+					errs[i] = err
+				}(i)
+			}
+			wg.Wait()
+
+			for _, err := range errs {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	t.Run("versions", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "backnrun.db")), quiet)
+		require.NoError(t, err)
+
+		require.NoError(t, migrate(db))
+		require.NoError(t, migrate(db))
+
+		var applied []schemaMigration
+		require.NoError(t, db.Order("version").Find(&applied).Error)
+		require.Len(t, applied, len(migrations))
+		for i, m := range migrations {
+			assert.Equal(t, m.version, applied[i].Version)
+		}
+
+		assert.True(t, db.Migrator().HasColumn(&orderRecord{}, "namespace"))
+		assert.True(t, db.Migrator().HasColumn(&tradeRecord{}, "namespace"))
+		assert.True(t, db.Migrator().HasTable(&candleRecord{}))
+	})
+
+	t.Run("database created without migrations", func(t *testing.T) {
+		ctx := context.Background()
+		path := filepath.Join(t.TempDir(), "backnrun.db")
+
+		db, err := gorm.Open(sqlite.Open(path), quiet)
+		require.NoError(t, err)
+		require.NoError(t, db.AutoMigrate(&core.Order{}))
+		require.NoError(t, db.Create(&core.Order{Pair: "BTCUSDT", Status: core.OrderStatusTypeFilled}).Error)
+
+		storage, err := NewFromSQLite(path, DefaultConfig(), quiet)
+		require.NoError(t, err)
+
+		orders, err := storage.Orders(ctx)
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		assert.Equal(t, "BTCUSDT", orders[0].Pair)
+	})
+
+	t.Run("newer schema", func(t *testing.T) {
+		db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "backnrun.db")), quiet)
+		require.NoError(t, err)
+
+		require.NoError(t, migrate(db))
+		require.NoError(t, db.Create(&schemaMigration{Version: len(migrations) + 1}).Error)
+		require.ErrorIs(t, migrate(db), ErrUnknownSchemaVersion)
+	})
+}
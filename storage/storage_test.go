@@ -0,0 +1,155 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/raykavin/backnrun/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testStorage runs the behaviour shared by every storage backend. The pair prefix keeps
+// candles of different runs apart in databases that outlive the test.
+func testStorage(t *testing.T, storage core.Storage, pairPrefix string) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	groupID := int64(1)
+
+	orders := []*core.Order{
+		{Pair: "BTCUSDT", Side: core.SideTypeBuy, Status: core.OrderStatusTypeFilled, CreatedAt: start, UpdatedAt: start},
+		{Pair: "ETHUSDT", Side: core.SideTypeBuy, Status: core.OrderStatusTypeNew, CreatedAt: start.Add(time.Hour), UpdatedAt: start.Add(3 * time.Hour)},
+		{Pair: "BTCUSDT", Side: core.SideTypeSell, Status: core.OrderStatusTypeNew, CreatedAt: start.Add(2 * time.Hour), UpdatedAt: start.Add(2 * time.Hour), GroupID: &groupID},
+	}
+	for _, order := range orders {
+		require.NoError(t, storage.CreateOrder(ctx, order))
+		require.NotZero(t, order.ID)
+	}
+
+	ids := func(orders []*core.Order) []int64 {
+		result := make([]int64, 0, len(orders))
+		for _, order := range orders {
+			result = append(result, order.ID)
+		}
+		return result
+	}
+
+	t.Run("update order", func(t *testing.T) {
+		orders[1].Status = core.OrderStatusTypeFilled
+		require.NoError(t, storage.UpdateOrder(ctx, orders[1]))
+
+		result, err := storage.Orders(ctx, core.WithStatus(core.OrderStatusTypeNew))
+		require.NoError(t, err)
+		assert.Equal(t, []int64{orders[2].ID}, ids(result))
+
+		require.Error(t, storage.UpdateOrder(ctx, &core.Order{ID: orders[2].ID + 1000}))
+	})
+
+	t.Run("query orders", func(t *testing.T) {
+		tt := []struct {
+			name     string
+			query    core.OrderQuery
+			expected []*core.Order
+		}{
+			{"all", core.OrderQuery{}, []*core.Order{orders[0], orders[2], orders[1]}},
+			{"status", core.OrderQuery{Status: []core.OrderStatusType{core.OrderStatusTypeFilled}}, []*core.Order{orders[0], orders[1]}},
+			{"pair", core.OrderQuery{Pair: "BTCUSDT"}, []*core.Order{orders[0], orders[2]}},
+			{"side", core.OrderQuery{Side: core.SideTypeSell}, []*core.Order{orders[2]}},
+			{"group", core.OrderQuery{GroupID: &groupID}, []*core.Order{orders[2]}},
+			{"created range", core.OrderQuery{CreatedFrom: start.Add(time.Hour), CreatedTo: start.Add(2 * time.Hour)}, []*core.Order{orders[2], orders[1]}},
+			{"sort and page", core.OrderQuery{OrderBy: core.OrderFieldID, Descending: true, Offset: 1, Limit: 1}, []*core.Order{orders[1]}},
+			{"filter function", core.OrderQuery{
+				Pair:    "BTCUSDT",
+				Filters: []core.OrderFilter{func(order core.Order) bool { return order.Side == core.SideTypeSell }},
+			}, []*core.Order{orders[2]}},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.name, func(t *testing.T) {
+				result, err := storage.QueryOrders(ctx, tc.query)
+				require.NoError(t, err)
+				assert.Equal(t, ids(tc.expected), ids(result))
+			})
+		}
+
+		_, err := storage.QueryOrders(ctx, core.OrderQuery{OrderBy: "price"})
+		require.ErrorIs(t, err, core.ErrInvalidOrderField)
+	})
+
+	t.Run("trades", func(t *testing.T) {
+		trades := []*core.Trade{
+			{Pair: "BTCUSDT", EntryOrderID: orders[0].ID, ExitOrderID: orders[2].ID, Profit: 10, ClosedAt: start.Add(2 * time.Hour)},
+			{Pair: "ETHUSDT", Profit: -5, ClosedAt: start.Add(time.Hour)},
+			{Pair: "BTCUSDT", Strategy: "trend", Profit: 3, ClosedAt: start.Add(3 * time.Hour)},
+		}
+		for _, trade := range trades {
+			require.NoError(t, storage.CreateTrade(ctx, trade))
+			require.NotZero(t, trade.ID)
+		}
+
+		tradeIDs := func(trades []*core.Trade) []int64 {
+			result := make([]int64, 0, len(trades))
+			for _, trade := range trades {
+				result = append(result, trade.ID)
+			}
+			return result
+		}
+
+		result, err := storage.Trades(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, tradeIDs([]*core.Trade{trades[1], trades[0], trades[2]}), tradeIDs(result))
+
+		result, err = storage.Trades(ctx, core.WithTradePair("BTCUSDT"), core.WithTradeStrategy(""))
+		require.NoError(t, err)
+		require.Len(t, result, 1)
+		assert.Equal(t, orders[2].ID, result[0].ExitOrderID)
+		assert.Equal(t, 10.0, result[0].Profit)
+
+		tt := []struct {
+			name     string
+			query    core.TradeQuery
+			expected []*core.Trade
+		}{
+			{"all", core.TradeQuery{}, []*core.Trade{trades[1], trades[0], trades[2]}},
+			{"pair", core.TradeQuery{Pair: "BTCUSDT"}, []*core.Trade{trades[0], trades[2]}},
+			{"strategy", core.TradeQuery{Strategy: "trend"}, []*core.Trade{trades[2]}},
+			{"closed range", core.TradeQuery{ClosedFrom: start.Add(time.Hour), ClosedTo: start.Add(2 * time.Hour)}, []*core.Trade{trades[1], trades[0]}},
+			{"closed from", core.TradeQuery{Pair: "BTCUSDT", ClosedFrom: start.Add(150 * time.Minute)}, []*core.Trade{trades[2]}},
+			{"filter function", core.TradeQuery{
+				Pair:    "BTCUSDT",
+				Filters: []core.TradeFilter{func(trade core.Trade) bool { return trade.Profit > 5 }},
+			}, []*core.Trade{trades[0]}},
+		}
+
+		for _, tc := range tt {
+			t.Run(tc.name, func(t *testing.T) {
+				result, err := storage.QueryTrades(ctx, tc.query)
+				require.NoError(t, err)
+				assert.Equal(t, tradeIDs(tc.expected), tradeIDs(result))
+			})
+		}
+	})
+
+	t.Run("candles", func(t *testing.T) {
+		pair := pairPrefix + "BTCUSDT"
+		candles := make([]core.Candle, 0, 4)
+		for i := 0; i < 4; i++ {
+			candles = append(candles, core.Candle{Pair: pair, Time: start.Add(time.Duration(i) * time.Hour), Close: float64(i)})
+		}
+		require.NoError(t, storage.SaveCandles(ctx, "1h", candles))
+
+		// saving again replaces the candles
+		candles[1].Close = 10
+		require.NoError(t, storage.SaveCandles(ctx, "1h", candles[1:2]))
+		require.NoError(t, storage.SaveCandles(ctx, "4h", candles[:1]))
+
+		result, err := storage.Candles(ctx, pair, "1h", start.Add(time.Hour), start.Add(2*time.Hour))
+		require.NoError(t, err)
+		require.Len(t, result, 2)
+		assert.True(t, result[0].Time.Equal(start.Add(time.Hour)))
+		assert.Equal(t, 10.0, result[0].Close)
+		assert.Equal(t, 2.0, result[1].Close)
+		assert.True(t, result[1].Complete)
+	})
+}
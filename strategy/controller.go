@@ -2,8 +2,11 @@ package strategy
 
 import (
 	"context"
+	"sync"
+	"time"
 
 	"github.com/raykavin/backnrun/core"
+	"github.com/xhit/go-str2duration/v2"
 )
 
 // Controller manages the execution of trading strategies
@@ -13,16 +16,66 @@ type Controller struct {
 	broker           core.Broker
 	log              core.Logger
 	started          bool
+
+	pair        string
+	timeframe   time.Duration
+	mu          sync.Mutex
+	informative []*informativeFrame
+}
+
+// informativeFrame holds the dataframe of an informative feed and the closed candles
+// waiting for the main timeframe to reach their close time
+type informativeFrame struct {
+	key              string
+	feed             core.InformativeFeed
+	duration         time.Duration
+	dataframeManager *DataframeManager
+	pending          []core.Candle
 }
 
 // NewStrategyController creates a new strategy controller
 func NewStrategyController(pair string, strategy core.Strategy, broker core.Broker, log core.Logger) *Controller {
-	return &Controller{
+	controller := &Controller{
 		dataframeManager: NewDataframeManager(pair),
 		strategy:         strategy,
 		broker:           broker,
 		log:              log,
+		pair:             pair,
+	}
+	controller.SetTimeframe(strategy.Timeframe())
+
+	if informativeStrategy, ok := strategy.(core.InformativeStrategy); ok {
+		for _, feed := range informativeStrategy.Informative() {
+			key := feed.Key()
+			if feed.Pair == "" {
+				feed.Pair = pair
+			}
+
+			controller.informative = append(controller.informative, &informativeFrame{
+				key:              key,
+				feed:             feed,
+				duration:         TimeframeDuration(feed.Timeframe),
+				dataframeManager: NewDataframeManager(feed.Pair),
+			})
+		}
+	}
+
+	return controller
+}
+
+// SetTimeframe sets the timeframe of the candles fed to the strategy, by default the strategy timeframe.
+// It is used to decide when an informative candle has closed relative to the strategy candles.
+func (c *Controller) SetTimeframe(timeframe string) {
+	c.timeframe = TimeframeDuration(timeframe)
+}
+
+// InformativeFeeds returns the informative feeds requested by the strategy, with their pair resolved
+func (c *Controller) InformativeFeeds() []core.InformativeFeed {
+	feeds := make([]core.InformativeFeed, 0, len(c.informative))
+	for _, frame := range c.informative {
+		feeds = append(feeds, frame.feed)
 	}
+	return feeds
 }
 
 // Start begins the strategy execution
@@ -30,6 +83,24 @@ func (c *Controller) Start() {
 	c.started = true
 }
 
+// OnInformativeCandle buffers a closed candle of an informative feed.
+// The candle is added to the informative dataframe once a strategy candle closing at
+// or after its close time is processed, so it is safe to call as soon as candles arrive.
+func (c *Controller) OnInformativeCandle(timeframe string, candle core.Candle) {
+	if !candle.Complete {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, frame := range c.informative {
+		if frame.feed.Pair == candle.Pair && frame.feed.Timeframe == timeframe {
+			frame.pending = append(frame.pending, candle)
+		}
+	}
+}
+
 // OnPartialCandle processes partial candle updates for high-frequency strategies
 func (c *Controller) OnPartialCandle(candle core.Candle) {
 	if !candle.Complete && c.dataframeManager.HasSufficientData(c.strategy.WarmupPeriod()) {
@@ -37,6 +108,7 @@ func (c *Controller) OnPartialCandle(candle core.Candle) {
 			c.dataframeManager.UpdateDataFrame(candle)
 
 			dataframe := c.dataframeManager.GetDataframe()
+			dataframe.Informative = c.informativeSamples()
 			highFreqStrategy.Indicators(dataframe)
 			highFreqStrategy.OnPartialCandle(dataframe, c.broker)
 		}
@@ -51,9 +123,11 @@ func (c *Controller) OnCandle(ctx context.Context, candle core.Candle) {
 	}
 
 	c.dataframeManager.UpdateDataFrame(candle)
+	c.updateInformative(candle.Time.Add(c.timeframe))
 
 	if c.dataframeManager.HasSufficientData(c.strategy.WarmupPeriod()) {
 		sample := c.dataframeManager.GetSample(c.strategy.WarmupPeriod())
+		sample.Informative = c.informativeSamples()
 		c.strategy.Indicators(&sample)
 
 		if c.started {
@@ -61,3 +135,55 @@ func (c *Controller) OnCandle(ctx context.Context, candle core.Candle) {
 		}
 	}
 }
+
+// updateInformative moves pending informative candles closed until the given time into their dataframes
+func (c *Controller) updateInformative(closeTime time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, frame := range c.informative {
+		applied := 0
+		for _, candle := range frame.pending {
+			if candle.Time.Add(frame.duration).After(closeTime) {
+				break
+			}
+
+			if !frame.dataframeManager.IsLateCandle(candle) {
+				frame.dataframeManager.UpdateDataFrame(candle)
+			}
+			applied++
+		}
+		frame.pending = frame.pending[applied:]
+	}
+}
+
+// informativeSamples returns the informative dataframes limited to their warmup period
+func (c *Controller) informativeSamples() map[string]*core.Dataframe {
+	if len(c.informative) == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	samples := make(map[string]*core.Dataframe, len(c.informative))
+	for _, frame := range c.informative {
+		warmup := frame.feed.WarmupPeriod
+		if warmup <= 0 {
+			warmup = c.strategy.WarmupPeriod()
+		}
+
+		sample := frame.dataframeManager.GetSample(warmup)
+		samples[frame.key] = &sample
+	}
+	return samples
+}
+
+// TimeframeDuration converts a timeframe to its duration, unknown timeframes are treated as zero
+func TimeframeDuration(timeframe string) time.Duration {
+	duration, err := str2duration.ParseDuration(timeframe)
+	if err != nil {
+		return 0
+	}
+	return duration
+}